@@ -1,12 +1,25 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"dx/cmd/cli/app"
+	"dx/internal/core/handler"
 
 	"github.com/spf13/cobra"
 )
 
+var buildJobs *int
+var buildForce *bool
+var buildWorktrees *map[string]string
+
 func init() {
+	buildJobs = buildCmd.Flags().IntP("jobs", "j", handler.DefaultBuildJobs, "Number of images to build at the same time")
+	buildForce = buildCmd.Flags().Bool("force", false, "Rebuild images even if their sources haven't changed")
+	buildWorktrees = buildCmd.Flags().StringToString("from-worktree", nil, "Build a service's or image's images from a local checkout (name=path)")
 	rootCmd.AddCommand(buildCmd)
 }
 
@@ -17,7 +30,20 @@ var buildCmd = &cobra.Command{
 specified, builds all services in the current profile.
 
 Images are built using the configured Dockerfile and made available to the
-local Kubernetes cluster.`,
+local Kubernetes cluster. Up to --jobs images are built at the same time;
+once a build fails no new builds are started and a summary shows which
+images were built, failed or skipped.
+
+Images are skipped when their commit, Dockerfile and build args haven't
+changed since they were last built and the built image still exists
+locally. Use --force to rebuild them anyway.
+
+Use --from-worktree to build the images of a service (or a single image)
+straight from a local checkout instead of its configured git source. The
+checkout is used as is, including uncommitted changes. A clean checkout is
+cached like a git source; a checkout with uncommitted changes, or with
+gitignored files in the build context, is always rebuilt. The .dockerignore
+file of the build context is respected.`,
 	Example: `  # Build all services in the default profile
   dx build
 
@@ -25,15 +51,58 @@ local Kubernetes cluster.`,
   dx build api frontend
 
   # Build all services regardless of profile
-  dx build -p all`,
+  dx build -p all
+
+  # Build one image at a time
+  dx build --jobs 1
+
+  # Rebuild images even if nothing changed
+  dx build --force
+
+  # Build the api images from a local checkout
+  dx build api --from-worktree api=~/src/api`,
 	Args:              ServiceArgsValidator,
 	ValidArgsFunction: ServiceArgsCompletion,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		handler, err := app.InjectBuildCommandHandler()
+		buildHandler, err := app.InjectBuildCommandHandler()
 		if err != nil {
 			return err
 		}
 
-		return handler.Handle(args, *profile)
+		worktrees, err := resolveWorktrees(*buildWorktrees)
+		if err != nil {
+			return err
+		}
+
+		return buildHandler.Handle(
+			args,
+			*profile,
+			handler.BuildOptions{Jobs: *buildJobs, Force: *buildForce, Worktrees: worktrees},
+		)
 	},
 }
+
+// resolveWorktrees turns the worktree paths into absolute paths, expanding ~ since shells don't
+// expand it after the = of a flag value, and checks that they are directories.
+func resolveWorktrees(worktrees map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(worktrees))
+	for name, path := range worktrees {
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get user home directory: %v", err)
+			}
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+		absolutePath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid worktree path for %s: %v", name, err)
+		}
+		info, err := os.Stat(absolutePath)
+		if err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("worktree %s for %s is not a directory", absolutePath, name)
+		}
+		resolved[name] = absolutePath
+	}
+	return resolved, nil
+}
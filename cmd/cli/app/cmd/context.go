@@ -13,7 +13,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var contextSetCreateNamespace bool
+
 func init() {
+	contextSetCmd.Flags().BoolVar(&contextSetCreateNamespace, "create-namespace", false, "create the context's Kubernetes namespace if it doesn't exist")
 	contextCmd.AddCommand(contextListCmd)
 	contextCmd.AddCommand(contextInfoCmd)
 	contextCmd.AddCommand(contextPrintCmd)
@@ -161,7 +164,15 @@ func getSortedProfiles(profiles []string) string {
 var contextSetCmd = &cobra.Command{
 	Use:   "set",
 	Short: "Sets the current context",
-	Long:  `Sets the current context to the specified context`,
+	Long: `Sets the current context to the specified context.
+
+With --create-namespace, the namespace configured for the context is created
+in its kube context if it doesn't exist yet.`,
+	Example: `  # Switch to the my-project context
+  dx context set my-project
+
+  # Switch context and create its namespace
+  dx context set my-project --create-namespace`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
 			return err
@@ -204,6 +215,6 @@ var contextSetCmd = &cobra.Command{
 			return err
 		}
 
-		return handler.HandleSet(args[0])
+		return handler.HandleSet(args[0], contextSetCreateNamespace)
 	},
 }
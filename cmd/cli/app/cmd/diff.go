@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"dx/cmd/cli/app"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [service...]",
+	Short: "Preview the changes installing services would make",
+	Long: `Renders the specified services the same way 'dx install' does and shows
+how the result differs from the live objects in the cluster, as a unified diff.
+If no services are specified, diffs all services in the current profile.
+
+Objects that already exist are applied as a server-side dry run, so defaults
+filled in by the API server don't show up as changes. Nothing in the cluster is
+modified. Values of Secrets are masked.`,
+	Example: `  # Preview changes for all services in the default profile
+  dx diff
+
+  # Preview changes for specific services
+  dx diff api worker`,
+	Args:              ServiceArgsValidator,
+	ValidArgsFunction: ServiceArgsCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diffHandler, err := app.InjectDiffCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		return diffHandler.Handle(args, *profile)
+	},
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"dx/cmd/cli/app"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the current context and its tools are set up",
+	Long: `Checks that the current context can be loaded and that the builder it
+uses to build and pull images is installed, and reports which builder is
+active. The builder is set with 'builder' in the context and defaults to
+docker.`,
+	Example: `  # Check the current context
+  dx doctor`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		handler, err := app.InjectDoctorCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		return handler.Handle()
+	},
+}
@@ -13,7 +13,11 @@ func init() {
 var genEnvKeyCmd = &cobra.Command{
 	Use:   "gen-env-key",
 	Short: "Generates an environment key for the currently active cluster configuration",
-	Long:  `Generates an environment key using the current cluster and namespace configuration in ~/.kube/config`,
+	Long: `Generates an environment key for the cluster and namespace of the current context.
+
+The cluster is taken from the context's kubeContext, or the current context in
+~/.kube/config. The namespace is taken from the context's namespace, or the
+namespace of the kube context.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		handler, err := app.InjectGenEnvKeyCommandHandler()
 		if err != nil {
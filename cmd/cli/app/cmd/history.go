@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"dx/cmd/cli/app"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history <service>",
+	Short: "Show the deployment history of a service",
+	Long: `Shows the deployments of a service recorded by 'dx install', oldest first.
+
+Each deployment lists the git ref and commit of the chart and of each image,
+a hash of the rendered helm args and values and when it was installed.
+The last 10 deployments of each service are kept, in ~/.dx/<context>/history.json.`,
+	Example: `  # Show the deployments of the api service
+  dx history api`,
+	Args:              cobra.MatchAll(cobra.ExactArgs(1), ServiceArgsValidator),
+	ValidArgsFunction: ServiceArgsCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		handler, err := app.InjectHistoryCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		return handler.Handle(args[0])
+	},
+}
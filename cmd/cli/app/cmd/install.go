@@ -1,15 +1,39 @@
 package cmd
 
 import (
+	"fmt"
+	"time"
+
 	"dx/cmd/cli/app"
+	"dx/internal/core/handler"
 
 	"github.com/spf13/cobra"
 )
 
 var skipDevProxy *bool
+var installWait *bool
+var installWaitForDependencies *bool
+var installTimeout *time.Duration
+var installJobs *int
+var installForce *bool
+var installDryRun *bool
+var installOutputDir *string
 
 func init() {
 	skipDevProxy = installCmd.Flags().BoolP("skip-dev-proxy", "s", false, "Skip dev proxy installation")
+	installWait = installCmd.Flags().Bool("wait", false, "Wait until the workloads of each service are ready")
+	installWaitForDependencies = installCmd.Flags().Bool(
+		"wait-for-dependencies",
+		false,
+		"Wait until services that others depend on are ready before installing their dependents",
+	)
+	installTimeout = installCmd.Flags().Duration("timeout", 5*time.Minute, "Time to wait for each service to become ready")
+	installJobs = installCmd.Flags().IntP("jobs", "j", handler.DefaultInstallJobs, "Number of services to install at the same time")
+	installForce = installCmd.Flags().Bool("force", false, "Upgrade releases even if their manifests haven't changed")
+	installDryRun = installCmd.Flags().Bool("dry-run", false, "Render the manifests instead of installing them, without contacting the cluster")
+	installOutputDir = installCmd.Flags().String("output-dir", "", "Directory to write the manifests of --dry-run to instead of stdout")
+	installCmd.MarkFlagsMutuallyExclusive("dry-run", "wait")
+	installCmd.MarkFlagsMutuallyExclusive("dry-run", "wait-for-dependencies")
 	rootCmd.AddCommand(installCmd)
 }
 
@@ -19,8 +43,25 @@ var installCmd = &cobra.Command{
 	Long: `Deploys the specified services to the local Kubernetes cluster using Helm.
 If no services are specified, deploys all services in the current profile.
 
+Up to --jobs services are installed at the same time. A service listed in another service's
+dependsOn is installed before it; with --wait-for-dependencies the dependent
+also waits until its dependencies are ready.
+
 This command also sets up the dev-proxy for routing traffic between local
-and Kubernetes services (unless --skip-dev-proxy is specified).`,
+and Kubernetes services (unless --skip-dev-proxy is specified).
+
+A service whose rendered manifests are identical to those of its deployed
+release is reported as unchanged and not upgraded, so no new release revision
+is created. Use --force to upgrade it anyway.
+
+With --dry-run, nothing is installed and the cluster isn't contacted. The
+manifests dx would deploy, including the dev-proxy selector patches, are
+printed to stdout, or written to --output-dir as <service>.yaml per service
+along with dev-proxy.yaml and the generated dev-proxy chart in dev-proxy/.
+
+With --wait, each service's Deployments, StatefulSets and Jobs are watched
+until they are ready. If a pod crash loops, can't pull its image or isn't
+ready within --timeout, its recent events and last log lines are printed.`,
 	Example: `  # Install all services in the default profile
   dx install
 
@@ -30,16 +71,44 @@ and Kubernetes services (unless --skip-dev-proxy is specified).`,
   # Install without dev-proxy setup
   dx install --skip-dev-proxy
 
+  # Upgrade all releases, even those whose manifests haven't changed
+  dx install --force
+
+  # Write the manifests that would be deployed to ./rendered
+  dx install --dry-run --output-dir ./rendered
+
+  # Validate the manifests that would be deployed
+  dx install --dry-run | kubeconform -summary
+
+  # Install and wait up to 10 minutes for each service to become ready
+  dx install --wait --timeout 10m
+
+  # Install one service at a time, e.g. for debugging
+  dx install --jobs 1
+
   # Install all services regardless of profile
   dx install -p all`,
 	Args:              ServiceArgsValidator,
 	ValidArgsFunction: ServiceArgsCompletion,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		handler, err := app.InjectInstallCommandHandler()
+		if *installOutputDir != "" && !*installDryRun {
+			return fmt.Errorf("--output-dir requires --dry-run")
+		}
+
+		installHandler, err := app.InjectInstallCommandHandler()
 		if err != nil {
 			return err
 		}
 
-		return handler.Handle(args, *profile, *skipDevProxy)
+		return installHandler.Handle(args, *profile, handler.InstallOptions{
+			SkipDevProxy:        *skipDevProxy,
+			Wait:                *installWait,
+			WaitForDependencies: *installWaitForDependencies,
+			Timeout:             *installTimeout,
+			Jobs:                *installJobs,
+			Force:               *installForce,
+			DryRun:              *installDryRun,
+			OutputDir:           *installOutputDir,
+		})
 	},
 }
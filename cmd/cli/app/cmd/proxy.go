@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"dx/cmd/cli/app"
+
+	"github.com/spf13/cobra"
+)
+
+var proxyRebuildForce bool
+
+func init() {
+	proxyRebuildCmd.Flags().BoolVar(&proxyRebuildForce, "force", false, "Rebuild the dev-proxy even if its configuration is unchanged")
+	proxyCmd.AddCommand(proxyDiffCmd)
+	proxyCmd.AddCommand(proxyRebuildCmd)
+	rootCmd.AddCommand(proxyCmd)
+}
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Manages the dev-proxy",
+	Long:  `Commands for inspecting and rebuilding the dev-proxy that routes cluster traffic to local services`,
+}
+
+var proxyDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Shows how the deployed dev-proxy differs from the current configuration",
+	Long: `Generates the dev-proxy configuration for the current context and dx version and
+compares it to the configuration saved by the last install.
+
+The checksum of the deployed dev-proxy is compared to the generated one, and the
+differences in the configuration files are printed as a unified diff.`,
+	Example: `  # Show pending dev-proxy changes
+  dx proxy diff`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		handler, err := app.InjectProxyCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		return handler.HandleDiff()
+	},
+}
+
+var proxyRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuilds and reinstalls the dev-proxy",
+	Long: `Regenerates the dev-proxy configuration, rebuilds its images and reinstalls it.
+
+The dev-proxy is only rebuilt when its checksum differs from the deployed one,
+unless --force is given.`,
+	Example: `  # Rebuild the dev-proxy if its configuration changed
+  dx proxy rebuild
+
+  # Rebuild the dev-proxy unconditionally
+  dx proxy rebuild --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		handler, err := app.InjectProxyCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		return handler.HandleRebuild(proxyRebuildForce)
+	},
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"dx/cmd/cli/app"
+
+	"github.com/spf13/cobra"
+)
+
+var pruneDryRun bool
+var pruneSkipConfirmation bool
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "list the orphaned releases without uninstalling them")
+	pruneCmd.Flags().BoolVarP(&pruneSkipConfirmation, "yes", "y", false, "skip confirmation for uninstalling the orphaned releases")
+	pruneCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Uninstall releases of services no longer in the configuration",
+	Long: `Finds the Helm releases DX installed for the current context whose services
+have been removed from or renamed in the configuration, and offers to uninstall
+them. Their wrapper charts, kustomize directories and helm values in
+~/.dx/<context>/ and their deployment history are removed as well. Services
+no longer in the configuration whose release is already gone are listed too,
+so their leftover local files can be removed.
+
+Releases of other contexts and the dev-proxy are never pruned. Use --dry-run
+to only list the orphaned releases, and --yes to skip the confirmation in
+non-interactive mode.`,
+	Example: `  # List the orphaned releases
+  dx prune --dry-run
+
+  # Uninstall the orphaned releases after confirmation
+  dx prune
+
+  # Uninstall the orphaned releases without confirmation
+  dx prune --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		handler, err := app.InjectPruneCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		return handler.Handle(pruneDryRun, pruneSkipConfirmation)
+	},
+}
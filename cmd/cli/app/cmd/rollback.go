@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"dx/cmd/cli/app"
+	"dx/internal/core/handler"
+
+	"github.com/spf13/cobra"
+)
+
+var rollbackWait *bool
+var rollbackTimeout *time.Duration
+
+func init() {
+	rollbackWait = rollbackCmd.Flags().Bool("wait", false, "Wait until the workloads of the service are ready")
+	rollbackTimeout = rollbackCmd.Flags().Duration("timeout", 5*time.Minute, "Time to wait for the service to become ready")
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <service> [revision]",
+	Short: "Reinstall a previous deployment of a service",
+	Long: `Reinstalls a previous deployment of a service from its deployment history,
+see 'dx history'. If no revision is specified, rolls back to the deployment
+before the latest one.
+
+The exact wrapper chart that was installed then is reinstalled, without
+rendering the chart again. The rollback is recorded as a new deployment.
+
+With --wait, the service's Deployments, StatefulSets and Jobs are watched
+until they are ready, like with 'dx install --wait'.`,
+	Example: `  # Roll back the api service to its previous deployment
+  dx rollback api
+
+  # Roll back the api service to revision 3
+  dx rollback api 3
+
+  # Roll back the api service and wait until it is ready
+  dx rollback api --wait`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.RangeArgs(1, 2)(cmd, args); err != nil {
+			return err
+		}
+		if len(args) == 2 {
+			if revision, err := strconv.Atoi(args[1]); err != nil || revision < 1 {
+				return fmt.Errorf("invalid revision '%s'", args[1])
+			}
+		}
+		return ServiceArgsValidator(cmd, args[:1])
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return ServiceArgsCompletion(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rollbackHandler, err := app.InjectRollbackCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		revision := 0
+		if len(args) == 2 {
+			revision, _ = strconv.Atoi(args[1])
+		}
+		return rollbackHandler.Handle(args[0], revision, handler.RollbackOptions{
+			Wait:    *rollbackWait,
+			Timeout: *rollbackTimeout,
+		})
+	},
+}
@@ -1,16 +1,33 @@
 package cmd
 
 import (
+	"fmt"
+	"time"
+
 	"dx/cmd/cli/app"
+	"dx/internal/core/handler"
 
 	"github.com/spf13/cobra"
 )
 
 var pullImages bool
+var updateWait bool
+var updateTimeout time.Duration
+var updateJobs int
+var updateForce bool
+var updateDryRun bool
+var updateOutputDir string
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
 	updateCmd.Flags().BoolVar(&pullImages, "pull", false, "pull images instead of building them")
+	updateCmd.Flags().IntVarP(&updateJobs, "jobs", "j", handler.DefaultBuildJobs, "number of images to build and services to install at the same time")
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "rebuild images and upgrade releases even if they haven't changed")
+	updateCmd.Flags().BoolVar(&updateWait, "wait", false, "wait until the workloads of each service are ready")
+	updateCmd.Flags().DurationVar(&updateTimeout, "timeout", 5*time.Minute, "time to wait for each service to become ready")
+	updateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "render the manifests without building, pulling or installing anything")
+	updateCmd.Flags().StringVar(&updateOutputDir, "output-dir", "", "directory to write the manifests of --dry-run to instead of stdout")
+	updateCmd.MarkFlagsMutuallyExclusive("dry-run", "wait")
 }
 
 var updateCmd = &cobra.Command{
@@ -21,7 +38,11 @@ If no services are specified, updates all services in the current profile.
 
 By default, images are built from source. Use --pull to pull pre-built images
 from the registry instead. Unlike 'dx pull', this skips the confirmation
-prompt since --pull is an explicit opt-in to overwrite locally-built images.`,
+prompt since --pull is an explicit opt-in to overwrite locally-built images.
+
+With --dry-run, no images are built or pulled and nothing is installed; the
+manifests are rendered like 'dx install --dry-run' does, using the images of
+the last build.`,
 	Example: `  # Build and reinstall all services in the default profile
   dx update
 
@@ -32,27 +53,23 @@ prompt since --pull is an explicit opt-in to overwrite locally-built images.`,
   dx update --pull
 
   # Pull and reinstall specific services
-  dx update --pull api frontend`,
+  dx update --pull api frontend
+
+  # Rebuild and reinstall, waiting for the services to become ready
+  dx update --wait
+
+  # Render the manifests of the api service to ./rendered
+  dx update api --dry-run --output-dir ./rendered`,
 	Args:              ServiceArgsValidator,
 	ValidArgsFunction: ServiceArgsCompletion,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if pullImages {
-			pullHandler, err := app.InjectPullCommandHandler()
-			if err != nil {
-				return err
-			}
-			// skipConfirmation=true since update is an intentional action
-			err = pullHandler.Handle(args, *profile, true)
-			if err != nil {
-				return err
-			}
-		} else {
-			buildHandler, err := app.InjectBuildCommandHandler()
-			if err != nil {
-				return err
-			}
-			err = buildHandler.Handle(args, *profile)
-			if err != nil {
+		if updateOutputDir != "" && !updateDryRun {
+			return fmt.Errorf("--output-dir requires --dry-run")
+		}
+
+		// A dry run renders the manifests with the images of the last build
+		if !updateDryRun {
+			if err := updateImages(args); err != nil {
 				return err
 			}
 		}
@@ -62,6 +79,31 @@ prompt since --pull is an explicit opt-in to overwrite locally-built images.`,
 			return err
 		}
 
-		return installHandler.Handle(args, *profile, false)
+		return installHandler.Handle(args, *profile, handler.InstallOptions{
+			Wait:      updateWait,
+			Timeout:   updateTimeout,
+			Jobs:      updateJobs,
+			Force:     updateForce,
+			DryRun:    updateDryRun,
+			OutputDir: updateOutputDir,
+		})
 	},
 }
+
+// updateImages pulls the images of the services with --pull and builds them otherwise.
+func updateImages(args []string) error {
+	if pullImages {
+		pullHandler, err := app.InjectPullCommandHandler()
+		if err != nil {
+			return err
+		}
+		// skipConfirmation=true since update is an intentional action
+		return pullHandler.Handle(args, *profile, true)
+	}
+
+	buildHandler, err := app.InjectBuildCommandHandler()
+	if err != nil {
+		return err
+	}
+	return buildHandler.Handle(args, *profile, handler.BuildOptions{Jobs: updateJobs, Force: updateForce})
+}
@@ -3,11 +3,11 @@ package cmd
 import (
 	"fmt"
 
+	"dx/internal/version"
+
 	"github.com/spf13/cobra"
 )
 
-var version = "dev"
-
 func init() {
 	rootCmd.AddCommand(versionCmd)
 }
@@ -16,6 +16,6 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Displays the application version",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("DX %s\n", version)
+		fmt.Printf("DX %s\n", version.Version)
 	},
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"dx/cmd/cli/app"
+	"dx/internal/core/handler"
+
+	"github.com/spf13/cobra"
+)
+
+var watchDebounce *time.Duration
+var watchJobs *int
+var watchWait *bool
+var watchTimeout *time.Duration
+
+func init() {
+	watchDebounce = watchCmd.Flags().Duration("debounce", 500*time.Millisecond, "Time to wait after the last change before updating")
+	watchJobs = watchCmd.Flags().IntP("jobs", "j", handler.DefaultBuildJobs, "Number of images to build and services to install at the same time")
+	watchWait = watchCmd.Flags().Bool("wait", false, "Wait until the workloads of each updated service are ready")
+	watchTimeout = watchCmd.Flags().Duration("timeout", 5*time.Minute, "Time to wait for each service to become ready")
+	rootCmd.AddCommand(watchCmd)
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [service...]",
+	Short: "Rebuild and reinstall services when their sources change",
+	Long: `Watches the sources of the specified services and rebuilds and reinstalls
+a service whenever its files change. If no services are specified, watches
+all services in the current profile.
+
+The build contexts of images with a localPath are watched, as are checkouts
+that 'dx build' has already made. Changes are collected until nothing has
+changed for --debounce, then only the affected services are updated. Sources
+are built as they are, including uncommitted changes, and images of clean
+checkouts that haven't changed since their last build are skipped.
+
+A failed update is reported and watching continues. Press Ctrl+C to stop.`,
+	Example: `  # Watch all services in the default profile
+  dx watch
+
+  # Watch specific services
+  dx watch api worker
+
+  # Wait for a quiet second before updating, and wait for readiness
+  dx watch api --debounce 1s --wait`,
+	Args:              ServiceArgsValidator,
+	ValidArgsFunction: ServiceArgsCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		watchHandler, err := app.InjectWatchCommandHandler()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		return watchHandler.Handle(ctx, args, *profile, handler.WatchOptions{
+			Debounce: *watchDebounce,
+			Build:    handler.BuildOptions{Jobs: *watchJobs},
+			Install: handler.InstallOptions{
+				SkipDevProxy: true,
+				Wait:         *watchWait,
+				Timeout:      *watchTimeout,
+				Jobs:         *watchJobs,
+			},
+		})
+	},
+}
@@ -7,6 +7,7 @@ import (
 	"dx/internal/adapters/command_runner"
 	"dx/internal/adapters/container_image_repository"
 	"dx/internal/adapters/container_orchestrator"
+	"dx/internal/adapters/file_watcher"
 	"dx/internal/adapters/filesystem"
 	"dx/internal/adapters/keyring"
 	"dx/internal/adapters/kustomize"
@@ -28,15 +29,23 @@ var Adapter = wire.NewSet(
 	scm.ProvideGit,
 	wire.Bind(new(ports.Scm), new(*scm.Git)),
 	container_image_repository.ProvideDockerRepository,
-	wire.Bind(new(ports.ContainerImageRepository), new(*container_image_repository.DockerRepository)),
+	container_image_repository.ProvidePodmanRepository,
+	container_image_repository.ProvideNerdctlRepository,
+	container_image_repository.ProvideBuildahRepository,
+	container_image_repository.ProvideConfiguredRepository,
+	wire.Bind(new(ports.ContainerImageRepository), new(*container_image_repository.ConfiguredRepository)),
 	container_orchestrator.ProvideHelmClient,
 	wire.Bind(new(ports.HelmClient), new(*container_orchestrator.HelmClient)),
 	kustomize.ProvideKustomizeClient,
 	wire.Bind(new(ports.KustomizeClient), new(*kustomize.Client)),
 	container_orchestrator.ProvideKubernetes,
 	wire.Bind(new(ports.ContainerOrchestrator), new(*container_orchestrator.Kubernetes)),
+	file_watcher.ProvidePollingFileWatcher,
+	wire.Bind(new(ports.FileWatcher), new(*file_watcher.PollingFileWatcher)),
 	filesystem.ProvideOsFileSystem,
 	wire.Bind(new(ports.FileSystem), new(*filesystem.OsFileSystem)),
+	filesystem.ProvideOsOutputWriter,
+	wire.Bind(new(ports.OutputWriter), new(*filesystem.OsOutputWriter)),
 	keyring.ProvideZalandoKeyring,
 	symmetric_encryptor.ProvideAesGcmEncryptor,
 	wire.Bind(new(ports.SymmetricEncryptor), new(*symmetric_encryptor.AesGcmEncryptor)),
@@ -54,6 +63,9 @@ var CoreSet = wire.NewSet(
 	core.ProvideEncryptedFileSecretRepository,
 	core.ProvideEnvironmentEnsurer,
 	core.ProvideChartWrapper,
+	core.ProvideBuildCache,
+	core.ProvideChartDownloader,
+	core.ProvideDeploymentHistory,
 )
 
 // CommandHandlerSet combines all sets needed for command handlers
@@ -88,6 +100,24 @@ func InjectBuildCommandHandler() (handler.BuildCommandHandler, error) {
 	return handler.BuildCommandHandler{}, nil
 }
 
+func InjectWatchCommandHandler() (handler.WatchCommandHandler, error) {
+	wire.Build(
+		CommandHandlerSet,
+		handler.ProvideBuildCommandHandler,
+		handler.ProvideInstallCommandHandler,
+		handler.ProvideWatchCommandHandler,
+	)
+	return handler.WatchCommandHandler{}, nil
+}
+
+func InjectDoctorCommandHandler() (handler.DoctorCommandHandler, error) {
+	wire.Build(
+		CommandHandlerSet,
+		handler.ProvideDoctorCommandHandler,
+	)
+	return handler.DoctorCommandHandler{}, nil
+}
+
 func InjectInstallCommandHandler() (handler.InstallCommandHandler, error) {
 	wire.Build(
 		CommandHandlerSet,
@@ -96,6 +126,30 @@ func InjectInstallCommandHandler() (handler.InstallCommandHandler, error) {
 	return handler.InstallCommandHandler{}, nil
 }
 
+func InjectDiffCommandHandler() (handler.DiffCommandHandler, error) {
+	wire.Build(
+		CommandHandlerSet,
+		handler.ProvideDiffCommandHandler,
+	)
+	return handler.DiffCommandHandler{}, nil
+}
+
+func InjectHistoryCommandHandler() (handler.HistoryCommandHandler, error) {
+	wire.Build(
+		CommandHandlerSet,
+		handler.ProvideHistoryCommandHandler,
+	)
+	return handler.HistoryCommandHandler{}, nil
+}
+
+func InjectRollbackCommandHandler() (handler.RollbackCommandHandler, error) {
+	wire.Build(
+		CommandHandlerSet,
+		handler.ProvideRollbackCommandHandler,
+	)
+	return handler.RollbackCommandHandler{}, nil
+}
+
 func InjectUninstallCommandHandler() (handler.UninstallCommandHandler, error) {
 	wire.Build(
 		CommandHandlerSet,
@@ -160,6 +214,14 @@ func InjectGenerateCommandHandler() (handler.GenerateCommandHandler, error) {
 	return handler.GenerateCommandHandler{}, nil
 }
 
+func InjectPruneCommandHandler() (handler.PruneCommandHandler, error) {
+	wire.Build(
+		CommandHandlerSet,
+		handler.ProvidePruneCommandHandler,
+	)
+	return handler.PruneCommandHandler{}, nil
+}
+
 func InjectPullCommandHandler() (handler.PullCommandHandler, error) {
 	wire.Build(
 		CommandHandlerSet,
@@ -167,3 +229,11 @@ func InjectPullCommandHandler() (handler.PullCommandHandler, error) {
 	)
 	return handler.PullCommandHandler{}, nil
 }
+
+func InjectProxyCommandHandler() (handler.ProxyCommandHandler, error) {
+	wire.Build(
+		CommandHandlerSet,
+		handler.ProvideProxyCommandHandler,
+	)
+	return handler.ProxyCommandHandler{}, nil
+}
@@ -10,6 +10,7 @@ import (
 	"dx/internal/adapters/command_runner"
 	"dx/internal/adapters/container_image_repository"
 	"dx/internal/adapters/container_orchestrator"
+	"dx/internal/adapters/file_watcher"
 	"dx/internal/adapters/filesystem"
 	"dx/internal/adapters/keyring"
 	"dx/internal/adapters/kustomize"
@@ -54,11 +55,16 @@ func InjectBuildCommandHandler() (handler.BuildCommandHandler, error) {
 	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
 	git := scm.ProvideGit(gitClient, osFileSystem)
 	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
-	buildCommandHandler := handler.ProvideBuildCommandHandler(fileSystemConfigRepository, git, dockerRepository)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	buildCommandHandler := handler.ProvideBuildCommandHandler(fileSystemConfigRepository, git, configuredRepository, buildCache, portsTemplater)
 	return buildCommandHandler, nil
 }
 
-func InjectInstallCommandHandler() (handler.InstallCommandHandler, error) {
+func InjectWatchCommandHandler() (handler.WatchCommandHandler, error) {
 	osFileSystem := filesystem.ProvideOsFileSystem()
 	portsKeyring := keyring.ProvideZalandoKeyring()
 	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
@@ -66,24 +72,79 @@ func InjectInstallCommandHandler() (handler.InstallCommandHandler, error) {
 	portsTemplater := templater.ProvideTextTemplater()
 	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
 	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
+	pollingFileWatcher := file_watcher.ProvidePollingFileWatcher()
 	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	buildCommandHandler := handler.ProvideBuildCommandHandler(fileSystemConfigRepository, git, configuredRepository, buildCache, portsTemplater)
 	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
 	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
 	chartWrapper := core.ProvideChartWrapper(osFileSystem)
-	kubernetes, err := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper)
-	if err != nil {
-		return handler.InstallCommandHandler{}, err
-	}
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
 	devProxyConfigGenerator := core.ProvideDevProxyConfigGenerator()
-	devProxyManager := core.ProvideDevProxyManager(fileSystemConfigRepository, osFileSystem, dockerRepository, kubernetes, devProxyConfigGenerator)
+	devProxyManager := core.ProvideDevProxyManager(fileSystemConfigRepository, osFileSystem, configuredRepository, kubernetes, devProxyConfigGenerator)
 	environmentEnsurer := core.ProvideEnvironmentEnsurer(fileSystemConfigRepository, kubernetes)
+	chartDownloader := core.ProvideChartDownloader(git, helmClient, osFileSystem)
+	osOutputWriter := filesystem.ProvideOsOutputWriter()
+	installCommandHandler := handler.ProvideInstallCommandHandler(fileSystemConfigRepository, configuredRepository, kubernetes, devProxyManager, environmentEnsurer, chartDownloader, osOutputWriter)
+	watchCommandHandler := handler.ProvideWatchCommandHandler(fileSystemConfigRepository, git, pollingFileWatcher, buildCommandHandler, installCommandHandler)
+	return watchCommandHandler, nil
+}
+
+func InjectDoctorCommandHandler() (handler.DoctorCommandHandler, error) {
+	osFileSystem := filesystem.ProvideOsFileSystem()
+	portsKeyring := keyring.ProvideZalandoKeyring()
+	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
+	secretsRepository := core.ProvideEncryptedFileSecretRepository(osFileSystem, portsKeyring, aesGcmEncryptor)
+	portsTemplater := templater.ProvideTextTemplater()
+	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
+	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	doctorCommandHandler := handler.ProvideDoctorCommandHandler(fileSystemConfigRepository, configuredRepository)
+	return doctorCommandHandler, nil
+}
+
+func InjectInstallCommandHandler() (handler.InstallCommandHandler, error) {
+	osFileSystem := filesystem.ProvideOsFileSystem()
+	portsKeyring := keyring.ProvideZalandoKeyring()
+	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
+	secretsRepository := core.ProvideEncryptedFileSecretRepository(osFileSystem, portsKeyring, aesGcmEncryptor)
+	portsTemplater := templater.ProvideTextTemplater()
+	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
+	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
+	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
+	chartWrapper := core.ProvideChartWrapper(osFileSystem)
 	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
 	git := scm.ProvideGit(gitClient, osFileSystem)
-	installCommandHandler := handler.ProvideInstallCommandHandler(fileSystemConfigRepository, dockerRepository, kubernetes, devProxyManager, environmentEnsurer, git)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
+	devProxyConfigGenerator := core.ProvideDevProxyConfigGenerator()
+	devProxyManager := core.ProvideDevProxyManager(fileSystemConfigRepository, osFileSystem, configuredRepository, kubernetes, devProxyConfigGenerator)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(fileSystemConfigRepository, kubernetes)
+	chartDownloader := core.ProvideChartDownloader(git, helmClient, osFileSystem)
+	osOutputWriter := filesystem.ProvideOsOutputWriter()
+	installCommandHandler := handler.ProvideInstallCommandHandler(fileSystemConfigRepository, configuredRepository, kubernetes, devProxyManager, environmentEnsurer, chartDownloader, osOutputWriter)
 	return installCommandHandler, nil
 }
 
-func InjectUninstallCommandHandler() (handler.UninstallCommandHandler, error) {
+func InjectDiffCommandHandler() (handler.DiffCommandHandler, error) {
 	osFileSystem := filesystem.ProvideOsFileSystem()
 	portsKeyring := keyring.ProvideZalandoKeyring()
 	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
@@ -94,14 +155,87 @@ func InjectUninstallCommandHandler() (handler.UninstallCommandHandler, error) {
 	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
 	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
 	chartWrapper := core.ProvideChartWrapper(osFileSystem)
-	kubernetes, err := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper)
-	if err != nil {
-		return handler.UninstallCommandHandler{}, err
-	}
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
+	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(fileSystemConfigRepository, kubernetes)
+	chartDownloader := core.ProvideChartDownloader(git, helmClient, osFileSystem)
+	diffCommandHandler := handler.ProvideDiffCommandHandler(fileSystemConfigRepository, kubernetes, environmentEnsurer, chartDownloader)
+	return diffCommandHandler, nil
+}
+
+func InjectHistoryCommandHandler() (handler.HistoryCommandHandler, error) {
+	osFileSystem := filesystem.ProvideOsFileSystem()
+	portsKeyring := keyring.ProvideZalandoKeyring()
+	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
+	secretsRepository := core.ProvideEncryptedFileSecretRepository(osFileSystem, portsKeyring, aesGcmEncryptor)
+	portsTemplater := templater.ProvideTextTemplater()
+	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
+	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	historyCommandHandler := handler.ProvideHistoryCommandHandler(deploymentHistory)
+	return historyCommandHandler, nil
+}
+
+func InjectRollbackCommandHandler() (handler.RollbackCommandHandler, error) {
+	osFileSystem := filesystem.ProvideOsFileSystem()
+	portsKeyring := keyring.ProvideZalandoKeyring()
+	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
+	secretsRepository := core.ProvideEncryptedFileSecretRepository(osFileSystem, portsKeyring, aesGcmEncryptor)
+	portsTemplater := templater.ProvideTextTemplater()
+	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
+	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
+	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
+	chartWrapper := core.ProvideChartWrapper(osFileSystem)
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
+	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
 	environmentEnsurer := core.ProvideEnvironmentEnsurer(fileSystemConfigRepository, kubernetes)
+	rollbackCommandHandler := handler.ProvideRollbackCommandHandler(fileSystemConfigRepository, kubernetes, environmentEnsurer)
+	return rollbackCommandHandler, nil
+}
+
+func InjectUninstallCommandHandler() (handler.UninstallCommandHandler, error) {
+	osFileSystem := filesystem.ProvideOsFileSystem()
+	portsKeyring := keyring.ProvideZalandoKeyring()
+	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
+	secretsRepository := core.ProvideEncryptedFileSecretRepository(osFileSystem, portsKeyring, aesGcmEncryptor)
+	portsTemplater := templater.ProvideTextTemplater()
+	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
+	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
+	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
+	chartWrapper := core.ProvideChartWrapper(osFileSystem)
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
 	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(fileSystemConfigRepository, kubernetes)
 	devProxyConfigGenerator := core.ProvideDevProxyConfigGenerator()
-	devProxyManager := core.ProvideDevProxyManager(fileSystemConfigRepository, osFileSystem, dockerRepository, kubernetes, devProxyConfigGenerator)
+	devProxyManager := core.ProvideDevProxyManager(fileSystemConfigRepository, osFileSystem, configuredRepository, kubernetes, devProxyConfigGenerator)
 	uninstallCommandHandler := handler.ProvideUninstallCommandHandler(fileSystemConfigRepository, kubernetes, environmentEnsurer, devProxyManager)
 	return uninstallCommandHandler, nil
 }
@@ -117,10 +251,16 @@ func InjectGenEnvKeyCommandHandler() (handler.GenEnvKeyCommandHandler, error) {
 	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
 	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
 	chartWrapper := core.ProvideChartWrapper(osFileSystem)
-	kubernetes, err := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper)
-	if err != nil {
-		return handler.GenEnvKeyCommandHandler{}, err
-	}
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
+	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
 	genEnvKeyCommandHandler := handler.ProvideGenEnvKeyCommandHandler(fileSystemConfigRepository, osFileSystem, kubernetes)
 	return genEnvKeyCommandHandler, nil
 }
@@ -136,7 +276,17 @@ func InjectContextCommandHandler() (handler.ContextCommandHandler, error) {
 	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
 	git := scm.ProvideGit(gitClient, osFileSystem)
 	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
-	contextCommandHandler := handler.ProvideContextCommandHandler(fileSystemConfigRepository, git, dockerRepository)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
+	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
+	chartWrapper := core.ProvideChartWrapper(osFileSystem)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
+	contextCommandHandler := handler.ProvideContextCommandHandler(fileSystemConfigRepository, git, configuredRepository, kubernetes)
 	return contextCommandHandler, nil
 }
 
@@ -199,6 +349,33 @@ func InjectGenerateCommandHandler() (handler.GenerateCommandHandler, error) {
 	return generateCommandHandler, nil
 }
 
+func InjectPruneCommandHandler() (handler.PruneCommandHandler, error) {
+	osFileSystem := filesystem.ProvideOsFileSystem()
+	portsKeyring := keyring.ProvideZalandoKeyring()
+	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
+	secretsRepository := core.ProvideEncryptedFileSecretRepository(osFileSystem, portsKeyring, aesGcmEncryptor)
+	portsTemplater := templater.ProvideTextTemplater()
+	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
+	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
+	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
+	chartWrapper := core.ProvideChartWrapper(osFileSystem)
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
+	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(fileSystemConfigRepository, kubernetes)
+	terminalInput := terminal.ProvideTerminalInput()
+	pruneCommandHandler := handler.ProvidePruneCommandHandler(fileSystemConfigRepository, kubernetes, deploymentHistory, environmentEnsurer, terminalInput)
+	return pruneCommandHandler, nil
+}
+
 func InjectPullCommandHandler() (handler.PullCommandHandler, error) {
 	osFileSystem := filesystem.ProvideOsFileSystem()
 	portsKeyring := keyring.ProvideZalandoKeyring()
@@ -208,17 +385,49 @@ func InjectPullCommandHandler() (handler.PullCommandHandler, error) {
 	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
 	osCommandRunner := command_runner.ProvideOsCommandRunner()
 	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
 	terminalInput := terminal.ProvideTerminalInput()
-	pullCommandHandler := handler.ProvidePullCommandHandler(fileSystemConfigRepository, dockerRepository, terminalInput)
+	pullCommandHandler := handler.ProvidePullCommandHandler(fileSystemConfigRepository, configuredRepository, terminalInput)
 	return pullCommandHandler, nil
 }
 
+func InjectProxyCommandHandler() (handler.ProxyCommandHandler, error) {
+	osFileSystem := filesystem.ProvideOsFileSystem()
+	portsKeyring := keyring.ProvideZalandoKeyring()
+	aesGcmEncryptor := symmetric_encryptor.ProvideAesGcmEncryptor()
+	secretsRepository := core.ProvideEncryptedFileSecretRepository(osFileSystem, portsKeyring, aesGcmEncryptor)
+	portsTemplater := templater.ProvideTextTemplater()
+	fileSystemConfigRepository := core.ProvideFileSystemConfigRepository(osFileSystem, secretsRepository, portsTemplater)
+	osCommandRunner := command_runner.ProvideOsCommandRunner()
+	dockerRepository := container_image_repository.ProvideDockerRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	podmanRepository := container_image_repository.ProvidePodmanRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	nerdctlRepository := container_image_repository.ProvideNerdctlRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	buildahRepository := container_image_repository.ProvideBuildahRepository(fileSystemConfigRepository, secretsRepository, portsTemplater, osCommandRunner)
+	configuredRepository := container_image_repository.ProvideConfiguredRepository(fileSystemConfigRepository, osCommandRunner, dockerRepository, podmanRepository, nerdctlRepository, buildahRepository)
+	helmClient := container_orchestrator.ProvideHelmClient(osCommandRunner)
+	client := kustomize.ProvideKustomizeClient(osCommandRunner, osFileSystem)
+	chartWrapper := core.ProvideChartWrapper(osFileSystem)
+	gitClient := scm.ProvideGitClient(osCommandRunner, osFileSystem)
+	git := scm.ProvideGit(gitClient, osFileSystem)
+	buildCache := core.ProvideBuildCache(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, git, configuredRepository)
+	deploymentHistory := core.ProvideDeploymentHistory(fileSystemConfigRepository, osFileSystem, git)
+	kubernetes := container_orchestrator.ProvideKubernetes(fileSystemConfigRepository, secretsRepository, portsTemplater, osFileSystem, helmClient, client, chartWrapper, buildCache, deploymentHistory, configuredRepository)
+	devProxyConfigGenerator := core.ProvideDevProxyConfigGenerator()
+	devProxyManager := core.ProvideDevProxyManager(fileSystemConfigRepository, osFileSystem, configuredRepository, kubernetes, devProxyConfigGenerator)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(fileSystemConfigRepository, kubernetes)
+	proxyCommandHandler := handler.ProvideProxyCommandHandler(devProxyManager, environmentEnsurer)
+	return proxyCommandHandler, nil
+}
+
 // wire.go:
 
-var Adapter = wire.NewSet(command_runner.ProvideOsCommandRunner, wire.Bind(new(ports.CommandRunner), new(*command_runner.OsCommandRunner)), scm.ProvideGitClient, scm.ProvideGit, wire.Bind(new(ports.Scm), new(*scm.Git)), container_image_repository.ProvideDockerRepository, wire.Bind(new(ports.ContainerImageRepository), new(*container_image_repository.DockerRepository)), container_orchestrator.ProvideHelmClient, wire.Bind(new(ports.HelmClient), new(*container_orchestrator.HelmClient)), kustomize.ProvideKustomizeClient, wire.Bind(new(ports.KustomizeClient), new(*kustomize.Client)), container_orchestrator.ProvideKubernetes, wire.Bind(new(ports.ContainerOrchestrator), new(*container_orchestrator.Kubernetes)), filesystem.ProvideOsFileSystem, wire.Bind(new(ports.FileSystem), new(*filesystem.OsFileSystem)), keyring.ProvideZalandoKeyring, symmetric_encryptor.ProvideAesGcmEncryptor, wire.Bind(new(ports.SymmetricEncryptor), new(*symmetric_encryptor.AesGcmEncryptor)), templater.ProvideTextTemplater, terminal.ProvideTerminalInput, wire.Bind(new(ports.TerminalInput), new(*terminal.TerminalInput)))
+var Adapter = wire.NewSet(command_runner.ProvideOsCommandRunner, wire.Bind(new(ports.CommandRunner), new(*command_runner.OsCommandRunner)), scm.ProvideGitClient, scm.ProvideGit, wire.Bind(new(ports.Scm), new(*scm.Git)), container_image_repository.ProvideDockerRepository, container_image_repository.ProvidePodmanRepository, container_image_repository.ProvideNerdctlRepository, container_image_repository.ProvideBuildahRepository, container_image_repository.ProvideConfiguredRepository, wire.Bind(new(ports.ContainerImageRepository), new(*container_image_repository.ConfiguredRepository)), container_orchestrator.ProvideHelmClient, wire.Bind(new(ports.HelmClient), new(*container_orchestrator.HelmClient)), kustomize.ProvideKustomizeClient, wire.Bind(new(ports.KustomizeClient), new(*kustomize.Client)), container_orchestrator.ProvideKubernetes, wire.Bind(new(ports.ContainerOrchestrator), new(*container_orchestrator.Kubernetes)), file_watcher.ProvidePollingFileWatcher, wire.Bind(new(ports.FileWatcher), new(*file_watcher.PollingFileWatcher)), filesystem.ProvideOsFileSystem, wire.Bind(new(ports.FileSystem), new(*filesystem.OsFileSystem)), filesystem.ProvideOsOutputWriter, wire.Bind(new(ports.OutputWriter), new(*filesystem.OsOutputWriter)), keyring.ProvideZalandoKeyring, symmetric_encryptor.ProvideAesGcmEncryptor, wire.Bind(new(ports.SymmetricEncryptor), new(*symmetric_encryptor.AesGcmEncryptor)), templater.ProvideTextTemplater, terminal.ProvideTerminalInput, wire.Bind(new(ports.TerminalInput), new(*terminal.TerminalInput)))
 
 // CoreSet provides domain/core dependencies
-var CoreSet = wire.NewSet(core.ProvideFileSystemConfigRepository, wire.Bind(new(core.ConfigRepository), new(*core.FileSystemConfigRepository)), core.ProvideDevProxyConfigGenerator, core.ProvideDevProxyManager, core.ProvideEncryptedFileSecretRepository, core.ProvideEnvironmentEnsurer, core.ProvideChartWrapper)
+var CoreSet = wire.NewSet(core.ProvideFileSystemConfigRepository, wire.Bind(new(core.ConfigRepository), new(*core.FileSystemConfigRepository)), core.ProvideDevProxyConfigGenerator, core.ProvideDevProxyManager, core.ProvideEncryptedFileSecretRepository, core.ProvideEnvironmentEnsurer, core.ProvideChartWrapper, core.ProvideBuildCache, core.ProvideChartDownloader, core.ProvideDeploymentHistory)
 
 // CommandHandlerSet combines all sets needed for command handlers
 var CommandHandlerSet = wire.NewSet(
@@ -45,6 +45,13 @@ func (r *OsCommandRunner) RunWithStdin(stdin io.Reader, name string, args ...str
 	return cmd.CombinedOutput()
 }
 
+func (r *OsCommandRunner) RunWithEnvAndStdin(stdin io.Reader, env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = stdin
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
 func (r *OsCommandRunner) RunInteractive(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Stdin = os.Stdin
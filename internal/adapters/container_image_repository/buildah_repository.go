@@ -0,0 +1,36 @@
+package container_image_repository
+
+import (
+	"dx/internal/core"
+	"dx/internal/ports"
+)
+
+var _ builderRepository = (*BuildahRepository)(nil)
+
+// BuildahRepository builds and pulls images with the buildah CLI.
+type BuildahRepository struct {
+	cliImageRepository
+}
+
+func ProvideBuildahRepository(
+	configRepository core.ConfigRepository,
+	secretsRepository core.SecretsRepository,
+	templater ports.Templater,
+	commandRunner ports.CommandRunner,
+) *BuildahRepository {
+	return &BuildahRepository{
+		cliImageRepository{
+			configRepository:  configRepository,
+			secretsRepository: secretsRepository,
+			templater:         templater,
+			commandRunner:     commandRunner,
+			cli: imageCli{
+				command:     "buildah",
+				imageIDArgs: []string{"inspect", "--type", "image", "--format", "{{.FromImageID}}"},
+				saveArgs: func(image, archivePath string) []string {
+					return []string{"push", image, "docker-archive:" + archivePath + ":" + image}
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package container_image_repository
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+)
+
+// imageCli describes how to run the CLI of a container image builder. All supported builders accept
+// docker's build and pull arguments, including a Dockerfile read from stdin with "-f -".
+type imageCli struct {
+	command string
+	// globalArgs are passed before every subcommand
+	globalArgs []string
+	// imageIDArgs print the ID of the image given as the next argument
+	imageIDArgs []string
+	// saveArgs write an image to a docker archive. Defaults to "save -o <archive> <image>".
+	saveArgs func(image, archivePath string) []string
+}
+
+// imageNotFoundMessages are the lowercase error messages of the builders for images that don't
+// exist locally
+var imageNotFoundMessages = []string{"no such image", "image not known", "no such object"}
+
+// cliImageRepository builds, pulls and inspects images by running a builder CLI.
+type cliImageRepository struct {
+	configRepository  core.ConfigRepository
+	secretsRepository core.SecretsRepository
+	templater         ports.Templater
+	commandRunner     ports.CommandRunner
+	cli               imageCli
+}
+
+func (c *cliImageRepository) BuildImage(image domain.DockerImage) error {
+	contextPath := filepath.Join(image.Path, image.BuildContextRelativePath)
+
+	// Determine dockerfile path: use stdin ("-") for override, or file path
+	var dockerfilePath string
+	var dockerfileContent string
+	if image.DockerfileOverride != "" {
+		dockerfilePath = "-"
+		dockerfileContent = image.DockerfileOverride
+	} else {
+		dockerfilePath = filepath.Join(image.Path, image.DockerfilePath)
+	}
+
+	args := []string{"build", "-t", image.Name, "-f", dockerfilePath}
+
+	templateValues, err := core.CreateTemplatingValues(c.configRepository, c.secretsRepository)
+	if err != nil {
+		return err
+	}
+
+	renderedOptions, err := core.RenderBuildOptions(image, c.templater, templateValues)
+	if err != nil {
+		return err
+	}
+	args = append(args, renderedOptions...)
+
+	renderedArgs, err := core.RenderBuildArgs(image, c.templater, templateValues)
+	if err != nil {
+		return err
+	}
+	args = append(args, renderedArgs...)
+
+	contextName, err := c.configRepository.LoadCurrentContextName()
+	if err != nil {
+		return err
+	}
+	secretFlags, secretEnv, err := core.BuildSecrets(image, c.secretsRepository, contextName)
+	if err != nil {
+		return err
+	}
+	args = append(args, secretFlags...)
+
+	// Add context path as the last argument
+	args = append(args, contextPath)
+
+	var stdin io.Reader
+	if dockerfileContent != "" {
+		// If using dockerfile override, pipe the content via stdin
+		stdin = strings.NewReader(dockerfileContent)
+	}
+
+	var output []byte
+	switch {
+	case len(secretEnv) > 0:
+		output, err = c.commandRunner.RunWithEnvAndStdin(stdin, secretEnv, c.cli.command, c.args(args...)...)
+	case stdin != nil:
+		output, err = c.commandRunner.RunWithStdin(stdin, c.cli.command, c.args(args...)...)
+	default:
+		output, err = c.commandRunner.Run(c.cli.command, c.args(args...)...)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to build image: %v\n%s", err, string(output))
+	}
+
+	return nil
+}
+
+// PullImage pulls an image from a registry
+func (c *cliImageRepository) PullImage(imageName string) error {
+	output, err := c.commandRunner.Run(c.cli.command, c.args("pull", imageName)...)
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %v\n%s", err, string(output))
+	}
+
+	return nil
+}
+
+// ImageID returns the ID of the local image, or an empty string if it doesn't exist locally
+func (c *cliImageRepository) ImageID(imageName string) (string, error) {
+	args := append(append([]string{}, c.cli.imageIDArgs...), imageName)
+	output, err := c.commandRunner.Run(c.cli.command, c.args(args...)...)
+	if err != nil {
+		lowerOutput := strings.ToLower(string(output))
+		for _, message := range imageNotFoundMessages {
+			if strings.Contains(lowerOutput, message) {
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("failed to inspect image: %v\n%s", err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SaveImage writes the local image to a docker archive
+func (c *cliImageRepository) SaveImage(imageName, archivePath string) error {
+	args := []string{"save", "-o", archivePath, imageName}
+	if c.cli.saveArgs != nil {
+		args = c.cli.saveArgs(imageName, archivePath)
+	}
+
+	output, err := c.commandRunner.Run(c.cli.command, c.args(args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to save image %s: %v\n%s", imageName, err, string(output))
+	}
+
+	return nil
+}
+
+// PushImage tags the local image as target and pushes it to the registry of target
+func (c *cliImageRepository) PushImage(imageName, target string) error {
+	output, err := c.commandRunner.Run(c.cli.command, c.args("tag", imageName, target)...)
+	if err != nil {
+		return fmt.Errorf("failed to tag image %s as %s: %v\n%s", imageName, target, err, string(output))
+	}
+
+	output, err = c.commandRunner.Run(c.cli.command, c.args("push", target)...)
+	if err != nil {
+		return fmt.Errorf("failed to push image %s: %v\n%s", target, err, string(output))
+	}
+
+	return nil
+}
+
+// BuilderVersion returns the version reported by the builder CLI
+func (c *cliImageRepository) BuilderVersion() (string, error) {
+	output, err := c.commandRunner.Run(c.cli.command, c.args("--version")...)
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %v\n%s", c.cli.command, err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// args prefixes the arguments of a subcommand with the global arguments of the CLI
+func (c *cliImageRepository) args(args ...string) []string {
+	return append(append([]string{}, c.cli.globalArgs...), args...)
+}
@@ -0,0 +1,175 @@
+package container_image_repository
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type builderTestCase struct {
+	name        string
+	provide     func(*testutil.MockCommandRunner) builderRepository
+	command     string
+	globalArgs  []string
+	imageIDArgs []string
+	notFound    string
+}
+
+func builderTestCases() []builderTestCase {
+	return []builderTestCase{
+		{
+			name: "docker",
+			provide: func(commandRunner *testutil.MockCommandRunner) builderRepository {
+				configRepo, secretsRepo, templater, _ := setupMocks()
+				return ProvideDockerRepository(configRepo, secretsRepo, templater, commandRunner)
+			},
+			command:     "docker",
+			imageIDArgs: []string{"image", "inspect", "--format", "{{.Id}}"},
+			notFound:    "Error: No such image: my-image",
+		},
+		{
+			name: "podman",
+			provide: func(commandRunner *testutil.MockCommandRunner) builderRepository {
+				configRepo, secretsRepo, templater, _ := setupMocks()
+				return ProvidePodmanRepository(configRepo, secretsRepo, templater, commandRunner)
+			},
+			command:     "podman",
+			imageIDArgs: []string{"image", "inspect", "--format", "{{.Id}}"},
+			notFound:    "Error: inspecting object: my-image: image not known",
+		},
+		{
+			name: "nerdctl",
+			provide: func(commandRunner *testutil.MockCommandRunner) builderRepository {
+				configRepo, secretsRepo, templater, _ := setupMocks()
+				return ProvideNerdctlRepository(configRepo, secretsRepo, templater, commandRunner)
+			},
+			command:     "nerdctl",
+			globalArgs:  []string{"--namespace", "k8s.io"},
+			imageIDArgs: []string{"image", "inspect", "--format", "{{.ID}}"},
+			notFound:    "FATA[0000] 1 errors:\nno such object: my-image",
+		},
+		{
+			name: "buildah",
+			provide: func(commandRunner *testutil.MockCommandRunner) builderRepository {
+				configRepo, secretsRepo, templater, _ := setupMocks()
+				return ProvideBuildahRepository(configRepo, secretsRepo, templater, commandRunner)
+			},
+			command:     "buildah",
+			imageIDArgs: []string{"inspect", "--type", "image", "--format", "{{.FromImageID}}"},
+			notFound:    "Error: image not known",
+		},
+	}
+}
+
+func TestCliImageRepository_BuildImage_AllBuilders(t *testing.T) {
+	for _, tc := range builderTestCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("Run", tc.command, append(tc.globalArgs,
+				"build", "-t", "my-image", "-f", "/path/to/repo/Dockerfile", "/path/to/repo/app",
+			)).Return([]byte("built"), nil)
+			sut := tc.provide(commandRunner)
+
+			err := sut.BuildImage(domain.DockerImage{
+				Name:                     "my-image",
+				DockerfilePath:           "Dockerfile",
+				BuildContextRelativePath: "app",
+				Path:                     "/path/to/repo",
+			})
+
+			require.NoError(t, err)
+			commandRunner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCliImageRepository_BuildImage_DockerfileOverrideFromStdin_AllBuilders(t *testing.T) {
+	for _, tc := range builderTestCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("RunWithStdin", mock.Anything, tc.command, append(tc.globalArgs,
+				"build", "-t", "my-image", "-f", "-", "/path/to/repo",
+			)).Return([]byte("built"), nil)
+			sut := tc.provide(commandRunner)
+
+			err := sut.BuildImage(domain.DockerImage{
+				Name:                     "my-image",
+				DockerfileOverride:       "FROM alpine",
+				BuildContextRelativePath: ".",
+				Path:                     "/path/to/repo",
+			})
+
+			require.NoError(t, err)
+			content, _ := io.ReadAll(commandRunner.Calls[0].Arguments.Get(0).(io.Reader))
+			assert.Equal(t, "FROM alpine", string(content))
+		})
+	}
+}
+
+func TestCliImageRepository_PullImage_AllBuilders(t *testing.T) {
+	for _, tc := range builderTestCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("Run", tc.command, append(tc.globalArgs, "pull", "postgres:15")).
+				Return([]byte("pulled"), nil)
+			sut := tc.provide(commandRunner)
+
+			err := sut.PullImage("postgres:15")
+
+			require.NoError(t, err)
+			commandRunner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCliImageRepository_ImageID_AllBuilders(t *testing.T) {
+	for _, tc := range builderTestCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append(append(append([]string{}, tc.globalArgs...), tc.imageIDArgs...), "my-image")
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("Run", tc.command, args).Return([]byte("sha256:abc\n"), nil).Once()
+			commandRunner.On("Run", tc.command, args).Return([]byte(tc.notFound), errors.New("exit status 1")).Once()
+			sut := tc.provide(commandRunner)
+
+			existing, err := sut.ImageID("my-image")
+			require.NoError(t, err)
+			missing, err := sut.ImageID("my-image")
+			require.NoError(t, err)
+
+			assert.Equal(t, "sha256:abc", existing)
+			assert.Empty(t, missing)
+		})
+	}
+}
+
+func TestCliImageRepository_BuilderVersion(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	commandRunner.On("Run", "nerdctl", []string{"--namespace", "k8s.io", "--version"}).
+		Return([]byte("nerdctl version 1.7.6\n"), nil)
+	configRepo, secretsRepo, templater, _ := setupMocks()
+	sut := ProvideNerdctlRepository(configRepo, secretsRepo, templater, commandRunner)
+
+	version, err := sut.BuilderVersion()
+
+	require.NoError(t, err)
+	assert.Equal(t, "nerdctl version 1.7.6", version)
+}
+
+func TestCliImageRepository_BuilderVersion_NotInstalled(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	commandRunner.On("Run", "podman", []string{"--version"}).
+		Return(nil, errors.New(`exec: "podman": executable file not found in $PATH`))
+	configRepo, secretsRepo, templater, _ := setupMocks()
+	sut := ProvidePodmanRepository(configRepo, secretsRepo, templater, commandRunner)
+
+	_, err := sut.BuilderVersion()
+
+	assert.ErrorContains(t, err, "failed to run podman")
+}
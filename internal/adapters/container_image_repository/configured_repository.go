@@ -0,0 +1,108 @@
+package container_image_repository
+
+import (
+	"fmt"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+)
+
+var _ ports.ContainerImageRepository = (*ConfiguredRepository)(nil)
+
+// builderRepository is a repository of a builder CLI, see cliImageRepository.
+type builderRepository interface {
+	BuildImage(image domain.DockerImage) error
+	PullImage(image string) error
+	ImageID(image string) (string, error)
+	BuilderVersion() (string, error)
+	SaveImage(image, archivePath string) error
+	PushImage(image, target string) error
+}
+
+// ConfiguredRepository delegates to the repository of the builder configured for the current
+// context, and loads the images it builds and pulls with the image loader of the context.
+type ConfiguredRepository struct {
+	configRepository core.ConfigRepository
+	commandRunner    ports.CommandRunner
+	repositories     map[string]builderRepository
+}
+
+func ProvideConfiguredRepository(
+	configRepository core.ConfigRepository,
+	commandRunner ports.CommandRunner,
+	dockerRepository *DockerRepository,
+	podmanRepository *PodmanRepository,
+	nerdctlRepository *NerdctlRepository,
+	buildahRepository *BuildahRepository,
+) *ConfiguredRepository {
+	return &ConfiguredRepository{
+		configRepository: configRepository,
+		commandRunner:    commandRunner,
+		repositories: map[string]builderRepository{
+			domain.BuilderDocker:  dockerRepository,
+			domain.BuilderPodman:  podmanRepository,
+			domain.BuilderNerdctl: nerdctlRepository,
+			domain.BuilderBuildah: buildahRepository,
+		},
+	}
+}
+
+func (r *ConfiguredRepository) BuildImage(image domain.DockerImage) error {
+	configContext, repository, err := r.repository()
+	if err != nil {
+		return err
+	}
+	if err := repository.BuildImage(image); err != nil {
+		return err
+	}
+	return r.loadImage(configContext, repository, image.Name)
+}
+
+func (r *ConfiguredRepository) PullImage(image string) error {
+	configContext, repository, err := r.repository()
+	if err != nil {
+		return err
+	}
+	if err := repository.PullImage(image); err != nil {
+		return err
+	}
+	return r.loadImage(configContext, repository, image)
+}
+
+func (r *ConfiguredRepository) LoadImage(image string) error {
+	configContext, repository, err := r.repository()
+	if err != nil {
+		return err
+	}
+	return r.loadImage(configContext, repository, image)
+}
+
+func (r *ConfiguredRepository) ImageID(image string) (string, error) {
+	_, repository, err := r.repository()
+	if err != nil {
+		return "", err
+	}
+	return repository.ImageID(image)
+}
+
+func (r *ConfiguredRepository) BuilderVersion() (string, error) {
+	_, repository, err := r.repository()
+	if err != nil {
+		return "", err
+	}
+	return repository.BuilderVersion()
+}
+
+func (r *ConfiguredRepository) repository() (*domain.ConfigurationContext, builderRepository, error) {
+	configContext, err := r.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repository, ok := r.repositories[configContext.ActiveBuilder()]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported builder '%s'", configContext.ActiveBuilder())
+	}
+	return configContext, repository, nil
+}
@@ -0,0 +1,217 @@
+package container_image_repository
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfiguredRepository_UsesBuilderOfCurrentContext(t *testing.T) {
+	tests := []struct {
+		builder string
+		command string
+	}{
+		{"", "docker"},
+		{domain.BuilderDocker, "docker"},
+		{domain.BuilderPodman, "podman"},
+		{domain.BuilderBuildah, "buildah"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			configRepo := new(testutil.MockConfigRepository)
+			configRepo.On("LoadCurrentConfigurationContext").
+				Return(&domain.ConfigurationContext{Name: "test-context", Builder: tt.builder}, nil)
+			_, secretsRepo, templater, _ := setupMocks()
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("Run", tt.command, []string{"pull", "postgres:15"}).Return([]byte("pulled"), nil)
+			sut := ProvideConfiguredRepository(
+				configRepo,
+				commandRunner,
+				ProvideDockerRepository(configRepo, secretsRepo, templater, commandRunner),
+				ProvidePodmanRepository(configRepo, secretsRepo, templater, commandRunner),
+				ProvideNerdctlRepository(configRepo, secretsRepo, templater, commandRunner),
+				ProvideBuildahRepository(configRepo, secretsRepo, templater, commandRunner),
+			)
+
+			err := sut.PullImage("postgres:15")
+
+			require.NoError(t, err)
+			commandRunner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestConfiguredRepository_RejectsUnsupportedBuilder(t *testing.T) {
+	configRepo := new(testutil.MockConfigRepository)
+	configRepo.On("LoadCurrentConfigurationContext").
+		Return(&domain.ConfigurationContext{Name: "test-context", Builder: "kaniko"}, nil)
+	sut := ProvideConfiguredRepository(configRepo, nil, nil, nil, nil, nil)
+
+	_, err := sut.ImageID("my-image")
+
+	assert.EqualError(t, err, "unsupported builder 'kaniko'")
+}
+
+func provideConfiguredRepository(
+	configContext *domain.ConfigurationContext,
+	commandRunner *testutil.MockCommandRunner,
+) *ConfiguredRepository {
+	configRepo := new(testutil.MockConfigRepository)
+	configRepo.On("LoadCurrentContextName").Return("test-context", nil)
+	configRepo.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	_, secretsRepo, templater, _ := setupMocks()
+	return ProvideConfiguredRepository(
+		configRepo,
+		commandRunner,
+		ProvideDockerRepository(configRepo, secretsRepo, templater, commandRunner),
+		ProvidePodmanRepository(configRepo, secretsRepo, templater, commandRunner),
+		ProvideNerdctlRepository(configRepo, secretsRepo, templater, commandRunner),
+		ProvideBuildahRepository(configRepo, secretsRepo, templater, commandRunner),
+	)
+}
+
+func TestConfiguredRepository_LoadsPulledImagesFromDockerDaemon(t *testing.T) {
+	tests := []struct {
+		loader      string
+		kubeContext string
+		command     string
+		args        []string
+	}{
+		{domain.ImageLoaderKind, "kind-dev", "kind", []string{"load", "docker-image", "postgres:15", "--name", "dev"}},
+		{domain.ImageLoaderKind, "", "kind", []string{"load", "docker-image", "postgres:15"}},
+		{domain.ImageLoaderMinikube, "dev", "minikube", []string{"image", "load", "postgres:15", "--profile", "dev"}},
+		{domain.ImageLoaderK3d, "k3d-dev", "k3d", []string{"image", "import", "postgres:15", "--cluster", "dev"}},
+		{domain.ImageLoaderK3d, "custom", "k3d", []string{"image", "import", "postgres:15"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.loader+"/"+tt.kubeContext, func(t *testing.T) {
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("Run", "docker", []string{"pull", "postgres:15"}).Return([]byte("pulled"), nil)
+			commandRunner.On("Run", tt.command, tt.args).Return([]byte("loaded"), nil)
+			sut := provideConfiguredRepository(
+				&domain.ConfigurationContext{Name: "test-context", ImageLoader: tt.loader, KubeContext: tt.kubeContext},
+				commandRunner,
+			)
+
+			err := sut.PullImage("postgres:15")
+
+			require.NoError(t, err)
+			commandRunner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestConfiguredRepository_LoadsImagesOfOtherBuildersFromArchive(t *testing.T) {
+	tests := []struct {
+		builder  string
+		saveArgs func(archive string) []string
+	}{
+		{domain.BuilderPodman, func(archive string) []string { return []string{"save", "-o", archive, "postgres:15"} }},
+		{domain.BuilderBuildah, func(archive string) []string {
+			return []string{"push", "postgres:15", "docker-archive:" + archive + ":postgres:15"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.builder, func(t *testing.T) {
+			var archive string
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("Run", tt.builder, []string{"pull", "postgres:15"}).Return([]byte("pulled"), nil)
+			commandRunner.On("Run", tt.builder, mock.Anything).
+				Run(func(args mock.Arguments) {
+					saveArgs := args.Get(1).([]string)
+					archive = saveArgs[2]
+					if tt.builder == domain.BuilderBuildah {
+						archive = strings.TrimSuffix(strings.TrimPrefix(saveArgs[2], "docker-archive:"), ":postgres:15")
+					}
+					assert.Equal(t, tt.saveArgs(archive), saveArgs)
+				}).
+				Return([]byte("saved"), nil).Once()
+			commandRunner.On("Run", "kind", mock.Anything).
+				Run(func(args mock.Arguments) {
+					assert.Equal(t, []string{"load", "image-archive", archive}, args.Get(1))
+				}).
+				Return([]byte("loaded"), nil)
+			sut := provideConfiguredRepository(
+				&domain.ConfigurationContext{Name: "test-context", Builder: tt.builder, ImageLoader: domain.ImageLoaderKind},
+				commandRunner,
+			)
+
+			err := sut.PullImage("postgres:15")
+
+			require.NoError(t, err)
+			commandRunner.AssertExpectations(t)
+			assert.NoDirExists(t, filepath.Dir(archive), "the archive is removed after loading")
+		})
+	}
+}
+
+func TestConfiguredRepository_PushesBuiltImagesToRegistry(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	commandRunner.On("Run", "docker", mock.MatchedBy(func(args []string) bool { return args[0] == "build" })).
+		Return([]byte("built"), nil)
+	commandRunner.On("Run", "docker", []string{"tag", "api", "localhost:5001/api"}).Return([]byte(""), nil)
+	commandRunner.On("Run", "docker", []string{"push", "localhost:5001/api"}).Return([]byte("pushed"), nil)
+	sut := provideConfiguredRepository(
+		&domain.ConfigurationContext{
+			Name:          "test-context",
+			ImageLoader:   domain.ImageLoaderRegistry,
+			ImageRegistry: "localhost:5001",
+		},
+		commandRunner,
+	)
+
+	err := sut.BuildImage(domain.DockerImage{Name: "api", Path: "/src/api", DockerfilePath: "Dockerfile"})
+
+	require.NoError(t, err)
+	commandRunner.AssertExpectations(t)
+}
+
+func TestConfiguredRepository_LoadImagePushesExistingImageToRegistry(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	commandRunner.On("Run", "docker", []string{"tag", "api:abc123", "localhost:5001/api:abc123"}).Return([]byte(""), nil)
+	commandRunner.On("Run", "docker", []string{"push", "localhost:5001/api:abc123"}).Return([]byte("pushed"), nil)
+	sut := provideConfiguredRepository(
+		&domain.ConfigurationContext{
+			Name:          "test-context",
+			ImageLoader:   domain.ImageLoaderRegistry,
+			ImageRegistry: "localhost:5001",
+		},
+		commandRunner,
+	)
+
+	err := sut.LoadImage("api:abc123")
+
+	require.NoError(t, err)
+	commandRunner.AssertExpectations(t)
+	commandRunner.AssertNotCalled(t, "Run", "docker", mock.MatchedBy(func(args []string) bool { return args[0] == "build" }))
+}
+
+func TestConfiguredRepository_ReportsFailedLoad(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	commandRunner.On("Run", "docker", []string{"pull", "postgres:15"}).Return([]byte("pulled"), nil)
+	commandRunner.On("Run", "kind", []string{"load", "docker-image", "postgres:15"}).
+		Return([]byte("ERROR: no nodes found for cluster \"kind\""), errors.New("exit status 1"))
+	sut := provideConfiguredRepository(
+		&domain.ConfigurationContext{Name: "test-context", ImageLoader: domain.ImageLoaderKind},
+		commandRunner,
+	)
+
+	err := sut.PullImage("postgres:15")
+
+	assert.EqualError(
+		t,
+		err,
+		"failed to load image postgres:15 into kind: exit status 1\nERROR: no nodes found for cluster \"kind\"",
+	)
+}
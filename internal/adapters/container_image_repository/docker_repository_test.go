@@ -80,6 +80,66 @@ func TestDockerRepository_BuildImage_WithBuildArgs(t *testing.T) {
 	commandRunner.AssertExpectations(t)
 }
 
+func TestDockerRepository_BuildImage_WithBuildOptions(t *testing.T) {
+	configRepo, secretsRepo, templater, commandRunner := setupMocks()
+
+	templater.On("Render", "dev", "build-options.target", mock.Anything).Return("dev", nil)
+	templater.On("Render", "default", "build-options.ssh.0", mock.Anything).Return("default", nil)
+	templater.On("Render", "--build-arg=VERSION=1.0", "build-args.0", mock.Anything).
+		Return("--build-arg=VERSION=1.0", nil)
+
+	commandRunner.On("Run", "docker", []string{
+		"build", "-t", "my-image", "-f", "/path/to/repo/Dockerfile",
+		"--target", "dev", "--ssh", "default", "--build-arg=VERSION=1.0", "/path/to/repo",
+	}).Return([]byte("Successfully built"), nil)
+
+	repo := ProvideDockerRepository(configRepo, secretsRepo, templater, commandRunner)
+
+	image := domain.DockerImage{
+		Name:                     "my-image",
+		DockerfilePath:           "Dockerfile",
+		BuildContextRelativePath: ".",
+		BuildArgs:                []string{"--build-arg=VERSION=1.0"},
+		Target:                   "dev",
+		SSH:                      []string{"default"},
+		Path:                     "/path/to/repo",
+	}
+
+	err := repo.BuildImage(image)
+
+	require.NoError(t, err)
+	templater.AssertExpectations(t)
+	commandRunner.AssertExpectations(t)
+}
+
+func TestDockerRepository_BuildImage_WithBuildSecrets(t *testing.T) {
+	configRepo := new(testutil.MockConfigRepository)
+	configRepo.On("LoadCurrentContextName").Return("test-context", nil)
+	configRepo.On("LoadCurrentConfigurationContext").Return(&domain.ConfigurationContext{Name: "test-context"}, nil)
+	secretsRepo := new(testutil.MockSecretsRepository)
+	secretsRepo.On("LoadSecrets", "test-context").Return([]*domain.Secret{{Key: "NPM_TOKEN", Value: "npm-secret"}}, nil)
+	commandRunner := new(testutil.MockCommandRunner)
+
+	commandRunner.On("RunWithEnvAndStdin", mock.Anything, []string{"DX_BUILD_SECRET_0=npm-secret"}, "docker", []string{
+		"build", "-t", "my-image", "-f", "-", "--secret", "id=npm,env=DX_BUILD_SECRET_0", "/path/to/repo",
+	}).Return([]byte("Successfully built"), nil)
+
+	repo := ProvideDockerRepository(configRepo, secretsRepo, new(testutil.MockTemplater), commandRunner)
+
+	image := domain.DockerImage{
+		Name:                     "my-image",
+		DockerfileOverride:       "FROM node:22\nRUN --mount=type=secret,id=npm npm ci",
+		BuildContextRelativePath: ".",
+		BuildSecrets:             map[string]string{"npm": "NPM_TOKEN"},
+		Path:                     "/path/to/repo",
+	}
+
+	err := repo.BuildImage(image)
+
+	require.NoError(t, err)
+	commandRunner.AssertExpectations(t)
+}
+
 func TestDockerRepository_BuildImage_WithDockerfileOverride(t *testing.T) {
 	configRepo, secretsRepo, templater, commandRunner := setupMocks()
 
@@ -205,3 +265,27 @@ func TestDockerRepository_PullImage_Fails(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to pull image")
 	assert.Contains(t, err.Error(), "pull access denied")
 }
+
+func TestDockerRepository_ImageID_Exists(t *testing.T) {
+	configRepo, secretsRepo, templater, commandRunner := setupMocks()
+	commandRunner.On("Run", "docker", []string{"image", "inspect", "--format", "{{.Id}}", "my-image"}).
+		Return([]byte("sha256:abc123\n"), nil)
+	repo := ProvideDockerRepository(configRepo, secretsRepo, templater, commandRunner)
+
+	imageID, err := repo.ImageID("my-image")
+
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:abc123", imageID)
+}
+
+func TestDockerRepository_ImageID_Missing(t *testing.T) {
+	configRepo, secretsRepo, templater, commandRunner := setupMocks()
+	commandRunner.On("Run", "docker", []string{"image", "inspect", "--format", "{{.Id}}", "my-image"}).
+		Return([]byte("Error: No such image: my-image"), errors.New("exit status 1"))
+	repo := ProvideDockerRepository(configRepo, secretsRepo, templater, commandRunner)
+
+	imageID, err := repo.ImageID("my-image")
+
+	require.NoError(t, err)
+	assert.Empty(t, imageID)
+}
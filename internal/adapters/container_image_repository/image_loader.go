@@ -0,0 +1,80 @@
+package container_image_repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dx/internal/core/domain"
+)
+
+// loadImage makes a local image available to the cluster of the context with its image loader.
+// kind, minikube and k3d load images straight from the docker daemon, and from an archive saved
+// with the builder otherwise. The registry loader pushes the image to the registry of the context,
+// and Kubernetes rewrites the references to it when services are installed.
+func (r *ConfiguredRepository) loadImage(
+	configContext *domain.ConfigurationContext,
+	repository builderRepository,
+	image string,
+) error {
+	loader := configContext.ActiveImageLoader()
+	switch loader {
+	case domain.ImageLoaderNone:
+		return nil
+	case domain.ImageLoaderRegistry:
+		return repository.PushImage(image, configContext.RegistryImage(image))
+	}
+
+	source := image
+	fromArchive := configContext.ActiveBuilder() != domain.BuilderDocker
+	if fromArchive {
+		directory, err := os.MkdirTemp("", "dx-image-")
+		if err != nil {
+			return fmt.Errorf("failed to create a directory for the archive of image %s: %v", image, err)
+		}
+		defer os.RemoveAll(directory)
+
+		source = filepath.Join(directory, "image.tar")
+		if err := repository.SaveImage(image, source); err != nil {
+			return err
+		}
+	}
+
+	command, args := loaderCommand(loader, configContext.KubeContext, source, fromArchive)
+	output, err := r.commandRunner.Run(command, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load image %s into %s: %v\n%s", image, loader, err, string(output))
+	}
+
+	return nil
+}
+
+// loaderCommand returns the command that loads source, an image or an archive, into the cluster of
+// the kube context. The cluster name is derived from the context names kind and k3d create, and the
+// tools fall back to their default cluster otherwise.
+func loaderCommand(loader, kubeContext, source string, fromArchive bool) (string, []string) {
+	switch loader {
+	case domain.ImageLoaderKind:
+		args := []string{"load", "docker-image", source}
+		if fromArchive {
+			args = []string{"load", "image-archive", source}
+		}
+		if cluster, ok := strings.CutPrefix(kubeContext, "kind-"); ok {
+			args = append(args, "--name", cluster)
+		}
+		return "kind", args
+	case domain.ImageLoaderMinikube:
+		args := []string{"image", "load", source}
+		if kubeContext != "" {
+			args = append(args, "--profile", kubeContext)
+		}
+		return "minikube", args
+	default:
+		args := []string{"image", "import", source}
+		if cluster, ok := strings.CutPrefix(kubeContext, "k3d-"); ok {
+			args = append(args, "--cluster", cluster)
+		}
+		return "k3d", args
+	}
+}
@@ -0,0 +1,38 @@
+package container_image_repository
+
+import (
+	"dx/internal/core"
+	"dx/internal/ports"
+)
+
+var _ builderRepository = (*NerdctlRepository)(nil)
+
+// nerdctlNamespace is the containerd namespace Kubernetes reads images from, so images built with
+// nerdctl (e.g. on Rancher Desktop) can be used by the cluster without pushing them
+const nerdctlNamespace = "k8s.io"
+
+// NerdctlRepository builds and pulls images with the nerdctl CLI of containerd.
+type NerdctlRepository struct {
+	cliImageRepository
+}
+
+func ProvideNerdctlRepository(
+	configRepository core.ConfigRepository,
+	secretsRepository core.SecretsRepository,
+	templater ports.Templater,
+	commandRunner ports.CommandRunner,
+) *NerdctlRepository {
+	return &NerdctlRepository{
+		cliImageRepository{
+			configRepository:  configRepository,
+			secretsRepository: secretsRepository,
+			templater:         templater,
+			commandRunner:     commandRunner,
+			cli: imageCli{
+				command:     "nerdctl",
+				globalArgs:  []string{"--namespace", nerdctlNamespace},
+				imageIDArgs: []string{"image", "inspect", "--format", "{{.ID}}"},
+			},
+		},
+	}
+}
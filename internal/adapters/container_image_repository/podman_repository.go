@@ -0,0 +1,33 @@
+package container_image_repository
+
+import (
+	"dx/internal/core"
+	"dx/internal/ports"
+)
+
+var _ builderRepository = (*PodmanRepository)(nil)
+
+// PodmanRepository builds and pulls images with the podman CLI, including rootless podman.
+type PodmanRepository struct {
+	cliImageRepository
+}
+
+func ProvidePodmanRepository(
+	configRepository core.ConfigRepository,
+	secretsRepository core.SecretsRepository,
+	templater ports.Templater,
+	commandRunner ports.CommandRunner,
+) *PodmanRepository {
+	return &PodmanRepository{
+		cliImageRepository{
+			configRepository:  configRepository,
+			secretsRepository: secretsRepository,
+			templater:         templater,
+			commandRunner:     commandRunner,
+			cli: imageCli{
+				command:     "podman",
+				imageIDArgs: []string{"image", "inspect", "--format", "{{.Id}}"},
+			},
+		},
+	}
+}
@@ -0,0 +1,303 @@
+package container_orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// diffFieldManager is the field manager of the server-side dry runs used to preview changes.
+const diffFieldManager = "dx-diff"
+
+// serverManagedFields are the fields set by the API server that are left out of diffs.
+var serverManagedFields = [][]string{
+	{"metadata", "creationTimestamp"},
+	{"metadata", "generation"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "selfLink"},
+	{"metadata", "uid"},
+	{"status"},
+}
+
+// DiffService renders a service like InstallService, including its wrapper chart, and compares each
+// rendered object with the live object in the cluster. Objects that already exist are applied
+// server-side as a dry run, so defaults set by the API server don't show up as changes. Fields of the
+// deployed release that the new render drops are removed from the result, and objects of the deployed
+// release that aren't rendered anymore are listed as deleted, like helm upgrade would.
+// Returns the objects that would be created, changed or deleted.
+func (k *Kubernetes) DiffService(service *domain.Service) ([]ports.ObjectDiff, error) {
+	rendered, err := k.renderService(service)
+	if err != nil {
+		return nil, err
+	}
+
+	// The wrapper chart is only templated, so it doesn't take up a generation
+	wrapperPath, err := k.generateWrapperChart(service, rendered, true)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := k.helmClient.Template(rendered.releaseName, wrapperPath, rendered.target.namespace, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to template wrapper chart: %w", err)
+	}
+
+	objects, err := decodeObjects(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	target := rendered.target
+	releaseManifests, err := k.helmClient.Manifest(rendered.releaseName, target.namespace, target.kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	releaseObjects, err := decodeObjects(releaseManifests)
+	if err != nil {
+		return nil, err
+	}
+	previousObjects := make(map[string]*unstructured.Unstructured, len(releaseObjects))
+	for _, object := range releaseObjects {
+		previousObjects[objectKey(object, target.namespace)] = object
+	}
+
+	dynamicClient, restMapper, err := k.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []ports.ObjectDiff
+	renderedKeys := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		key := objectKey(object, target.namespace)
+		renderedKeys[key] = true
+		diff, err := diffObject(context.Background(), dynamicClient, restMapper, object, previousObjects[key], target.namespace)
+		if err != nil {
+			return nil, err
+		}
+		if diff.Live != diff.Desired {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	for _, object := range releaseObjects {
+		if renderedKeys[objectKey(object, target.namespace)] {
+			continue
+		}
+		diff, err := diffDeletedObject(context.Background(), dynamicClient, restMapper, object, target.namespace)
+		if err != nil {
+			return nil, err
+		}
+		if diff.Live != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// objectKey identifies an object of a release by its group, kind, namespace and name. Objects
+// without a namespace are keyed by the namespace of the release, like helm places them.
+func objectKey(object *unstructured.Unstructured, namespace string) string {
+	objectNamespace := object.GetNamespace()
+	if objectNamespace == "" {
+		objectNamespace = namespace
+	}
+	gvk := object.GroupVersionKind()
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, objectNamespace, object.GetName())
+}
+
+// decodeObjects parses multi-document YAML manifests, skipping empty documents.
+func decodeObjects(manifests []byte) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifests), 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		var document json.RawMessage
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifests: %w", err)
+		}
+		if len(document) == 0 || string(document) == "null" {
+			continue
+		}
+
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(document); err != nil {
+			return nil, fmt.Errorf("failed to parse manifests: %w", err)
+		}
+		objects = append(objects, object)
+	}
+}
+
+// objectResource returns the client for the resource of an object. Namespaced objects without a
+// namespace are placed in the given namespace, like helm does.
+func objectResource(
+	dynamicClient dynamic.Interface,
+	restMapper meta.RESTMapper,
+	object *unstructured.Unstructured,
+	namespace string,
+) (dynamic.ResourceInterface, error) {
+	gvk := object.GroupVersionKind()
+	mapping, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find resource for %s %s: %w", gvk.Kind, object.GetName(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return dynamicClient.Resource(mapping.Resource), nil
+	}
+	if object.GetNamespace() == "" {
+		object.SetNamespace(namespace)
+	}
+	return dynamicClient.Resource(mapping.Resource).Namespace(object.GetNamespace()), nil
+}
+
+// diffObject compares a rendered object with its live counterpart. previous is the object as
+// installed by the deployed release, or nil if the release didn't install it. Fields it sets that
+// desired doesn't are removed from the result of the dry run, since helm deletes them on upgrade.
+func diffObject(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	restMapper meta.RESTMapper,
+	desired *unstructured.Unstructured,
+	previous *unstructured.Unstructured,
+	namespace string,
+) (ports.ObjectDiff, error) {
+	resource, err := objectResource(dynamicClient, restMapper, desired, namespace)
+	if err != nil {
+		return ports.ObjectDiff{}, err
+	}
+	gvk := desired.GroupVersionKind()
+
+	diff := ports.ObjectDiff{Kind: gvk.Kind, Namespace: desired.GetNamespace(), Name: desired.GetName()}
+	live, err := resource.Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		diff.Desired, err = diffYaml(nil, desired)
+		return diff, err
+	}
+	if err != nil {
+		return diff, fmt.Errorf("failed to get %s %s: %w", gvk.Kind, desired.GetName(), err)
+	}
+
+	applied, err := resource.Apply(ctx, desired.GetName(), desired, metav1.ApplyOptions{
+		FieldManager: diffFieldManager,
+		Force:        true,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		return diff, fmt.Errorf("failed to dry-run %s %s: %w", gvk.Kind, desired.GetName(), err)
+	}
+	if previous != nil {
+		removeDroppedFields(applied.Object, previous.Object, desired.Object)
+	}
+
+	diff.Live, err = diffYaml(nil, live)
+	if err != nil {
+		return diff, err
+	}
+	diff.Desired, err = diffYaml(live, applied)
+	return diff, err
+}
+
+// diffDeletedObject compares an object of the deployed release that is no longer rendered with its
+// live counterpart, which helm would delete. Live is empty if the object doesn't exist anymore.
+func diffDeletedObject(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	restMapper meta.RESTMapper,
+	object *unstructured.Unstructured,
+	namespace string,
+) (ports.ObjectDiff, error) {
+	resource, err := objectResource(dynamicClient, restMapper, object, namespace)
+	if err != nil {
+		return ports.ObjectDiff{}, err
+	}
+	kind := object.GetKind()
+
+	diff := ports.ObjectDiff{Kind: kind, Namespace: object.GetNamespace(), Name: object.GetName()}
+	live, err := resource.Get(ctx, object.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return diff, nil
+	}
+	if err != nil {
+		return diff, fmt.Errorf("failed to get %s %s: %w", kind, object.GetName(), err)
+	}
+	diff.Live, err = diffYaml(nil, live)
+	return diff, err
+}
+
+// removeDroppedFields removes the fields previous sets and desired doesn't from object, recursing
+// into maps set by all three. Lists are left as they are.
+func removeDroppedFields(object, previous, desired map[string]interface{}) {
+	for key, previousValue := range previous {
+		desiredValue, ok := desired[key]
+		if !ok {
+			delete(object, key)
+			continue
+		}
+		previousMap, previousIsMap := previousValue.(map[string]interface{})
+		desiredMap, desiredIsMap := desiredValue.(map[string]interface{})
+		objectMap, objectIsMap := object[key].(map[string]interface{})
+		if previousIsMap && desiredIsMap && objectIsMap {
+			removeDroppedFields(objectMap, previousMap, desiredMap)
+		}
+	}
+}
+
+// diffYaml returns an object as YAML without the fields managed by the API server. The values of
+// secrets are masked; a value that differs from the one in previous is marked as changed.
+func diffYaml(previous, object *unstructured.Unstructured) (string, error) {
+	object = object.DeepCopy()
+	for _, field := range serverManagedFields {
+		unstructured.RemoveNestedField(object.Object, field...)
+	}
+	if object.GetKind() == "Secret" && object.GetAPIVersion() == "v1" {
+		maskSecretValues(previous, object)
+	}
+
+	out, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to format %s %s: %w", object.GetKind(), object.GetName(), err)
+	}
+	return string(out), nil
+}
+
+// maskSecretValues replaces the data and stringData values of a secret with a placeholder that only
+// tells whether the value differs from the one in previous.
+func maskSecretValues(previous, secret *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		values, found, _ := unstructured.NestedMap(secret.Object, field)
+		if !found {
+			continue
+		}
+		var previousValues map[string]interface{}
+		if previous != nil {
+			previousValues, _, _ = unstructured.NestedMap(previous.Object, field)
+		}
+		for key, value := range values {
+			previousValue, ok := previousValues[key]
+			if ok && previousValue != value {
+				values[key] = "*** (changed)"
+			} else {
+				values[key] = "***"
+			}
+		}
+		_ = unstructured.SetNestedMap(secret.Object, values, field)
+	}
+}
@@ -0,0 +1,187 @@
+package container_orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func configMap(namespace, name string, data map[string]interface{}) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name},
+		"data":       data,
+	}}
+	if namespace != "" {
+		object.SetNamespace(namespace)
+	}
+	return object
+}
+
+func createDiffTestClient(objects ...runtime.Object) (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	return dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...), restMapper
+}
+
+func TestDecodeObjects_SkipsEmptyDocuments(t *testing.T) {
+	manifests := []byte(`---
+# Source: api/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api
+data:
+  replicas: "2"
+---
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+`)
+
+	objects, err := decodeObjects(manifests)
+
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "ConfigMap", objects[0].GetKind())
+	assert.Equal(t, "Service", objects[1].GetKind())
+	assert.Equal(t, "api", objects[1].GetName())
+}
+
+func TestDecodeObjects_RejectsObjectsWithoutKind(t *testing.T) {
+	_, err := decodeObjects([]byte("apiVersion: v1\nmetadata:\n  name: api\n"))
+
+	assert.ErrorContains(t, err, "failed to parse manifests")
+}
+
+func TestDiffObject_NewObjectHasNoLiveVersion(t *testing.T) {
+	client, restMapper := createDiffTestClient()
+
+	diff, err := diffObject(t.Context(), client, restMapper, configMap("", "api", map[string]interface{}{"a": "1"}), nil, "dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ConfigMap", diff.Kind)
+	assert.Equal(t, "dev", diff.Namespace)
+	assert.Equal(t, "api", diff.Name)
+	assert.Empty(t, diff.Live)
+	assert.Contains(t, diff.Desired, "namespace: dev")
+	assert.Contains(t, diff.Desired, "a: \"1\"")
+}
+
+func TestDiffObject_ComparesLiveObjectWithDryRun(t *testing.T) {
+	live := configMap("dev", "api", map[string]interface{}{"a": "1"})
+	live.SetResourceVersion("42")
+	live.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "Helm"})
+	client, restMapper := createDiffTestClient(live)
+	var applyOptions metav1.PatchOptions
+	client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		applyOptions = action.(k8stesting.PatchActionImpl).PatchOptions
+		applied := configMap("dev", "api", map[string]interface{}{"a": "2"})
+		applied.SetResourceVersion("42")
+		applied.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "Helm"})
+		return true, applied, nil
+	})
+
+	diff, err := diffObject(t.Context(), client, restMapper, configMap("dev", "api", map[string]interface{}{"a": "2"}), nil, "dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{metav1.DryRunAll}, applyOptions.DryRun)
+	assert.Equal(t, diffFieldManager, applyOptions.FieldManager)
+	assert.Contains(t, diff.Live, "a: \"1\"")
+	assert.Contains(t, diff.Desired, "a: \"2\"")
+	assert.Contains(t, diff.Desired, "app.kubernetes.io/managed-by: Helm")
+	assert.NotContains(t, diff.Live, "resourceVersion")
+}
+
+func TestDiffObject_RemovesFieldsDroppedFromRelease(t *testing.T) {
+	live := configMap("dev", "api", map[string]interface{}{"a": "1", "b": "2"})
+	live.SetLabels(map[string]string{"app": "api", "tier": "backend"})
+	client, restMapper := createDiffTestClient(live)
+	client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		// Fields owned by helm stay set, since the dry run uses another field manager
+		return true, live.DeepCopy(), nil
+	})
+	previous := configMap("", "api", map[string]interface{}{"a": "1", "b": "2"})
+	previous.SetLabels(map[string]string{"app": "api", "tier": "backend"})
+	desired := configMap("", "api", map[string]interface{}{"a": "1"})
+	desired.SetLabels(map[string]string{"app": "api"})
+
+	diff, err := diffObject(t.Context(), client, restMapper, desired, previous, "dev")
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Live, "b: \"2\"")
+	assert.Contains(t, diff.Live, "tier: backend")
+	assert.Contains(t, diff.Desired, "a: \"1\"")
+	assert.NotContains(t, diff.Desired, "b: \"2\"")
+	assert.NotContains(t, diff.Desired, "tier: backend")
+	assert.Contains(t, diff.Desired, "app: api")
+}
+
+func TestDiffDeletedObject(t *testing.T) {
+	client, restMapper := createDiffTestClient(configMap("dev", "api", map[string]interface{}{"a": "1"}))
+
+	diff, err := diffDeletedObject(t.Context(), client, restMapper, configMap("", "api", nil), "dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, "dev", diff.Namespace)
+	assert.Contains(t, diff.Live, "a: \"1\"")
+	assert.Empty(t, diff.Desired)
+}
+
+func TestDiffDeletedObject_AlreadyDeleted(t *testing.T) {
+	client, restMapper := createDiffTestClient()
+
+	diff, err := diffDeletedObject(t.Context(), client, restMapper, configMap("", "api", nil), "dev")
+
+	require.NoError(t, err)
+	assert.Empty(t, diff.Live)
+}
+
+func TestObjectKey_DefaultsToReleaseNamespace(t *testing.T) {
+	assert.Equal(t, objectKey(configMap("", "api", nil), "dev"), objectKey(configMap("dev", "api", nil), "dev"))
+	assert.NotEqual(t, objectKey(configMap("", "api", nil), "dev"), objectKey(configMap("other", "api", nil), "dev"))
+}
+
+func TestDiffObject_UnknownKind(t *testing.T) {
+	client, restMapper := createDiffTestClient()
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion("example.com/v1")
+	object.SetKind("Widget")
+	object.SetName("api")
+
+	_, err := diffObject(t.Context(), client, restMapper, object, nil, "dev")
+
+	assert.ErrorContains(t, err, "failed to find resource for Widget api")
+}
+
+func TestDiffYaml_MasksSecretValues(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "api"},
+		"data":       map[string]interface{}{"same": "c2FtZQ==", "changed": "b2xk"},
+	}}
+	desired := live.DeepCopy()
+	desired.Object["data"] = map[string]interface{}{"same": "c2FtZQ==", "changed": "bmV3", "added": "YWRkZWQ="}
+
+	liveYaml, err := diffYaml(nil, live)
+	require.NoError(t, err)
+	desiredYaml, err := diffYaml(live, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, "apiVersion: v1\ndata:\n  changed: '***'\n  same: '***'\nkind: Secret\nmetadata:\n  name: api\n", liveYaml)
+	assert.Equal(t, "apiVersion: v1\ndata:\n  added: '***'\n  changed: '*** (changed)'\n  same: '***'\nkind: Secret\nmetadata:\n  name: api\n", desiredYaml)
+	assert.Equal(t, "bmV3", desired.Object["data"].(map[string]interface{})["changed"], "the object must not be modified")
+}
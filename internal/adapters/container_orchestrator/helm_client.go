@@ -1,7 +1,10 @@
 package container_orchestrator
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"dx/internal/ports"
@@ -40,11 +43,17 @@ func (h *HelmClient) Template(name, chartPath, namespace string, args []string)
 }
 
 // UpgradeFromManifests installs/upgrades using pre-rendered manifests in a wrapper chart.
-func (h *HelmClient) UpgradeFromManifests(name, namespace, wrapperChartPath string) error {
+// The release is labelled with managed-by=dx and the given labels.
+func (h *HelmClient) UpgradeFromManifests(name, namespace, kubeContext, wrapperChartPath string, labels map[string]string) error {
+	releaseLabels := []string{"managed-by=dx"}
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		releaseLabels = append(releaseLabels, fmt.Sprintf("%s=%s", key, labels[key]))
+	}
+
 	cmdArgs := []string{
 		"upgrade",
 		"--install",
-		"--labels", "managed-by=dx",
+		"--labels", strings.Join(releaseLabels, ","),
 		name,
 		wrapperChartPath,
 	}
@@ -52,6 +61,9 @@ func (h *HelmClient) UpgradeFromManifests(name, namespace, wrapperChartPath stri
 	if namespace != "" {
 		cmdArgs = append(cmdArgs, "--namespace", namespace)
 	}
+	if kubeContext != "" {
+		cmdArgs = append(cmdArgs, "--kube-context", kubeContext)
+	}
 
 	output, err := h.commandRunner.Run("helm", cmdArgs...)
 	if err != nil {
@@ -62,11 +74,14 @@ func (h *HelmClient) UpgradeFromManifests(name, namespace, wrapperChartPath stri
 }
 
 // Uninstall removes a helm release.
-func (h *HelmClient) Uninstall(name, namespace string) error {
+func (h *HelmClient) Uninstall(name, namespace, kubeContext string) error {
 	cmdArgs := []string{"uninstall", name}
 	if namespace != "" {
 		cmdArgs = append(cmdArgs, "--namespace", namespace)
 	}
+	if kubeContext != "" {
+		cmdArgs = append(cmdArgs, "--kube-context", kubeContext)
+	}
 
 	output, err := h.commandRunner.Run("helm", cmdArgs...)
 	if err != nil {
@@ -76,11 +91,14 @@ func (h *HelmClient) Uninstall(name, namespace string) error {
 }
 
 // List returns release names matching the label selector.
-func (h *HelmClient) List(labelSelector, namespace string) ([]string, error) {
+func (h *HelmClient) List(labelSelector, namespace, kubeContext string) ([]string, error) {
 	cmdArgs := []string{"list", "-l", labelSelector, "--short"}
 	if namespace != "" {
 		cmdArgs = append(cmdArgs, "--namespace", namespace)
 	}
+	if kubeContext != "" {
+		cmdArgs = append(cmdArgs, "--kube-context", kubeContext)
+	}
 
 	output, err := h.commandRunner.Run("helm", cmdArgs...)
 	if err != nil {
@@ -93,3 +111,84 @@ func (h *HelmClient) List(labelSelector, namespace string) ([]string, error) {
 	}
 	return strings.Split(trimmed, "\n"), nil
 }
+
+// ReleaseMetadata returns the metadata of the deployed revision of a release using helm get metadata.
+// Returns nil if the release doesn't exist.
+func (h *HelmClient) ReleaseMetadata(name, namespace, kubeContext string) (*ports.ReleaseMetadata, error) {
+	cmdArgs := []string{"get", "metadata", name, "--output", "json"}
+	if namespace != "" {
+		cmdArgs = append(cmdArgs, "--namespace", namespace)
+	}
+	if kubeContext != "" {
+		cmdArgs = append(cmdArgs, "--kube-context", kubeContext)
+	}
+
+	output, err := h.commandRunner.Run("helm", cmdArgs...)
+	if err != nil {
+		if strings.Contains(string(output), "release: not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get helm release metadata: %w, output: %s", err, string(output))
+	}
+
+	var metadata struct {
+		Status      string            `json:"status"`
+		Revision    int               `json:"revision"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(output, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse helm release metadata: %w", err)
+	}
+	return &ports.ReleaseMetadata{
+		Status:      metadata.Status,
+		Revision:    metadata.Revision,
+		Annotations: metadata.Annotations,
+	}, nil
+}
+
+// Manifest returns the manifests of the deployed revision of a release using helm get manifest.
+// Returns nil if the release doesn't exist.
+func (h *HelmClient) Manifest(name, namespace, kubeContext string) ([]byte, error) {
+	cmdArgs := []string{"get", "manifest", name}
+	if namespace != "" {
+		cmdArgs = append(cmdArgs, "--namespace", namespace)
+	}
+	if kubeContext != "" {
+		cmdArgs = append(cmdArgs, "--kube-context", kubeContext)
+	}
+
+	output, err := h.commandRunner.Run("helm", cmdArgs...)
+	if err != nil {
+		if strings.Contains(string(output), "release: not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get helm release manifest: %w, output: %s", err, string(output))
+	}
+	return output, nil
+}
+
+// Pull downloads a chart using helm pull and unpacks it into destination.
+func (h *HelmClient) Pull(chart, repoURL, version, destination string) error {
+	cmdArgs := []string{"pull", chart, "--untar", "--untardir", destination}
+	if repoURL != "" {
+		cmdArgs = append(cmdArgs, "--repo", repoURL)
+	}
+	if version != "" {
+		cmdArgs = append(cmdArgs, "--version", version)
+	}
+
+	output, err := h.commandRunner.Run("helm", cmdArgs...)
+	if err != nil {
+		return fmt.Errorf("failed to pull helm chart %s: %w, output: %s", chart, err, string(output))
+	}
+	return nil
+}
+
+// DependencyBuild downloads the dependencies of a chart using helm dependency build.
+func (h *HelmClient) DependencyBuild(chartPath string) error {
+	output, err := h.commandRunner.Run("helm", "dependency", "build", chartPath)
+	if err != nil {
+		return fmt.Errorf("helm dependency build failed: %w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"dx/internal/testutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,7 +60,33 @@ func TestHelmClient_UpgradeFromManifests(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	err := client.UpgradeFromManifests("my-release", "my-namespace", "/path/to/wrapper")
+	err := client.UpgradeFromManifests("my-release", "my-namespace", "", "/path/to/wrapper", nil)
+
+	require.NoError(t, err)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_UpgradeFromManifests_AdditionalLabels(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"upgrade", "--install", "--labels", "managed-by=dx,a-label=a,dx-context=my-context", "my-release", "/path/to/wrapper", "--namespace", "my-namespace"}).
+		Return([]byte(""), nil)
+
+	client := ProvideHelmClient(runner)
+
+	err := client.UpgradeFromManifests("my-release", "my-namespace", "", "/path/to/wrapper", map[string]string{"dx-context": "my-context", "a-label": "a"})
+
+	require.NoError(t, err)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_UpgradeFromManifests_KubeContext(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"upgrade", "--install", "--labels", "managed-by=dx", "my-release", "/path/to/wrapper", "--namespace", "my-namespace", "--kube-context", "kind-dev"}).
+		Return([]byte(""), nil)
+
+	client := ProvideHelmClient(runner)
+
+	err := client.UpgradeFromManifests("my-release", "my-namespace", "kind-dev", "/path/to/wrapper", nil)
 
 	require.NoError(t, err)
 	runner.AssertExpectations(t)
@@ -72,7 +99,7 @@ func TestHelmClient_UpgradeFromManifests_NoNamespace(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	err := client.UpgradeFromManifests("my-release", "", "/path/to/wrapper")
+	err := client.UpgradeFromManifests("my-release", "", "", "/path/to/wrapper", nil)
 
 	require.NoError(t, err)
 	runner.AssertExpectations(t)
@@ -85,7 +112,20 @@ func TestHelmClient_Uninstall(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	err := client.Uninstall("my-release", "my-namespace")
+	err := client.Uninstall("my-release", "my-namespace", "")
+
+	require.NoError(t, err)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_Uninstall_KubeContext(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"uninstall", "my-release", "--namespace", "my-namespace", "--kube-context", "kind-dev"}).
+		Return([]byte(""), nil)
+
+	client := ProvideHelmClient(runner)
+
+	err := client.Uninstall("my-release", "my-namespace", "kind-dev")
 
 	require.NoError(t, err)
 	runner.AssertExpectations(t)
@@ -98,7 +138,7 @@ func TestHelmClient_Uninstall_NoNamespace(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	err := client.Uninstall("my-release", "")
+	err := client.Uninstall("my-release", "", "")
 
 	require.NoError(t, err)
 	runner.AssertExpectations(t)
@@ -111,13 +151,27 @@ func TestHelmClient_List(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	releases, err := client.List("managed-by=dx", "my-namespace")
+	releases, err := client.List("managed-by=dx", "my-namespace", "")
 
 	require.NoError(t, err)
 	assert.Equal(t, []string{"release1", "release2", "release3"}, releases)
 	runner.AssertExpectations(t)
 }
 
+func TestHelmClient_List_KubeContext(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"list", "-l", "managed-by=dx", "--short", "--namespace", "my-namespace", "--kube-context", "kind-dev"}).
+		Return([]byte("release1"), nil)
+
+	client := ProvideHelmClient(runner)
+
+	releases, err := client.List("managed-by=dx", "my-namespace", "kind-dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"release1"}, releases)
+	runner.AssertExpectations(t)
+}
+
 func TestHelmClient_List_NoNamespace(t *testing.T) {
 	runner := new(testutil.MockCommandRunner)
 	runner.On("Run", "helm", []string{"list", "-l", "managed-by=dx", "--short"}).
@@ -125,7 +179,7 @@ func TestHelmClient_List_NoNamespace(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	releases, err := client.List("managed-by=dx", "")
+	releases, err := client.List("managed-by=dx", "", "")
 
 	require.NoError(t, err)
 	assert.Equal(t, []string{"release1", "release2"}, releases)
@@ -139,7 +193,7 @@ func TestHelmClient_List_Empty(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	releases, err := client.List("managed-by=dx", "my-namespace")
+	releases, err := client.List("managed-by=dx", "my-namespace", "")
 
 	require.NoError(t, err)
 	assert.Empty(t, releases)
@@ -153,7 +207,7 @@ func TestHelmClient_UpgradeFromManifests_Error(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	err := client.UpgradeFromManifests("my-release", "my-namespace", "/path/to/wrapper")
+	err := client.UpgradeFromManifests("my-release", "my-namespace", "", "/path/to/wrapper", nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "helm upgrade failed")
@@ -168,7 +222,7 @@ func TestHelmClient_Uninstall_Error(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	err := client.Uninstall("my-release", "my-namespace")
+	err := client.Uninstall("my-release", "my-namespace", "")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to uninstall helm chart")
@@ -183,7 +237,7 @@ func TestHelmClient_List_Error(t *testing.T) {
 
 	client := ProvideHelmClient(runner)
 
-	_, err := client.List("managed-by=dx", "my-namespace")
+	_, err := client.List("managed-by=dx", "my-namespace", "")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to list helm charts")
@@ -195,3 +249,122 @@ func TestHelmClientInterface(t *testing.T) {
 	// Verify HelmClient implements the ports.HelmClient interface
 	var _ ports.HelmClient = (*HelmClient)(nil)
 }
+
+func TestHelmClient_ReleaseMetadata(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"get", "metadata", "my-release", "--output", "json", "--namespace", "my-namespace", "--kube-context", "kind-dev"}).
+		Return([]byte(`{"name":"my-release","revision":3,"status":"deployed","annotations":{"dx.manifests-checksum":"abc"}}`), nil)
+
+	client := ProvideHelmClient(runner)
+
+	metadata, err := client.ReleaseMetadata("my-release", "my-namespace", "kind-dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, &ports.ReleaseMetadata{
+		Status:      "deployed",
+		Revision:    3,
+		Annotations: map[string]string{"dx.manifests-checksum": "abc"},
+	}, metadata)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_ReleaseMetadata_NotFound(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"get", "metadata", "my-release", "--output", "json"}).
+		Return([]byte("Error: release: not found"), errors.New("exit status 1"))
+
+	client := ProvideHelmClient(runner)
+
+	metadata, err := client.ReleaseMetadata("my-release", "", "")
+
+	require.NoError(t, err)
+	assert.Nil(t, metadata)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_Manifest(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"get", "manifest", "my-release", "--namespace", "my-namespace", "--kube-context", "kind-dev"}).
+		Return([]byte("apiVersion: v1\nkind: ConfigMap\n"), nil)
+
+	client := ProvideHelmClient(runner)
+
+	manifest, err := client.Manifest("my-release", "my-namespace", "kind-dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\n", string(manifest))
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_Manifest_NotFound(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"get", "manifest", "my-release"}).
+		Return([]byte("Error: release: not found"), errors.New("exit status 1"))
+
+	client := ProvideHelmClient(runner)
+
+	manifest, err := client.Manifest("my-release", "", "")
+
+	require.NoError(t, err)
+	assert.Nil(t, manifest)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_Pull_FromRepository(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{
+		"pull", "postgresql", "--untar", "--untardir", "/charts/abc",
+		"--repo", "https://charts.bitnami.com/bitnami", "--version", "15.5.0",
+	}).Return([]byte(""), nil)
+	client := ProvideHelmClient(runner)
+
+	err := client.Pull("postgresql", "https://charts.bitnami.com/bitnami", "15.5.0", "/charts/abc")
+
+	require.NoError(t, err)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_Pull_OCILatest(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"pull", "oci://ghcr.io/acme/charts/kafka", "--untar", "--untardir", "/charts/abc"}).
+		Return([]byte(""), nil)
+	client := ProvideHelmClient(runner)
+
+	err := client.Pull("oci://ghcr.io/acme/charts/kafka", "", "", "/charts/abc")
+
+	require.NoError(t, err)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_Pull_Error(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", mock.Anything).Return([]byte("Error: chart \"kafka\" version \"9.9.9\" not found"), errors.New("exit status 1"))
+	client := ProvideHelmClient(runner)
+
+	err := client.Pull("kafka", "https://charts.example.com", "9.9.9", "/charts/abc")
+
+	assert.ErrorContains(t, err, "failed to pull helm chart kafka")
+	assert.ErrorContains(t, err, "version \"9.9.9\" not found")
+}
+
+func TestHelmClient_DependencyBuild(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"dependency", "build", "/charts/api"}).Return([]byte(""), nil)
+	client := ProvideHelmClient(runner)
+
+	err := client.DependencyBuild("/charts/api")
+
+	require.NoError(t, err)
+	runner.AssertExpectations(t)
+}
+
+func TestHelmClient_DependencyBuild_Error(t *testing.T) {
+	runner := new(testutil.MockCommandRunner)
+	runner.On("Run", "helm", []string{"dependency", "build", "/charts/api"}).
+		Return([]byte("Error: no repository definition for https://charts.example.com\n"), errors.New("exit status 1"))
+	client := ProvideHelmClient(runner)
+
+	err := client.DependencyBuild("/charts/api")
+
+	assert.EqualError(t, err, "helm dependency build failed: exit status 1, output: Error: no repository definition for https://charts.example.com")
+}
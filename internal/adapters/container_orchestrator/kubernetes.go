@@ -4,19 +4,28 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"time"
+	"sync"
 
 	"dx/internal/core"
 	"dx/internal/core/domain"
 	"dx/internal/ports"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -24,16 +33,26 @@ var _ ports.ContainerOrchestrator = (*Kubernetes)(nil)
 
 // Kubernetes represents a client for interacting with Kubernetes
 type Kubernetes struct {
-	configRepository  core.ConfigRepository
-	secretsRepository core.SecretsRepository
-	templater         ports.Templater
-	clientSet         *kubernetes.Clientset
-	helmClient        ports.HelmClient
-	kustomizeClient   ports.KustomizeClient
-	chartWrapper      *core.ChartWrapper
-	fileService       ports.FileSystem
+	configRepository         core.ConfigRepository
+	secretsRepository        core.SecretsRepository
+	templater                ports.Templater
+	loadingRules             *clientcmd.ClientConfigLoadingRules
+	clientSet                kubernetes.Interface // Created on first use, guarded by clientSetMutex
+	dynamicClientSet         dynamic.Interface    // Created on first use, guarded by clientSetMutex
+	restMapper               meta.RESTMapper      // Created on first use, guarded by clientSetMutex
+	clientSetMutex           sync.Mutex
+	helmClient               ports.HelmClient
+	kustomizeClient          ports.KustomizeClient
+	chartWrapper             *core.ChartWrapper
+	buildCache               *core.BuildCache
+	deploymentHistory        *core.DeploymentHistory
+	containerImageRepository ports.ContainerImageRepository
+	fileService              ports.FileSystem
 }
 
+// ProvideKubernetes creates a Kubernetes orchestrator. The kubeconfig is located using the standard
+// loading rules: the files listed in $KUBECONFIG, merged, or ~/.kube/config. Nothing is loaded and no
+// client is created until the cluster is first used, so commands that don't need it work offline.
 func ProvideKubernetes(
 	configRepository core.ConfigRepository,
 	secretsRepository core.SecretsRepository,
@@ -42,59 +61,179 @@ func ProvideKubernetes(
 	helmClient ports.HelmClient,
 	kustomizeClient ports.KustomizeClient,
 	chartWrapper *core.ChartWrapper,
-) (*Kubernetes, error) {
-	// Try to load the kubeConfig from the default location
-	home, err := os.UserHomeDir()
+	buildCache *core.BuildCache,
+	deploymentHistory *core.DeploymentHistory,
+	containerImageRepository ports.ContainerImageRepository,
+) *Kubernetes {
+	return &Kubernetes{
+		configRepository:         configRepository,
+		secretsRepository:        secretsRepository,
+		templater:                templater,
+		loadingRules:             clientcmd.NewDefaultClientConfigLoadingRules(),
+		helmClient:               helmClient,
+		kustomizeClient:          kustomizeClient,
+		chartWrapper:             chartWrapper,
+		buildCache:               buildCache,
+		deploymentHistory:        deploymentHistory,
+		containerImageRepository: containerImageRepository,
+		fileService:              fileService,
+	}
+}
+
+// deploymentTarget identifies where the current dx context is deployed.
+type deploymentTarget struct {
+	contextName string
+	// kubeContext is empty when the current kubeconfig context is used
+	kubeContext string
+	namespace   string
+}
+
+// kubeClientConfig returns the kubeconfig client configuration for the kube context of the given
+// dx context, falling back to the current kubeconfig context.
+func (k *Kubernetes) kubeClientConfig(configContext *domain.ConfigurationContext) clientcmd.ClientConfig {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		k.loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: configContext.KubeContext},
+	)
+}
+
+// client returns the clientset for the kube context of the current dx context.
+func (k *Kubernetes) client() (kubernetes.Interface, error) {
+	k.clientSetMutex.Lock()
+	defer k.clientSetMutex.Unlock()
+
+	if k.clientSet != nil {
+		return k.clientSet, nil
+	}
+
+	restConfig, err := k.restConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientSet, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+
+	k.clientSet = clientSet
+	return clientSet, nil
+}
+
+// dynamicClient returns a dynamic client and a REST mapper for the kube context of the current dx
+// context. The mapper discovers the resources of the cluster on first use.
+func (k *Kubernetes) dynamicClient() (dynamic.Interface, meta.RESTMapper, error) {
+	k.clientSetMutex.Lock()
+	defer k.clientSetMutex.Unlock()
+
+	if k.dynamicClientSet != nil {
+		return k.dynamicClientSet, k.restMapper, nil
+	}
+
+	restConfig, err := k.restConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dynamicClientSet, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %v", err)
+		return nil, nil, fmt.Errorf("failed to create kubernetes discovery client: %v", err)
 	}
-	kubeConfigPath := filepath.Join(home, ".kube", "config")
 
-	// Create the config from the kubeConfig file
-	kubeConfig, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	k.dynamicClientSet = dynamicClientSet
+	k.restMapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	return k.dynamicClientSet, k.restMapper, nil
+}
+
+// restConfig returns the client configuration for the kube context of the current dx context.
+func (k *Kubernetes) restConfig() (*rest.Config, error) {
+	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig, err := k.kubeClientConfig(configContext).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes config: %v", err)
 	}
+	return restConfig, nil
+}
 
-	// Create the clientSet
-	clientSet, err := kubernetes.NewForConfig(kubeConfig)
+// configuredNamespace returns the namespace set in the dx context, falling back to the namespace of
+// its kube context. Returns an empty string if neither sets a namespace.
+func (k *Kubernetes) configuredNamespace(configContext *domain.ConfigurationContext) (string, error) {
+	if configContext.Namespace != "" {
+		return configContext.Namespace, nil
+	}
+
+	rawConfig, err := k.kubeClientConfig(configContext).RawConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kubernetes client: %v", err)
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
-	return &Kubernetes{
-		configRepository:  configRepository,
-		secretsRepository: secretsRepository,
-		templater:         templater,
-		clientSet:         clientSet,
-		helmClient:        helmClient,
-		kustomizeClient:   kustomizeClient,
-		chartWrapper:      chartWrapper,
-		fileService:       fileService,
+	kubeContextName := configContext.KubeContext
+	if kubeContextName == "" {
+		kubeContextName = rawConfig.CurrentContext
+	}
+	if kubeContext, ok := rawConfig.Contexts[kubeContextName]; ok {
+		return kubeContext.Namespace, nil
+	}
+	return "", nil
+}
+
+// currentTarget returns the kube context and namespace of the current dx context.
+// The namespace defaults to "default" when neither the dx context nor the kube context set one.
+func (k *Kubernetes) currentTarget() (deploymentTarget, error) {
+	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return deploymentTarget{}, fmt.Errorf("failed to load configuration context: %w", err)
+	}
+
+	namespace, err := k.configuredNamespace(configContext)
+	if err != nil {
+		return deploymentTarget{}, err
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return deploymentTarget{
+		contextName: configContext.Name,
+		kubeContext: configContext.KubeContext,
+		namespace:   namespace,
 	}, nil
 }
 
 // CreateClusterEnvironmentKey creates a string that is used to uniquely identify the cluster and namespace
 func (k *Kubernetes) CreateClusterEnvironmentKey() (string, error) {
+	clientSet, err := k.client()
+	if err != nil {
+		return "", err
+	}
+
 	// Get cluster ID from kube-system namespace UID
-	kubeSystemNS, err := k.clientSet.CoreV1().Namespaces().Get(context.Background(), "kube-system", metav1.GetOptions{})
+	kubeSystemNS, err := clientSet.CoreV1().Namespaces().Get(context.Background(), "kube-system", metav1.GetOptions{})
 	if err != nil {
 		return "", fmt.Errorf("failed to get kube-system namespace: %v", err)
 	}
 	clusterUID := string(kubeSystemNS.UID)
 
-	// Get the current namespace from context
-	namespace := ""
-	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
-	if err == nil && config.CurrentContext != "" {
-		if currentContext, ok := config.Contexts[config.CurrentContext]; ok && currentContext.Namespace != "" {
-			namespace = currentContext.Namespace
-		}
+	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return "", fmt.Errorf("failed to load configuration context: %w", err)
+	}
+	namespace, err := k.configuredNamespace(configContext)
+	if err != nil {
+		return "", err
 	}
 
 	// Fail if no namespace is set
 	if namespace == "" {
-		return "", fmt.Errorf("no namespace set in current context")
+		return "", fmt.Errorf("no namespace set in context '%s' or its kube context", configContext.Name)
 	}
 
 	// Create a deterministic key based only on cluster UID and namespace
@@ -105,110 +244,365 @@ func (k *Kubernetes) CreateClusterEnvironmentKey() (string, error) {
 	return base64.URLEncoding.EncodeToString(hash.Sum(nil)), nil
 }
 
-// getCurrentNamespace returns the namespace from the current kubeconfig context.
-func (k *Kubernetes) getCurrentNamespace() string {
-	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+// EnsureNamespace creates the namespace of the current context if it doesn't exist.
+// Returns the namespace and whether it was created.
+func (k *Kubernetes) EnsureNamespace() (string, bool, error) {
+	target, err := k.currentTarget()
 	if err != nil {
-		return "default"
+		return "", false, err
 	}
-	if config.CurrentContext == "" {
-		return "default"
+
+	clientSet, err := k.client()
+	if err != nil {
+		return "", false, err
+	}
+
+	_, err = clientSet.CoreV1().Namespaces().Get(context.Background(), target.namespace, metav1.GetOptions{})
+	if err == nil {
+		return target.namespace, false, nil
 	}
-	if currentContext, ok := config.Contexts[config.CurrentContext]; ok && currentContext.Namespace != "" {
-		return currentContext.Namespace
+	if !apierrors.IsNotFound(err) {
+		return "", false, fmt.Errorf("failed to get namespace %s: %w", target.namespace, err)
 	}
-	return "default"
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: target.namespace}}
+	_, err = clientSet.CoreV1().Namespaces().Create(context.Background(), namespace, metav1.CreateOptions{})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create namespace %s: %w", target.namespace, err)
+	}
+	return target.namespace, true, nil
 }
 
-// InstallService installs a service using helm with kustomize patches.
-func (k *Kubernetes) InstallService(service *domain.Service) error {
+// renderedService is a service rendered through the install pipeline, ready to be wrapped in a chart.
+type renderedService struct {
+	target      deploymentTarget
+	releaseName string
+	chartPath   string
+	// manifests are the rendered manifests with the dx patches applied
+	manifests []byte
+	// checksum identifies the manifests, see core.ManifestsChecksumAnnotation
+	checksum string
+	// argsHash identifies the rendered helm args and values the chart was templated with
+	argsHash string
+}
+
+// renderService renders the chart of a service with its helm args and applies the kustomize labels,
+// patches and image overrides. Helm args, values and patches can refer to the images of the context as
+// .Images.<name>.ref, see core.BuildCache.ImageReferences; referring to a missing image is an error.
+func (k *Kubernetes) renderService(service *domain.Service) (*renderedService, error) {
 	templateValues, err := core.CreateTemplatingValues(k.configRepository, k.secretsRepository)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return nil, err
+	}
+	imageReferences, err := k.buildCache.ImageReferences(configContext)
+	if err != nil {
+		return nil, err
 	}
+	templateValues["Images"] = imageReferences
+	templater := core.NewImagesTemplater(k.templater, imageReferences)
 
 	var renderedArgs []string
 	for i, arg := range service.HelmArgs {
-		renderedArg, err := k.templater.Render(arg, fmt.Sprintf("helm-args.%d", i), templateValues)
+		renderedArg, err := templater.Render(arg, fmt.Sprintf("helm-args.%d", i), templateValues)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		renderedArgs = append(renderedArgs, renderedArg)
 	}
 
 	// Validate helm args don't contain dangerous flags
 	if err := validateHelmArgs(renderedArgs); err != nil {
-		return err
+		return nil, err
 	}
 
 	chartPath := filepath.Join(service.HelmPath, service.HelmChartRelativePath)
-	namespace := k.getCurrentNamespace()
+	target, err := k.currentTarget()
+	if err != nil {
+		return nil, err
+	}
+	contextName := target.contextName
 
-	// 1. Render helm chart to get raw manifests
-	rawManifests, err := k.helmClient.Template(service.Name, chartPath, namespace, renderedArgs)
+	values, err := core.RenderHelmValues(service, templater, templateValues)
 	if err != nil {
-		return fmt.Errorf("failed to template helm chart: %w", err)
+		return nil, err
 	}
+	valuesArgs, removeValues, err := k.writeHelmValues(contextName, service.Name, values)
+	if err != nil {
+		return nil, err
+	}
+	defer removeValues()
 
-	// 2. Build patches from LocalServices configuration
-	patches, err := k.buildPatches()
+	// 1. Render helm chart to get raw manifests. The chart is rendered with the service name
+	// rather than the release name, so resource names derived from .Release.Name stay stable.
+	rawManifests, err := k.helmClient.Template(service.Name, chartPath, target.namespace, append(valuesArgs, renderedArgs...))
 	if err != nil {
-		return fmt.Errorf("failed to build patches: %w", err)
+		return nil, fmt.Errorf("failed to template helm chart: %w", err)
 	}
 
-	// 3. Get context name for kustomize work dir
-	contextName, err := k.configRepository.LoadCurrentContextName()
+	// 2. Build patches from the manifests and LocalServices configuration, followed by the patches of the service
+	patches, err := k.buildPatches(contextName, service, rawManifests)
 	if err != nil {
-		return fmt.Errorf("failed to get context name: %w", err)
+		return nil, fmt.Errorf("failed to build patches: %w", err)
 	}
+	servicePatches, err := core.RenderServicePatches(service, templater, templateValues)
+	if err != nil {
+		return nil, err
+	}
+	patches = append(patches, servicePatches...)
 
-	// 4. Apply kustomize patches
-	homeDir, err := os.UserHomeDir()
+	images, err := k.registryImageOverrides(serviceImages(service))
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
+	}
+
+	// 3. Apply kustomize labels, patches and image overrides
+	homeDir, err := k.fileService.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 	kustomizeWorkDir := filepath.Join(homeDir, ".dx", contextName, "kustomize", service.Name)
-	patchedManifests, err := k.kustomizeClient.Apply(rawManifests, patches, kustomizeWorkDir)
+	patchedManifests, err := k.kustomizeClient.Apply(
+		rawManifests,
+		ports.Kustomization{Labels: contextLabels(contextName), Patches: patches, Images: images},
+		kustomizeWorkDir,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply kustomize patches: %w", err)
+	}
+
+	manifestsHash := sha256.Sum256(patchedManifests)
+	return &renderedService{
+		target:      target,
+		releaseName: core.ReleaseName(contextName, service.Name),
+		chartPath:   chartPath,
+		manifests:   patchedManifests,
+		checksum:    hex.EncodeToString(manifestsHash[:]),
+		argsHash:    hashArgs(renderedArgs, values),
+	}, nil
+}
+
+// hashArgs returns a hash of the rendered helm args and values of a service.
+func hashArgs(args []string, values [][]byte) string {
+	// Fields are NUL-separated so different splits of the same bytes hash differently
+	hash := sha256.New()
+	for _, arg := range args {
+		hash.Write([]byte(arg))
+		hash.Write([]byte{0})
+	}
+	for _, content := range values {
+		hash.Write(content)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeHelmValues writes the rendered helm values of a service to a private directory, as they may
+// contain secrets. Returns the -f flags passing them to helm and a function removing the directory.
+func (k *Kubernetes) writeHelmValues(contextName, serviceName string, values [][]byte) ([]string, func(), error) {
+	if len(values) == 0 {
+		return nil, func() {}, nil
+	}
+
+	homeDir, err := k.fileService.HomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to apply kustomize patches: %w", err)
+		return nil, nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	valuesDir := filepath.Join(homeDir, ".dx", contextName, "helm-values", serviceName)
+	if err := k.fileService.MkdirAll(valuesDir, ports.ReadWriteExecute); err != nil {
+		return nil, nil, fmt.Errorf("failed to create helm values directory: %w", err)
 	}
+	removeValues := func() { _ = k.fileService.RemoveAll(valuesDir) }
 
-	// 5. Generate wrapper chart
+	var args []string
+	for i, content := range values {
+		path := filepath.Join(valuesDir, fmt.Sprintf("values-%d.yaml", i))
+		if err := k.fileService.WriteFile(path, content, ports.ReadWrite); err != nil {
+			removeValues()
+			return nil, nil, fmt.Errorf("failed to write helm values: %w", err)
+		}
+		args = append(args, "-f", path)
+	}
+	return args, removeValues, nil
+}
+
+// generateWrapperChart writes the rendered manifests of a service to the next generation of its wrapper
+// chart, see core.ChartWrapper.Generate, or to its scratch wrapper chart if scratch is set. Returns the path to the chart.
+func (k *Kubernetes) generateWrapperChart(service *domain.Service, rendered *renderedService, scratch bool) (string, error) {
 	wrapperPath, err := k.chartWrapper.Generate(core.WrapperChartConfig{
 		ReleaseName:       service.Name,
-		ContextName:       contextName,
-		PatchedManifests:  patchedManifests,
+		ContextName:       rendered.target.contextName,
+		PatchedManifests:  rendered.manifests,
 		OriginalChartName: service.Name,
-		OriginalChartPath: chartPath,
+		OriginalChartPath: rendered.chartPath,
+		ManifestsChecksum: rendered.checksum,
+		Scratch:           scratch,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to generate wrapper chart: %w", err)
+		return "", fmt.Errorf("failed to generate wrapper chart: %w", err)
 	}
+	return wrapperPath, nil
+}
 
-	// 6. Install wrapper chart with helm
-	return k.helmClient.UpgradeFromManifests(service.Name, namespace, wrapperPath)
+// InstallService installs a service using helm with kustomize patches, see renderService.
+// The upgrade is skipped unless force is set or the patched manifests differ from those of the
+// deployed release. Returns whether the release was upgraded.
+func (k *Kubernetes) InstallService(service *domain.Service, force bool) (bool, error) {
+	rendered, err := k.renderService(service)
+	if err != nil {
+		return false, err
+	}
+	target := rendered.target
+
+	if err := k.checkLegacyRelease(target, service.Name); err != nil {
+		return false, err
+	}
+
+	// Skip the upgrade if the deployed release has the same manifests
+	if !force {
+		release, err := k.helmClient.ReleaseMetadata(rendered.releaseName, target.namespace, target.kubeContext)
+		if err != nil {
+			return false, err
+		}
+		if release != nil && release.Status == "deployed" && release.Annotations[core.ManifestsChecksumAnnotation] == rendered.checksum {
+			return false, nil
+		}
+	}
+
+	wrapperPath, err := k.generateWrapperChart(service, rendered, false)
+	if err != nil {
+		return false, err
+	}
+
+	err = k.helmClient.UpgradeFromManifests(
+		rendered.releaseName,
+		target.namespace,
+		target.kubeContext,
+		wrapperPath,
+		contextLabels(target.contextName),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	// Don't fail on errors from here on - the service was already installed
+	if err := k.chartWrapper.Commit(target.contextName, service.Name, wrapperPath); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: failed to keep the wrapper chart of service %s: %v\n", service.Name, err)
+	}
+	chart, images := k.deploymentHistory.Sources(service)
+	_, err = k.deploymentHistory.Record(service.Name, domain.Deployment{
+		Chart:             chart,
+		Images:            images,
+		ArgsHash:          rendered.argsHash,
+		ManifestsChecksum: rendered.checksum,
+		WrapperChart:      wrapperPath,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: failed to record the deployment of service %s, it can't be rolled back to: %v\n", service.Name, err)
+	}
+	return true, nil
 }
 
-// buildPatches creates kustomize patches based on LocalServices configuration.
-func (k *Kubernetes) buildPatches() ([]ports.Patch, error) {
+// RollbackService reinstalls the wrapper chart of a previous deployment of a service, see
+// domain.DeploymentHistory.Find, as a new generation and records the rollback as a new deployment.
+func (k *Kubernetes) RollbackService(service *domain.Service, revision int) (domain.Deployment, error) {
+	deployment, err := k.deploymentHistory.Find(service.Name, revision)
+	if err != nil {
+		return domain.Deployment{}, err
+	}
+	exists, err := k.fileService.FileExists(filepath.Join(deployment.WrapperChart, "Chart.yaml"))
+	if err != nil {
+		return domain.Deployment{}, err
+	}
+	if !exists {
+		return domain.Deployment{}, fmt.Errorf(
+			"the wrapper chart of revision %d of service %s was removed, only the last %d are kept",
+			deployment.Revision, service.Name, core.MaxWrapperChartGenerations,
+		)
+	}
+
+	target, err := k.currentTarget()
+	if err != nil {
+		return domain.Deployment{}, err
+	}
+	// Reinstall a copy, so the chart is kept as long as the deployment recording the rollback
+	wrapperPath, err := k.chartWrapper.Copy(target.contextName, service.Name, deployment.WrapperChart)
+	if err != nil {
+		return domain.Deployment{}, err
+	}
+	err = k.helmClient.UpgradeFromManifests(
+		core.ReleaseName(target.contextName, service.Name),
+		target.namespace,
+		target.kubeContext,
+		wrapperPath,
+		contextLabels(target.contextName),
+	)
+	if err != nil {
+		return domain.Deployment{}, err
+	}
+	if err := k.chartWrapper.Commit(target.contextName, service.Name, wrapperPath); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: failed to keep the wrapper chart of service %s: %v\n", service.Name, err)
+	}
+
+	rollback := deployment
+	rollback.RollbackOf = deployment.Revision
+	rollback.WrapperChart = wrapperPath
+	recorded, err := k.deploymentHistory.Record(service.Name, rollback)
+	if err != nil {
+		return domain.Deployment{}, fmt.Errorf("rolled back service %s but failed to record the rollback: %w", service.Name, err)
+	}
+	return recorded, nil
+}
+
+// contextLabels returns the labels that mark resources and releases as owned by a context.
+func contextLabels(contextName string) map[string]string {
+	return map[string]string{core.ContextLabel: contextName}
+}
+
+// serviceImages returns the images a service is built from and pulls.
+func serviceImages(service *domain.Service) []string {
+	var images []string
+	for _, image := range service.DockerImages {
+		images = append(images, image.Name)
+	}
+	return append(images, service.RemoteImages...)
+}
+
+// registryImageOverrides points references to the given images at the registry of the context when
+// images are loaded into a registry, and returns nothing otherwise.
+func (k *Kubernetes) registryImageOverrides(images []string) ([]ports.ImageOverride, error) {
 	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
 	if err != nil {
 		return nil, err
 	}
+	if configContext.ActiveImageLoader() != domain.ImageLoaderRegistry {
+		return nil, nil
+	}
 
-	var patches []ports.Patch
+	var overrides []ports.ImageOverride
+	for _, image := range images {
+		name := core.ImageNameWithoutTag(image)
+		overrides = append(overrides, ports.ImageOverride{Name: name, NewName: configContext.RegistryImage(name)})
+	}
+	return overrides, nil
+}
 
-	// Add recreatedAt annotation to all Deployments to force pod recreation
-	patches = append(patches, ports.Patch{
-		Target: ports.PatchTarget{Kind: "Deployment"},
-		Operations: []ports.PatchOperation{
-			{
-				Op:    "add",
-				Path:  "/spec/template/metadata/annotations/kubectl.kubernetes.io~1recreatedAt",
-				Value: time.Now().Format(time.RFC3339),
-			},
-		},
-	})
+// buildPatches creates kustomize patches for the manifests based on LocalServices configuration.
+// Service selectors are pointed at the dev-proxy of the given context.
+func (k *Kubernetes) buildPatches(contextName string, service *domain.Service, manifests []byte) ([]ports.Patch, error) {
+	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace pods only when what they run changed
+	patches, err := k.restartPatches(manifests, serviceImages(service))
+	if err != nil {
+		return nil, err
+	}
 
 	// Add service selector patches for each LocalService
 	proxyPort := 18080
@@ -216,7 +610,7 @@ func (k *Kubernetes) buildPatches() ([]ports.Patch, error) {
 		patches = append(patches, ports.Patch{
 			Target: ports.PatchTarget{Kind: "Service", Name: localService.Name},
 			Operations: []ports.PatchOperation{
-				{Op: "replace", Path: "/spec/selector/app", Value: "dev-proxy"},
+				{Op: "replace", Path: "/spec/selector/app", Value: core.DevProxyName(contextName)},
 				{Op: "replace", Path: "/spec/ports/0/targetPort", Value: proxyPort},
 			},
 		})
@@ -226,97 +620,290 @@ func (k *Kubernetes) buildPatches() ([]ports.Patch, error) {
 	return patches, nil
 }
 
-// InstallDevProxy installs the dev-proxy (no patches needed).
-func (k *Kubernetes) InstallDevProxy(service *domain.Service) error {
+// RenderService renders a service like InstallService without contacting the cluster.
+// Returns the patched manifests.
+func (k *Kubernetes) RenderService(service *domain.Service) ([]byte, error) {
+	rendered, err := k.renderService(service)
+	if err != nil {
+		return nil, err
+	}
+	return rendered.manifests, nil
+}
+
+// RenderDevProxy renders the dev-proxy like InstallDevProxy without contacting the cluster.
+func (k *Kubernetes) RenderDevProxy(service *domain.Service) ([]byte, error) {
+	_, manifests, err := k.renderDevProxy(service)
+	return manifests, err
+}
+
+// renderDevProxy renders the dev-proxy chart. Its manifests are only kustomized to point at its
+// images in the registry of the context, when images are loaded into a registry.
+func (k *Kubernetes) renderDevProxy(service *domain.Service) (deploymentTarget, []byte, error) {
 	templateValues, err := core.CreateTemplatingValues(k.configRepository, k.secretsRepository)
 	if err != nil {
-		return err
+		return deploymentTarget{}, nil, err
 	}
 
 	var renderedArgs []string
 	for i, arg := range service.HelmArgs {
 		renderedArg, err := k.templater.Render(arg, fmt.Sprintf("helm-args.%d", i), templateValues)
 		if err != nil {
-			return err
+			return deploymentTarget{}, nil, err
 		}
 		renderedArgs = append(renderedArgs, renderedArg)
 	}
 
 	// Validate helm args don't contain dangerous flags
 	if err := validateHelmArgs(renderedArgs); err != nil {
-		return err
+		return deploymentTarget{}, nil, err
 	}
 
 	chartPath := filepath.Join(service.HelmPath, service.HelmChartRelativePath)
-	namespace := k.getCurrentNamespace()
+	target, err := k.currentTarget()
+	if err != nil {
+		return deploymentTarget{}, nil, err
+	}
+	contextName := target.contextName
 
 	// For dev-proxy, no patches needed - just template and install
-	rawManifests, err := k.helmClient.Template(service.Name, chartPath, namespace, renderedArgs)
+	rawManifests, err := k.helmClient.Template(service.Name, chartPath, target.namespace, renderedArgs)
 	if err != nil {
-		return fmt.Errorf("failed to template helm chart: %w", err)
+		return deploymentTarget{}, nil, fmt.Errorf("failed to template helm chart: %w", err)
 	}
 
-	contextName, err := k.configRepository.LoadCurrentContextName()
+	images, err := k.registryImageOverrides(
+		[]string{core.DevProxyHAProxyImage(contextName), core.DevProxyMitmproxyImage(contextName)},
+	)
 	if err != nil {
-		return fmt.Errorf("failed to get context name: %w", err)
+		return deploymentTarget{}, nil, err
+	}
+	if len(images) == 0 {
+		return target, rawManifests, nil
 	}
 
-	// Generate wrapper chart without patches
+	homeDir, err := k.fileService.HomeDir()
+	if err != nil {
+		return deploymentTarget{}, nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	kustomizeWorkDir := filepath.Join(homeDir, ".dx", contextName, "kustomize", service.Name)
+	manifests, err := k.kustomizeClient.Apply(rawManifests, ports.Kustomization{Images: images}, kustomizeWorkDir)
+	if err != nil {
+		return deploymentTarget{}, nil, fmt.Errorf("failed to apply kustomize image overrides: %w", err)
+	}
+	return target, manifests, nil
+}
+
+// InstallDevProxy installs the dev-proxy, see renderDevProxy.
+func (k *Kubernetes) InstallDevProxy(service *domain.Service) error {
+	target, manifests, err := k.renderDevProxy(service)
+	if err != nil {
+		return err
+	}
+
+	// Generate wrapper chart without patches. The dev-proxy isn't rolled back, so no generations are kept.
 	wrapperPath, err := k.chartWrapper.Generate(core.WrapperChartConfig{
 		ReleaseName:       service.Name,
-		ContextName:       contextName,
-		PatchedManifests:  rawManifests, // No patches applied
+		ContextName:       target.contextName,
+		PatchedManifests:  manifests,
 		OriginalChartName: service.Name,
-		OriginalChartPath: chartPath,
+		OriginalChartPath: filepath.Join(service.HelmPath, service.HelmChartRelativePath),
+		Scratch:           true,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to generate wrapper chart: %w", err)
 	}
 
-	return k.helmClient.UpgradeFromManifests(service.Name, namespace, wrapperPath)
+	return k.helmClient.UpgradeFromManifests(
+		core.ReleaseName(target.contextName, service.Name),
+		target.namespace,
+		target.kubeContext,
+		wrapperPath,
+		contextLabels(target.contextName),
+	)
 }
 
-// UninstallService deletes a service using helm uninstall and cleans up wrapper chart.
+// UninstallService deletes a service using helm uninstall and cleans up its wrapper charts, kustomize
+// work directory and helm values files, see RemoveLocalState. A release of the service installed by an older version of dx, see
+// legacyReleaseSelector, is uninstalled as well.
 func (k *Kubernetes) UninstallService(service *domain.Service) error {
-	namespace := k.getCurrentNamespace()
-	err := k.helmClient.Uninstall(service.Name, namespace)
+	target, err := k.currentTarget()
 	if err != nil {
 		return err
 	}
 
-	// Clean up wrapper chart directory
-	contextName, err := k.configRepository.LoadCurrentContextName()
+	legacy, err := k.hasLegacyRelease(target, service.Name)
 	if err != nil {
-		// Log warning but don't fail - the service was uninstalled
-		return nil
+		return err
+	}
+	releaseName := core.ReleaseName(target.contextName, service.Name)
+	if legacy {
+		if err := k.helmClient.Uninstall(service.Name, target.namespace, target.kubeContext); err != nil {
+			return err
+		}
+		// The service may not have been installed since dx was upgraded
+		release, err := k.helmClient.ReleaseMetadata(releaseName, target.namespace, target.kubeContext)
+		if err != nil {
+			return err
+		}
+		if release == nil {
+			releaseName = ""
+		}
+	}
+
+	if releaseName != "" {
+		if err := k.helmClient.Uninstall(releaseName, target.namespace, target.kubeContext); err != nil {
+			return err
+		}
 	}
 
 	// Ignore cleanup errors - the service was already uninstalled
-	_ = k.chartWrapper.Cleanup(contextName, service.Name)
+	_ = k.removeLocalState(target.contextName, service.Name)
+	return nil
+}
+
+// localStateDirs are the directories in ~/.dx/<context> that hold a subdirectory per installed
+// service, see generateWrapperChart, renderService and writeHelmValues.
+var localStateDirs = []string{"wrapper-charts", "kustomize", "helm-values"}
+
+// ServicesWithLocalState returns the names of the services, including the dev-proxy, with wrapper
+// charts, a kustomize work directory or helm values files in ~/.dx/<context>, sorted.
+func (k *Kubernetes) ServicesWithLocalState() ([]string, error) {
+	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration context: %w", err)
+	}
+
+	var services []string
+	for _, dir := range localStateDirs {
+		names, err := k.fileService.ReadDir(filepath.Join("~", ".dx", configContext.Name, dir))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !slices.Contains(services, name) {
+				services = append(services, name)
+			}
+		}
+	}
+	slices.Sort(services)
+	return services, nil
+}
+
+// RemoveLocalState removes the wrapper charts, kustomize work directory and helm values files of a
+// service in ~/.dx/<context>, without touching its release.
+func (k *Kubernetes) RemoveLocalState(serviceName string) error {
+	configContext, err := k.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration context: %w", err)
+	}
+	return k.removeLocalState(configContext.Name, serviceName)
+}
+
+func (k *Kubernetes) removeLocalState(contextName, serviceName string) error {
+	if err := k.chartWrapper.Cleanup(contextName, serviceName); err != nil {
+		return err
+	}
+	for _, dir := range []string{"kustomize", "helm-values"} {
+		if err := k.fileService.RemoveAll(filepath.Join("~", ".dx", contextName, dir, serviceName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyReleaseSelector selects the releases installed by versions of dx that didn't scope releases
+// per context. Those releases are named after the service and lack the context label, so their
+// resources collide with those of the release of the same service, see core.ReleaseName.
+const legacyReleaseSelector = "managed-by=dx,!" + core.ContextLabel
+
+// hasLegacyRelease reports whether a service has a release installed by an older version of dx,
+// see legacyReleaseSelector.
+func (k *Kubernetes) hasLegacyRelease(target deploymentTarget, serviceName string) (bool, error) {
+	releases, err := k.helmClient.List(legacyReleaseSelector, target.namespace, target.kubeContext)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(releases, serviceName), nil
+}
+
+// checkLegacyRelease fails with instructions if a service has a release installed by an older
+// version of dx, since installing the service again would conflict with its resources.
+func (k *Kubernetes) checkLegacyRelease(target deploymentTarget, serviceName string) error {
+	legacy, err := k.hasLegacyRelease(target, serviceName)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		return fmt.Errorf(
+			"service %s was installed as the release '%s' by an older version of dx, which doesn't scope "+
+				"releases per context. Run 'dx uninstall %s' to remove it, then install the service again",
+			serviceName, serviceName, serviceName,
+		)
+	}
 	return nil
 }
 
+// HasDeployedServices reports whether services other than the dev-proxy are deployed
+// for the current context. Releases of other contexts in the same namespace are ignored.
 func (k *Kubernetes) HasDeployedServices() (bool, error) {
-	namespace := k.getCurrentNamespace()
-	releases, err := k.helmClient.List("managed-by=dx", namespace)
+	target, err := k.currentTarget()
+	if err != nil {
+		return false, err
+	}
+
+	labelSelector := fmt.Sprintf("managed-by=dx,%s=%s", core.ContextLabel, target.contextName)
+	releases, err := k.helmClient.List(labelSelector, target.namespace, target.kubeContext)
 	if err != nil {
 		return false, err
 	}
 	return len(releases) > 1, nil
 }
 
+// DeployedServices returns the names of the services with a dx release for the current context,
+// derived from the release names, see core.ReleaseName. Releases of other contexts in the same
+// namespace are ignored.
+func (k *Kubernetes) DeployedServices() ([]string, error) {
+	target, err := k.currentTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	labelSelector := fmt.Sprintf("managed-by=dx,%s=%s", core.ContextLabel, target.contextName)
+	releases, err := k.helmClient.List(labelSelector, target.namespace, target.kubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := core.ReleaseName(target.contextName, "")
+	var services []string
+	for _, release := range releases {
+		if serviceName, ok := strings.CutPrefix(release, prefix); ok && serviceName != "" {
+			services = append(services, serviceName)
+		}
+	}
+	return services, nil
+}
+
 // devProxyChecksumAnnotation is the annotation key used to store the dev-proxy configuration checksum.
 // This must match the annotation key used in the dev-proxy Helm template.
 const devProxyChecksumAnnotation = "checksum"
 
-// GetDevProxyChecksum returns the checksum annotation from the existing dev-proxy deployment.
-// Returns an empty string if the deployment doesn't exist.
+// GetDevProxyChecksum returns the checksum annotation from the existing dev-proxy deployment
+// of the current context. Returns an empty string if the deployment doesn't exist.
 func (k *Kubernetes) GetDevProxyChecksum() (string, error) {
-	namespace := k.getCurrentNamespace()
+	target, err := k.currentTarget()
+	if err != nil {
+		return "", err
+	}
+
+	clientSet, err := k.client()
+	if err != nil {
+		return "", err
+	}
 
-	deployment, err := k.clientSet.AppsV1().Deployments(namespace).Get(
+	deployment, err := clientSet.AppsV1().Deployments(target.namespace).Get(
 		context.Background(),
-		"dev-proxy",
+		core.DevProxyName(target.contextName),
 		metav1.GetOptions{},
 	)
 	if err != nil {
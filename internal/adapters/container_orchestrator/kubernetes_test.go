@@ -0,0 +1,485 @@
+package container_orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: kind-dev
+clusters:
+- name: kind-dev
+  cluster:
+    server: https://127.0.0.1:6443
+- name: kind-other
+  cluster:
+    server: https://127.0.0.1:7443
+contexts:
+- name: kind-dev
+  context:
+    cluster: kind-dev
+    user: dev
+    namespace: dev-namespace
+- name: kind-other
+  context:
+    cluster: kind-other
+    user: dev
+users:
+- name: dev
+  user:
+    token: any-token
+`
+
+func writeTestKubeConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(testKubeConfig), 0600))
+	return path
+}
+
+func createTestKubernetes(
+	t *testing.T,
+	configContext *domain.ConfigurationContext,
+	objects ...runtime.Object,
+) (*Kubernetes, *testutil.MockCommandRunner) {
+	t.Helper()
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	runner := new(testutil.MockCommandRunner)
+
+	clientSet := fake.NewClientset()
+	for _, object := range objects {
+		require.NoError(t, clientSet.Tracker().Add(object))
+	}
+
+	return &Kubernetes{
+		configRepository: configRepository,
+		loadingRules:     &clientcmd.ClientConfigLoadingRules{ExplicitPath: writeTestKubeConfig(t)},
+		clientSet:        clientSet,
+		helmClient:       ProvideHelmClient(runner),
+	}, runner
+}
+
+func TestKubernetes_currentTarget_UsesConfiguredNamespaceAndKubeContext(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{
+		Name:        "my-context",
+		Namespace:   "my-namespace",
+		KubeContext: "kind-other",
+	})
+
+	target, err := sut.currentTarget()
+
+	require.NoError(t, err)
+	assert.Equal(t, deploymentTarget{contextName: "my-context", kubeContext: "kind-other", namespace: "my-namespace"}, target)
+}
+
+func TestKubernetes_currentTarget_FallsBackToKubeContextNamespace(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context"})
+
+	target, err := sut.currentTarget()
+
+	require.NoError(t, err)
+	assert.Equal(t, "dev-namespace", target.namespace)
+	assert.Equal(t, "", target.kubeContext)
+}
+
+func TestKubernetes_currentTarget_DefaultsToDefaultNamespace(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", KubeContext: "kind-other"})
+
+	target, err := sut.currentTarget()
+
+	require.NoError(t, err)
+	assert.Equal(t, "default", target.namespace)
+}
+
+func TestKubernetes_CreateClusterEnvironmentKey_RequiresNamespace(t *testing.T) {
+	kubeSystem := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "cluster-uid"}}
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", KubeContext: "kind-other"}, kubeSystem)
+
+	_, err := sut.CreateClusterEnvironmentKey()
+
+	assert.ErrorContains(t, err, "no namespace set in context 'my-context'")
+}
+
+func TestKubernetes_CreateClusterEnvironmentKey_DependsOnNamespace(t *testing.T) {
+	kubeSystem := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "cluster-uid"}}
+	sut1, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "namespace-1"}, kubeSystem)
+	sut2, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "namespace-2"}, kubeSystem)
+
+	key1, err := sut1.CreateClusterEnvironmentKey()
+	require.NoError(t, err)
+	key2, err := sut2.CreateClusterEnvironmentKey()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, key1, key2)
+}
+
+func TestKubernetes_EnsureNamespace_CreatesMissingNamespace(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "my-namespace"})
+
+	namespace, created, err := sut.EnsureNamespace()
+
+	require.NoError(t, err)
+	assert.Equal(t, "my-namespace", namespace)
+	assert.True(t, created)
+	_, err = sut.clientSet.CoreV1().Namespaces().Get(t.Context(), "my-namespace", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestKubernetes_EnsureNamespace_ExistingNamespace(t *testing.T) {
+	existing := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-namespace"}}
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "my-namespace"}, existing)
+
+	namespace, created, err := sut.EnsureNamespace()
+
+	require.NoError(t, err)
+	assert.Equal(t, "my-namespace", namespace)
+	assert.False(t, created)
+}
+
+func TestKubernetes_GetDevProxyChecksum_ReadsDeploymentOfCurrentContext(t *testing.T) {
+	deployment := func(name, checksum string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shared"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"checksum": checksum}},
+				},
+			},
+		}
+	}
+	sut, _ := createTestKubernetes(
+		t,
+		&domain.ConfigurationContext{Name: "context-b", Namespace: "shared"},
+		deployment("dev-proxy-context-a", "checksum-a"),
+		deployment("dev-proxy-context-b", "checksum-b"),
+	)
+
+	checksum, err := sut.GetDevProxyChecksum()
+
+	require.NoError(t, err)
+	assert.Equal(t, "checksum-b", checksum)
+}
+
+func TestKubernetes_GetDevProxyChecksum_NotDeployed(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "shared"})
+
+	checksum, err := sut.GetDevProxyChecksum()
+
+	require.NoError(t, err)
+	assert.Equal(t, "", checksum)
+}
+
+func TestKubernetes_HasDeployedServices_FiltersByContext(t *testing.T) {
+	sut, runner := createTestKubernetes(t, &domain.ConfigurationContext{
+		Name:        "my-context",
+		Namespace:   "shared",
+		KubeContext: "kind-dev",
+	})
+	runner.On("Run", "helm", []string{"list", "-l", "managed-by=dx,dx-context=my-context", "--short", "--namespace", "shared", "--kube-context", "kind-dev"}).
+		Return([]byte("my-context-dev-proxy\nmy-context-api"), nil)
+
+	hasDeployedServices, err := sut.HasDeployedServices()
+
+	require.NoError(t, err)
+	assert.True(t, hasDeployedServices)
+	runner.AssertExpectations(t)
+}
+
+func TestKubernetes_DeployedServices_StripsContextPrefix(t *testing.T) {
+	sut, runner := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "shared"})
+	runner.On("Run", "helm", []string{"list", "-l", "managed-by=dx,dx-context=my-context", "--short", "--namespace", "shared"}).
+		Return([]byte("my-context-dev-proxy\nmy-context-api\nunrelated-release"), nil)
+
+	services, err := sut.DeployedServices()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev-proxy", "api"}, services)
+	runner.AssertExpectations(t)
+}
+
+func TestKubernetes_UninstallService_UsesContextReleaseName(t *testing.T) {
+	sut, runner := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "shared"})
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("RemoveAll", "~/.dx/my-context/wrapper-charts/api").Return(nil)
+	fileSystem.On("RemoveAll", "~/.dx/my-context/kustomize/api").Return(nil)
+	fileSystem.On("RemoveAll", "~/.dx/my-context/helm-values/api").Return(nil)
+	sut.chartWrapper = core.ProvideChartWrapper(fileSystem)
+	sut.fileService = fileSystem
+	runner.On("Run", "helm", []string{"list", "-l", "managed-by=dx,!dx-context", "--short", "--namespace", "shared"}).
+		Return([]byte("other-service"), nil)
+	runner.On("Run", "helm", []string{"uninstall", "my-context-api", "--namespace", "shared"}).Return([]byte(""), nil)
+
+	err := sut.UninstallService(&domain.Service{Name: "api"})
+
+	require.NoError(t, err)
+	runner.AssertExpectations(t)
+	fileSystem.AssertExpectations(t)
+}
+
+func TestKubernetes_UninstallService_RemovesLegacyRelease(t *testing.T) {
+	tests := []struct {
+		name             string
+		releaseInstalled bool
+	}{
+		{"only legacy release", false},
+		{"legacy and context release", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut, runner := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "shared"})
+			fileSystem := new(testutil.MockFileSystem)
+			fileSystem.On("RemoveAll", mock.Anything).Return(nil)
+			sut.chartWrapper = core.ProvideChartWrapper(fileSystem)
+			sut.fileService = fileSystem
+			runner.On("Run", "helm", []string{"list", "-l", "managed-by=dx,!dx-context", "--short", "--namespace", "shared"}).
+				Return([]byte("api"), nil)
+			runner.On("Run", "helm", []string{"uninstall", "api", "--namespace", "shared"}).Return([]byte(""), nil)
+			metadataArgs := []string{"get", "metadata", "my-context-api", "--output", "json", "--namespace", "shared"}
+			if tt.releaseInstalled {
+				runner.On("Run", "helm", metadataArgs).Return([]byte(`{"status":"deployed"}`), nil)
+				runner.On("Run", "helm", []string{"uninstall", "my-context-api", "--namespace", "shared"}).Return([]byte(""), nil)
+			} else {
+				runner.On("Run", "helm", metadataArgs).Return([]byte("Error: release: not found"), errors.New("exit status 1"))
+			}
+
+			err := sut.UninstallService(&domain.Service{Name: "api"})
+
+			require.NoError(t, err)
+			runner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestKubernetes_ServicesWithLocalState_RemoveLocalState(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context"})
+	fileSystem := testutil.NewTestFileSystem(t)
+	sut.chartWrapper = core.ProvideChartWrapper(fileSystem)
+	sut.fileService = fileSystem
+	for _, dir := range []string{
+		"my-context/wrapper-charts/api/1",
+		"my-context/wrapper-charts/old-worker/1",
+		"my-context/kustomize/old-worker",
+		"my-context/helm-values/legacy",
+		"other-context/kustomize/web",
+	} {
+		require.NoError(t, fileSystem.MkdirAll(filepath.Join("~", ".dx", dir), ports.ReadWriteExecute))
+	}
+
+	services, err := sut.ServicesWithLocalState()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "legacy", "old-worker"}, services)
+
+	require.NoError(t, sut.RemoveLocalState("old-worker"))
+	require.NoError(t, sut.RemoveLocalState("legacy"))
+
+	services, err = sut.ServicesWithLocalState()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api"}, services)
+}
+
+func TestKubernetes_checkLegacyRelease(t *testing.T) {
+	sut, runner := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context"})
+	runner.On("Run", "helm", []string{"list", "-l", "managed-by=dx,!dx-context", "--short", "--namespace", "shared"}).
+		Return([]byte("dev-proxy\napi"), nil)
+	target := deploymentTarget{contextName: "my-context", namespace: "shared"}
+
+	assert.NoError(t, sut.checkLegacyRelease(target, "worker"))
+	err := sut.checkLegacyRelease(target, "api")
+	assert.ErrorContains(t, err, "service api was installed as the release 'api' by an older version of dx")
+	assert.ErrorContains(t, err, "Run 'dx uninstall api'")
+}
+
+func TestProvideKubernetes_HonorsKubeconfigEnvironmentVariable(t *testing.T) {
+	// The current context is set in the first file, the contexts in the second
+	currentContextPath := filepath.Join(t.TempDir(), "current-context")
+	require.NoError(t, os.WriteFile(currentContextPath, []byte("apiVersion: v1\nkind: Config\ncurrent-context: kind-other\n"), 0600))
+	t.Setenv("KUBECONFIG", currentContextPath+string(os.PathListSeparator)+writeTestKubeConfig(t))
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(&domain.ConfigurationContext{Name: "my-context", Namespace: "my-namespace"}, nil)
+
+	sut := ProvideKubernetes(configRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	rawConfig, err := sut.kubeClientConfig(&domain.ConfigurationContext{}).RawConfig()
+
+	require.NoError(t, err)
+	assert.Equal(t, "kind-other", rawConfig.CurrentContext)
+	assert.Contains(t, rawConfig.Contexts, "kind-dev")
+}
+
+func TestProvideKubernetes_DoesNotLoadKubeconfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	configRepository := new(testutil.MockConfigRepository)
+
+	sut := ProvideKubernetes(configRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	assert.NotNil(t, sut)
+	assert.Nil(t, sut.clientSet)
+	configRepository.AssertNotCalled(t, "LoadCurrentConfigurationContext")
+}
+
+func TestKubernetes_client_CreatedOnFirstUseFromKubeContext(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(&domain.ConfigurationContext{Name: "my-context", KubeContext: "kind-other"}, nil)
+	sut := &Kubernetes{
+		configRepository: configRepository,
+		loadingRules:     &clientcmd.ClientConfigLoadingRules{ExplicitPath: writeTestKubeConfig(t)},
+	}
+
+	client1, err := sut.client()
+	require.NoError(t, err)
+	client2, err := sut.client()
+	require.NoError(t, err)
+
+	assert.Same(t, client1, client2)
+	configRepository.AssertNumberOfCalls(t, "LoadCurrentConfigurationContext", 1)
+}
+
+func TestKubernetes_registryImageOverrides_PointsImagesAtRegistry(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{
+		Name:          "test-context",
+		ImageLoader:   domain.ImageLoaderRegistry,
+		ImageRegistry: "localhost:5001",
+	})
+	service := &domain.Service{
+		DockerImages: []domain.DockerImage{{Name: "api"}},
+		RemoteImages: []string{"postgres:15", "registry.example.com:5000/cache@sha256:abc"},
+	}
+
+	overrides, err := sut.registryImageOverrides(serviceImages(service))
+
+	require.NoError(t, err)
+	assert.Equal(t, []ports.ImageOverride{
+		{Name: "api", NewName: "localhost:5001/api"},
+		{Name: "postgres", NewName: "localhost:5001/postgres"},
+		{Name: "registry.example.com:5000/cache", NewName: "localhost:5001/registry.example.com:5000/cache"},
+	}, overrides)
+}
+
+func TestKubernetes_registryImageOverrides_NoneWithoutRegistryLoader(t *testing.T) {
+	sut, _ := createTestKubernetes(t, &domain.ConfigurationContext{Name: "test-context", ImageLoader: domain.ImageLoaderKind})
+
+	overrides, err := sut.registryImageOverrides([]string{"api"})
+
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+}
+
+func TestKubernetes_writeHelmValues_WritesPrivateValuesFiles(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	sut := &Kubernetes{fileService: fileSystem}
+
+	args, removeValues, err := sut.writeHelmValues("dev", "api", [][]byte{[]byte("a: 1\n"), []byte("b: 2\n")})
+
+	require.NoError(t, err)
+	valuesDir := filepath.Join(fileSystem.BaseDir(), ".dx", "dev", "helm-values", "api")
+	assert.Equal(t, []string{
+		"-f", filepath.Join(valuesDir, "values-0.yaml"),
+		"-f", filepath.Join(valuesDir, "values-1.yaml"),
+	}, args)
+	content, err := fileSystem.ReadFile(args[3])
+	require.NoError(t, err)
+	assert.Equal(t, "b: 2\n", string(content))
+
+	removeValues()
+	exists, err := fileSystem.FileExists(args[1])
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestKubernetes_writeHelmValues_NoValues(t *testing.T) {
+	sut := &Kubernetes{fileService: testutil.NewTestFileSystem(t)}
+
+	args, removeValues, err := sut.writeHelmValues("dev", "api", nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, args)
+	removeValues()
+}
+
+func createTestDeploymentHistory(t *testing.T, fileSystem ports.FileSystem, deployments ...domain.Deployment) *core.DeploymentHistory {
+	t.Helper()
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentContextName").Return("my-context", nil)
+	history := core.ProvideDeploymentHistory(configRepository, fileSystem, new(testutil.MockScm))
+	for _, deployment := range deployments {
+		_, err := history.Record("api", deployment)
+		require.NoError(t, err)
+	}
+	return history
+}
+
+func TestKubernetes_RollbackService_ReinstallsPreviousWrapperChart(t *testing.T) {
+	sut, runner := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "shared"})
+	fileSystem := testutil.NewTestFileSystem(t)
+	require.NoError(t, fileSystem.WriteFile("/charts/1/Chart.yaml", []byte("name: api-wrapper"), 0))
+	require.NoError(t, fileSystem.WriteFile("/charts/1/templates/manifests.yaml", []byte("kind: ConfigMap"), 0))
+	sut.fileService = fileSystem
+	sut.chartWrapper = core.ProvideChartWrapper(fileSystem)
+	sut.deploymentHistory = createTestDeploymentHistory(t, fileSystem,
+		domain.Deployment{WrapperChart: "/charts/1", ManifestsChecksum: "checksum-1"},
+		domain.Deployment{WrapperChart: "/charts/2", ManifestsChecksum: "checksum-2"},
+	)
+	copyPath := filepath.Join(fileSystem.BaseDir(), ".dx", "my-context", "wrapper-charts", "api", "1")
+	runner.On("Run", "helm", []string{"upgrade", "--install", "--labels", "managed-by=dx,dx-context=my-context", "my-context-api", copyPath, "--namespace", "shared"}).
+		Return([]byte(""), nil)
+
+	deployment, err := sut.RollbackService(&domain.Service{Name: "api"}, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, deployment.Revision)
+	assert.Equal(t, 1, deployment.RollbackOf)
+	assert.Equal(t, "checksum-1", deployment.ManifestsChecksum)
+	// The chart is reinstalled as a new generation, so it is kept as long as the rollback
+	assert.Equal(t, copyPath, deployment.WrapperChart)
+	content, err := fileSystem.ReadFile("~/.dx/my-context/wrapper-charts/api/1/templates/manifests.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ConfigMap", string(content))
+	generations, err := fileSystem.ReadFile("~/.dx/my-context/wrapper-charts/api/generations.json")
+	require.NoError(t, err)
+	assert.Equal(t, "[1]", string(generations))
+	deployments, err := sut.deploymentHistory.List("api")
+	require.NoError(t, err)
+	assert.Len(t, deployments, 3)
+	runner.AssertExpectations(t)
+}
+
+func TestKubernetes_RollbackService_WrapperChartRemoved(t *testing.T) {
+	sut, runner := createTestKubernetes(t, &domain.ConfigurationContext{Name: "my-context", Namespace: "shared"})
+	fileSystem := testutil.NewTestFileSystem(t)
+	sut.fileService = fileSystem
+	sut.deploymentHistory = createTestDeploymentHistory(t, fileSystem,
+		domain.Deployment{WrapperChart: "/charts/1"},
+		domain.Deployment{WrapperChart: "/charts/2"},
+	)
+
+	_, err := sut.RollbackService(&domain.Service{Name: "api"}, 1)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the wrapper chart of revision 1 of service api was removed")
+	runner.AssertNotCalled(t, "Run")
+}
+
+func TestHashArgs_SeparatesFields(t *testing.T) {
+	assert.Equal(t, hashArgs([]string{"--set", "a=1"}, nil), hashArgs([]string{"--set", "a=1"}, nil))
+	assert.NotEqual(t, hashArgs([]string{"--set", "a=1"}, nil), hashArgs([]string{"--seta=1"}, nil))
+	assert.NotEqual(t, hashArgs(nil, [][]byte{[]byte("a: 1\n")}), hashArgs(nil, [][]byte{[]byte("a: 2\n")}))
+}
@@ -0,0 +1,527 @@
+package container_orchestrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// helmReleaseAnnotation is set by helm on every resource it manages
+	helmReleaseAnnotation = "meta.helm.sh/release-name"
+	// deploymentRevisionAnnotation is set by the deployment controller on the ReplicaSets it creates
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	rolloutPollInterval          = 2 * time.Second
+	// maxPodEvents is the number of most recent pod events included in rollout diagnostics
+	maxPodEvents = 10
+	// podLogTailLines is the number of container log lines included in rollout diagnostics
+	podLogTailLines int64 = 20
+)
+
+// failedWaitingReasons are container waiting reasons a rollout doesn't recover from without changes,
+// so waiting is aborted as soon as one is seen.
+var failedWaitingReasons = []string{
+	"CrashLoopBackOff",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+	"ErrImageNeverPull",
+	"ErrImagePull",
+	"ImagePullBackOff",
+	"InvalidImageName",
+}
+
+// workloadStatus is the readiness of a single Deployment, StatefulSet or Job.
+type workloadStatus struct {
+	name     string // e.g. "deployment/api"
+	ready    bool
+	progress string // e.g. "1/2"
+	selector *metav1.LabelSelector
+	// revisionLabels select the pods of the current revision among those matching the selector.
+	// Nil if the current revision isn't known yet, in which case no pods are checked for failures.
+	revisionLabels map[string]string
+	// failure is set when the workload itself reports that it failed
+	failure string
+}
+
+// WaitForService blocks until the Deployments, StatefulSets and Jobs of the service's release are ready.
+func (k *Kubernetes) WaitForService(
+	service *domain.Service,
+	timeout time.Duration,
+	onProgress func(status string),
+) error {
+	target, err := k.currentTarget()
+	if err != nil {
+		return err
+	}
+
+	clientSet, err := k.client()
+	if err != nil {
+		return err
+	}
+
+	waiter := rolloutWaiter{
+		clientSet:   clientSet,
+		namespace:   target.namespace,
+		releaseName: core.ReleaseName(target.contextName, service.Name),
+		serviceName: service.Name,
+		// Resources are labelled by kustomize, see ports.KustomizeClient
+		labelSelector: fmt.Sprintf("managed-by=dx,%s=%s", core.ContextLabel, target.contextName),
+	}
+	return waiter.wait(timeout, onProgress)
+}
+
+// rolloutWaiter polls the workloads of a single helm release.
+type rolloutWaiter struct {
+	clientSet   kubernetes.Interface
+	namespace   string
+	releaseName string
+	serviceName string
+	// labelSelector selects the resources of the context, which are narrowed down to those of the
+	// release by isReleaseResource
+	labelSelector string
+}
+
+func (w *rolloutWaiter) wait(timeout time.Duration, onProgress func(status string)) error {
+	deadline := time.Now().Add(timeout)
+	lastStatus := ""
+
+	for {
+		workloads, err := w.workloadStatuses()
+		if err != nil {
+			return err
+		}
+
+		var pending []workloadStatus
+		for _, workload := range workloads {
+			if workload.failure != "" {
+				return w.rolloutError(workload, workload.failure)
+			}
+			if !workload.ready {
+				pending = append(pending, workload)
+			}
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		// Fail fast when a pod is stuck in a state it won't recover from
+		for _, workload := range pending {
+			rolloutErr, err := w.findFailedPod(workload)
+			if err != nil {
+				return err
+			}
+			if rolloutErr != nil {
+				return rolloutErr
+			}
+		}
+
+		status := fmt.Sprintf(
+			"%d/%d ready, waiting for %s %s",
+			len(workloads)-len(pending),
+			len(workloads),
+			pending[0].name,
+			pending[0].progress,
+		)
+		if status != lastStatus && onProgress != nil {
+			onProgress(status)
+			lastStatus = status
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return w.rolloutError(
+				pending[0],
+				fmt.Sprintf("is not ready after %s (%s ready)", timeout, pending[0].progress),
+			)
+		}
+		time.Sleep(min(rolloutPollInterval, remaining))
+	}
+}
+
+// workloadStatuses returns the readiness of all Deployments, StatefulSets and Jobs of the release.
+func (w *rolloutWaiter) workloadStatuses() ([]workloadStatus, error) {
+	ctx := context.Background()
+	listOptions := metav1.ListOptions{LabelSelector: w.labelSelector}
+	var statuses []workloadStatus
+
+	deployments, err := w.clientSet.AppsV1().Deployments(w.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		if !w.isReleaseResource(deployment.ObjectMeta) {
+			continue
+		}
+		status := deploymentStatus(&deployment)
+		if !status.ready {
+			status.revisionLabels, err = w.currentReplicaSetLabels(&deployment)
+			if err != nil {
+				return nil, err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	statefulSets, err := w.clientSet.AppsV1().StatefulSets(w.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if w.isReleaseResource(statefulSet.ObjectMeta) {
+			statuses = append(statuses, statefulSetStatus(&statefulSet))
+		}
+	}
+
+	jobs, err := w.clientSet.BatchV1().Jobs(w.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for _, job := range jobs.Items {
+		if w.isReleaseResource(job.ObjectMeta) {
+			statuses = append(statuses, jobStatus(&job))
+		}
+	}
+
+	return statuses, nil
+}
+
+func (w *rolloutWaiter) isReleaseResource(meta metav1.ObjectMeta) bool {
+	return meta.Annotations[helmReleaseAnnotation] == w.releaseName
+}
+
+// currentReplicaSetLabels returns the pod-template-hash of the newest ReplicaSet of the deployment,
+// or nil if the deployment controller hasn't created one yet. Pods of older ReplicaSets may still
+// be failing while the new ones roll out, so they are left out of the failure checks.
+func (w *rolloutWaiter) currentReplicaSetLabels(deployment *appsv1.Deployment) (map[string]string, error) {
+	if deployment.Spec.Selector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of deployment/%s: %w", deployment.Name, err)
+	}
+
+	replicaSets, err := w.clientSet.AppsV1().ReplicaSets(w.namespace).List(
+		context.Background(),
+		metav1.ListOptions{LabelSelector: selector.String()},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets of deployment/%s: %w", deployment.Name, err)
+	}
+
+	var current *appsv1.ReplicaSet
+	currentRevision := int64(-1)
+	for i, replicaSet := range replicaSets.Items {
+		if !metav1.IsControlledBy(&replicaSet, deployment) {
+			continue
+		}
+		revision, err := strconv.ParseInt(replicaSet.Annotations[deploymentRevisionAnnotation], 10, 64)
+		if err != nil || revision <= currentRevision {
+			continue
+		}
+		current = &replicaSets.Items[i]
+		currentRevision = revision
+	}
+
+	if current == nil || current.Labels[appsv1.DefaultDeploymentUniqueLabelKey] == "" {
+		return nil, nil
+	}
+	return map[string]string{
+		appsv1.DefaultDeploymentUniqueLabelKey: current.Labels[appsv1.DefaultDeploymentUniqueLabelKey],
+	}, nil
+}
+
+func deploymentStatus(deployment *appsv1.Deployment) workloadStatus {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	status := workloadStatus{
+		name:     "deployment/" + deployment.Name,
+		progress: fmt.Sprintf("%d/%d", deployment.Status.AvailableReplicas, replicas),
+		selector: deployment.Spec.Selector,
+		ready: deployment.Status.ObservedGeneration >= deployment.Generation &&
+			deployment.Status.UpdatedReplicas >= replicas &&
+			deployment.Status.AvailableReplicas >= replicas &&
+			deployment.Status.Replicas == deployment.Status.UpdatedReplicas,
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			status.failure = fmt.Sprintf("exceeded its progress deadline: %s", condition.Message)
+		}
+	}
+	return status
+}
+
+func statefulSetStatus(statefulSet *appsv1.StatefulSet) workloadStatus {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+
+	// Pods of OnDelete StatefulSets are only updated when deleted, so only readiness is checked
+	updated := statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType ||
+		statefulSet.Status.UpdatedReplicas >= replicas
+
+	status := workloadStatus{
+		name:     "statefulset/" + statefulSet.Name,
+		progress: fmt.Sprintf("%d/%d", statefulSet.Status.ReadyReplicas, replicas),
+		selector: statefulSet.Spec.Selector,
+		ready: statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+			statefulSet.Status.ReadyReplicas >= replicas &&
+			updated,
+	}
+	if statefulSet.Status.UpdateRevision != "" {
+		status.revisionLabels = map[string]string{
+			appsv1.ControllerRevisionHashLabelKey: statefulSet.Status.UpdateRevision,
+		}
+	}
+	return status
+}
+
+func jobStatus(job *batchv1.Job) workloadStatus {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+
+	status := workloadStatus{
+		name:     "job/" + job.Name,
+		progress: fmt.Sprintf("%d/%d", job.Status.Succeeded, completions),
+		selector: job.Spec.Selector,
+		// Jobs have no revisions, all of their pods are current
+		revisionLabels: map[string]string{},
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			status.ready = true
+		case batchv1.JobFailed:
+			status.failure = fmt.Sprintf("failed: %s", condition.Message)
+		}
+	}
+	return status
+}
+
+// findFailedPod returns a rollout error for the first pod of the current revision of the workload
+// with a container in a failed waiting state, or nil if there is none.
+func (w *rolloutWaiter) findFailedPod(workload workloadStatus) (*ports.RolloutError, error) {
+	if workload.revisionLabels == nil {
+		return nil, nil
+	}
+
+	pods, err := w.workloadPods(workload)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+		if !isCurrentRevision(&pod, workload) {
+			continue
+		}
+		containerStatuses := append(slices.Clone(pod.Status.InitContainerStatuses), pod.Status.ContainerStatuses...)
+		for _, containerStatus := range containerStatuses {
+			waiting := containerStatus.State.Waiting
+			if waiting == nil || !slices.Contains(failedWaitingReasons, waiting.Reason) {
+				continue
+			}
+
+			reason := fmt.Sprintf("has container %s in %s", containerStatus.Name, waiting.Reason)
+			if waiting.Message != "" {
+				reason += ": " + waiting.Message
+			}
+			return w.podDiagnostics(workload, reason, &pod, containerStatus), nil
+		}
+	}
+	return nil, nil
+}
+
+// rolloutError builds a rollout error for the workload, with diagnostics of its first pod that isn't ready.
+func (w *rolloutWaiter) rolloutError(workload workloadStatus, reason string) error {
+	pods, err := w.workloadPods(workload)
+	if err != nil || len(pods) == 0 {
+		return &ports.RolloutError{Service: w.serviceName, Workload: workload.name, Reason: reason}
+	}
+
+	// Prefer pods of the current revision, old ones are being replaced anyway
+	var current []corev1.Pod
+	for _, candidate := range pods {
+		if isCurrentRevision(&candidate, workload) {
+			current = append(current, candidate)
+		}
+	}
+	if len(current) > 0 {
+		pods = current
+	}
+
+	pod := pods[0]
+	for _, candidate := range pods {
+		if !isPodReady(&candidate) {
+			pod = candidate
+			break
+		}
+	}
+
+	containerStatus := corev1.ContainerStatus{}
+	if len(pod.Spec.Containers) > 0 {
+		containerStatus.Name = pod.Spec.Containers[0].Name
+	}
+	for _, candidate := range pod.Status.ContainerStatuses {
+		if !candidate.Ready {
+			containerStatus = candidate
+			break
+		}
+	}
+
+	return w.podDiagnostics(workload, reason, &pod, containerStatus)
+}
+
+func (w *rolloutWaiter) workloadPods(workload workloadStatus) ([]corev1.Pod, error) {
+	if workload.selector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(workload.selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of %s: %w", workload.name, err)
+	}
+
+	pods, err := w.clientSet.CoreV1().Pods(w.namespace).List(
+		context.Background(),
+		metav1.ListOptions{LabelSelector: selector.String()},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of %s: %w", workload.name, err)
+	}
+
+	// Sort for deterministic diagnostics
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	return pods.Items, nil
+}
+
+func isCurrentRevision(pod *corev1.Pod, workload workloadStatus) bool {
+	if workload.revisionLabels == nil {
+		return false
+	}
+	for key, value := range workload.revisionLabels {
+		if pod.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podDiagnostics collects the recent events and log tail of the pod. Diagnostics are best effort,
+// failures to collect them are ignored.
+func (w *rolloutWaiter) podDiagnostics(
+	workload workloadStatus,
+	reason string,
+	pod *corev1.Pod,
+	containerStatus corev1.ContainerStatus,
+) *ports.RolloutError {
+	return &ports.RolloutError{
+		Service:  w.serviceName,
+		Workload: workload.name,
+		Reason:   reason,
+		Pod:      pod.Name,
+		Events:   w.podEvents(pod.Name),
+		Logs:     w.containerLogs(pod.Name, containerStatus),
+	}
+}
+
+func (w *rolloutWaiter) podEvents(podName string) []string {
+	events, err := w.clientSet.CoreV1().Events(w.namespace).List(
+		context.Background(),
+		metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("involvedObject.name", podName).String()},
+	)
+	if err != nil {
+		return nil
+	}
+
+	var podEvents []corev1.Event
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind == "Pod" && event.InvolvedObject.Name == podName {
+			podEvents = append(podEvents, event)
+		}
+	}
+	sort.SliceStable(podEvents, func(i, j int) bool {
+		return eventTime(&podEvents[i]).Before(eventTime(&podEvents[j]))
+	})
+	if len(podEvents) > maxPodEvents {
+		podEvents = podEvents[len(podEvents)-maxPodEvents:]
+	}
+
+	lines := make([]string, 0, len(podEvents))
+	for _, event := range podEvents {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", event.Type, event.Reason, strings.TrimSpace(event.Message)))
+	}
+	return lines
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// containerLogs returns the last log lines of the container. The logs of the previous instance are
+// preferred for restarted containers, as that is the one that crashed.
+func (w *rolloutWaiter) containerLogs(podName string, containerStatus corev1.ContainerStatus) string {
+	if containerStatus.Name == "" {
+		return ""
+	}
+
+	tailLines := podLogTailLines
+	options := &corev1.PodLogOptions{
+		Container: containerStatus.Name,
+		TailLines: &tailLines,
+		Previous:  containerStatus.RestartCount > 0,
+	}
+
+	logs, err := w.clientSet.CoreV1().Pods(w.namespace).GetLogs(podName, options).DoRaw(context.Background())
+	if err != nil && options.Previous {
+		options.Previous = false
+		logs, err = w.clientSet.CoreV1().Pods(w.namespace).GetLogs(podName, options).DoRaw(context.Background())
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(logs), "\n")
+}
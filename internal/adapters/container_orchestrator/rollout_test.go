@@ -0,0 +1,302 @@
+package container_orchestrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var rolloutTestContext = &domain.ConfigurationContext{Name: "my-context", Namespace: "dev"}
+
+func releaseMeta(name string, releaseName string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   "dev",
+		Labels:      map[string]string{"managed-by": "dx", core.ContextLabel: "my-context"},
+		Annotations: map[string]string{helmReleaseAnnotation: releaseName},
+	}
+}
+
+func testDeployment(name string, releaseName string, available int32) *appsv1.Deployment {
+	replicas := int32(1)
+	meta := releaseMeta(name, releaseName)
+	meta.UID = types.UID(name + "-uid")
+	return &appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: available,
+		},
+	}
+}
+
+// testReplicaSet returns a ReplicaSet of the deployment, whose pods are labelled with the hash
+func testReplicaSet(deployment *appsv1.Deployment, revision string, hash string) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        deployment.Name + "-" + hash,
+			Namespace:   "dev",
+			Labels:      map[string]string{"app": deployment.Name, appsv1.DefaultDeploymentUniqueLabelKey: hash},
+			Annotations: map[string]string{deploymentRevisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: deployment.Name, UID: deployment.UID, Controller: &controller},
+			},
+		},
+	}
+}
+
+func testPod(name string, app string, hash string, containerState corev1.ContainerState) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "dev",
+			Labels:    map[string]string{"app": app, appsv1.DefaultDeploymentUniqueLabelKey: hash},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "main", State: containerState, RestartCount: 3}},
+		},
+	}
+}
+
+func testPodEvent(name string, podName string, reason string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "dev"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: podName, Namespace: "dev"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " message",
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestKubernetes_WaitForService_ReturnsWhenWorkloadsAreReady(t *testing.T) {
+	completions := int32(1)
+	sut, _ := createTestKubernetes(
+		t,
+		rolloutTestContext,
+		testDeployment("api", "my-context-api", 1),
+		&appsv1.StatefulSet{
+			ObjectMeta: releaseMeta("db", "my-context-api"),
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1, UpdatedReplicas: 1},
+		},
+		&batchv1.Job{
+			ObjectMeta: releaseMeta("migrate", "my-context-api"),
+			Spec:       batchv1.JobSpec{Completions: &completions},
+			Status: batchv1.JobStatus{
+				Succeeded:  1,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+		},
+		// Not ready, but part of another context's release
+		testDeployment("other-api", "other-context-api", 0),
+	)
+
+	err := sut.WaitForService(&domain.Service{Name: "api"}, time.Minute, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestKubernetes_WaitForService_IgnoresWorkloadsWithoutContextLabels(t *testing.T) {
+	unlabelled := testDeployment("legacy-api", "my-context-api", 0)
+	unlabelled.Labels = nil
+	sut, _ := createTestKubernetes(t, rolloutTestContext, testDeployment("api", "my-context-api", 1), unlabelled)
+
+	err := sut.WaitForService(&domain.Service{Name: "api"}, time.Minute, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestKubernetes_WaitForService_FailsFastOnCrashLoopBackOff(t *testing.T) {
+	now := time.Now()
+	deployment := testDeployment("api", "my-context-api", 0)
+	sut, _ := createTestKubernetes(
+		t,
+		rolloutTestContext,
+		deployment,
+		testReplicaSet(deployment, "1", "abc"),
+		testPod("api-abc", "api", "abc", corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 40s"},
+		}),
+		testPodEvent("event-2", "api-abc", "BackOff", now),
+		testPodEvent("event-1", "api-abc", "Started", now.Add(-time.Minute)),
+		testPodEvent("event-3", "other-pod", "Pulled", now),
+	)
+
+	start := time.Now()
+	err := sut.WaitForService(&domain.Service{Name: "api"}, time.Minute, nil)
+
+	var rolloutErr *ports.RolloutError
+	require.True(t, errors.As(err, &rolloutErr))
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, "api", rolloutErr.Service)
+	assert.Equal(t, "deployment/api", rolloutErr.Workload)
+	assert.Equal(t, "api-abc", rolloutErr.Pod)
+	assert.Contains(t, rolloutErr.Reason, "CrashLoopBackOff: back-off 40s")
+	assert.Equal(
+		t,
+		[]string{"Warning Started: Started message", "Warning BackOff: BackOff message"},
+		rolloutErr.Events,
+	)
+	assert.Equal(t, "fake logs", rolloutErr.Logs)
+}
+
+func TestKubernetes_WaitForService_FailsFastOnImagePullBackOff(t *testing.T) {
+	deployment := testDeployment("api", "my-context-api", 0)
+	sut, _ := createTestKubernetes(
+		t,
+		rolloutTestContext,
+		deployment,
+		testReplicaSet(deployment, "1", "abc"),
+		testPod("api-abc", "api", "abc", corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"},
+		}),
+	)
+
+	err := sut.WaitForService(&domain.Service{Name: "api"}, time.Minute, nil)
+
+	var rolloutErr *ports.RolloutError
+	require.True(t, errors.As(err, &rolloutErr))
+	assert.Equal(t, "has container main in ImagePullBackOff", rolloutErr.Reason)
+}
+
+func TestKubernetes_WaitForService_IgnoresFailedPodsOfPreviousReplicaSets(t *testing.T) {
+	deployment := testDeployment("api", "my-context-api", 0)
+	otherDeployment := testDeployment("other", "my-context-api", 0)
+	sut, _ := createTestKubernetes(
+		t,
+		rolloutTestContext,
+		deployment,
+		testReplicaSet(deployment, "1", "old"),
+		testReplicaSet(deployment, "2", "new"),
+		// Selected by the deployment, but controlled by another one
+		testReplicaSet(otherDeployment, "3", "other"),
+		testPod("api-new", "api", "new", corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+		}),
+		testPod("api-old", "api", "old", corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		}),
+		testPod("api-other", "api", "other", corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		}),
+	)
+
+	err := sut.WaitForService(&domain.Service{Name: "api"}, 10*time.Millisecond, nil)
+
+	var rolloutErr *ports.RolloutError
+	require.True(t, errors.As(err, &rolloutErr))
+	assert.Equal(t, "is not ready after 10ms (0/1 ready)", rolloutErr.Reason)
+	assert.Equal(t, "api-new", rolloutErr.Pod)
+}
+
+func TestKubernetes_WaitForService_FailsFastOnlyOnPodsOfTheUpdateRevision(t *testing.T) {
+	replicas := int32(1)
+	statefulSet := func(updateRevision string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: releaseMeta("db", "my-context-api"),
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+			Status: appsv1.StatefulSetStatus{UpdateRevision: updateRevision},
+		}
+	}
+	crashingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db-0",
+			Namespace: "dev",
+			Labels:    map[string]string{"app": "db", appsv1.ControllerRevisionHashLabelKey: "db-1"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "main",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}},
+		},
+	}
+
+	t.Run("previous revision", func(t *testing.T) {
+		sut, _ := createTestKubernetes(t, rolloutTestContext, statefulSet("db-2"), crashingPod)
+
+		err := sut.WaitForService(&domain.Service{Name: "api"}, 10*time.Millisecond, nil)
+
+		var rolloutErr *ports.RolloutError
+		require.True(t, errors.As(err, &rolloutErr))
+		assert.Equal(t, "is not ready after 10ms (0/1 ready)", rolloutErr.Reason)
+	})
+
+	t.Run("update revision", func(t *testing.T) {
+		sut, _ := createTestKubernetes(t, rolloutTestContext, statefulSet("db-1"), crashingPod)
+
+		err := sut.WaitForService(&domain.Service{Name: "api"}, time.Minute, nil)
+
+		var rolloutErr *ports.RolloutError
+		require.True(t, errors.As(err, &rolloutErr))
+		assert.Equal(t, "statefulset/db", rolloutErr.Workload)
+		assert.Equal(t, "has container main in CrashLoopBackOff", rolloutErr.Reason)
+	})
+}
+
+func TestKubernetes_WaitForService_FailsOnFailedJob(t *testing.T) {
+	sut, _ := createTestKubernetes(
+		t,
+		rolloutTestContext,
+		&batchv1.Job{
+			ObjectMeta: releaseMeta("migrate", "my-context-api"),
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"},
+				},
+			},
+		},
+	)
+
+	err := sut.WaitForService(&domain.Service{Name: "api"}, time.Minute, nil)
+
+	var rolloutErr *ports.RolloutError
+	require.True(t, errors.As(err, &rolloutErr))
+	assert.Equal(t, "job/migrate", rolloutErr.Workload)
+	assert.Equal(t, "failed: BackoffLimitExceeded", rolloutErr.Reason)
+}
+
+func TestKubernetes_WaitForService_TimesOutWithProgress(t *testing.T) {
+	sut, _ := createTestKubernetes(
+		t,
+		rolloutTestContext,
+		testDeployment("api", "my-context-api", 0),
+		testPod("api-abc", "api", "abc", corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{},
+		}),
+	)
+	var statuses []string
+
+	err := sut.WaitForService(&domain.Service{Name: "api"}, 10*time.Millisecond, func(status string) {
+		statuses = append(statuses, status)
+	})
+
+	var rolloutErr *ports.RolloutError
+	require.True(t, errors.As(err, &rolloutErr))
+	assert.Equal(t, "is not ready after 10ms (0/1 ready)", rolloutErr.Reason)
+	assert.Equal(t, "api-abc", rolloutErr.Pod)
+	assert.Equal(t, []string{"0/1 ready, waiting for deployment/api 0/1"}, statuses)
+	assert.EqualError(t, err, "service api is not ready: deployment/api is not ready after 10ms (0/1 ready)")
+}
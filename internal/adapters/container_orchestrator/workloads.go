@@ -0,0 +1,127 @@
+package container_orchestrator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"dx/internal/core"
+	"dx/internal/ports"
+
+	"gopkg.in/yaml.v3"
+)
+
+// restartChecksumAnnotation is set on the pod templates of workloads to a checksum of what their pods
+// run, so pods are replaced when it changes and left running otherwise.
+const restartChecksumAnnotation = "dx/restart-checksum"
+
+// restartedKinds are the kinds of workloads whose pods are replaced when what they run changes
+var restartedKinds = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// workload is a resource that runs pods from a pod template.
+type workload struct {
+	kind   string
+	name   string
+	images []string
+}
+
+// podTemplateResource is the part of a workload manifest needed to find its images.
+type podTemplateResource struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Template struct {
+			Spec struct {
+				InitContainers []struct {
+					Image string `yaml:"image"`
+				} `yaml:"initContainers"`
+				Containers []struct {
+					Image string `yaml:"image"`
+				} `yaml:"containers"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+// findWorkloads returns the Deployments, StatefulSets and DaemonSets in the manifests with the
+// images of their containers.
+func findWorkloads(manifests []byte) ([]workload, error) {
+	var workloads []workload
+	decoder := yaml.NewDecoder(bytes.NewReader(manifests))
+	for {
+		var resource podTemplateResource
+		err := decoder.Decode(&resource)
+		if errors.Is(err, io.EOF) {
+			return workloads, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifests: %w", err)
+		}
+		if !slices.Contains(restartedKinds, resource.Kind) {
+			continue
+		}
+
+		w := workload{kind: resource.Kind, name: resource.Metadata.Name}
+		podSpec := resource.Spec.Template.Spec
+		for _, container := range podSpec.InitContainers {
+			w.images = append(w.images, container.Image)
+		}
+		for _, container := range podSpec.Containers {
+			w.images = append(w.images, container.Image)
+		}
+		workloads = append(workloads, w)
+	}
+}
+
+// restartPatches annotates the pod template of each workload in the manifests with a checksum of
+// the manifests and the local IDs of the images it runs. Pods are replaced when the manifests change,
+// including ConfigMaps they don't reference by hash, or when an image is rebuilt under the same tag.
+// Only the local IDs of localImages, the images the context builds or loads, are looked up. Other
+// images, such as third-party images pulled by the cluster, and images whose ID can't be determined
+// only count by reference.
+func (k *Kubernetes) restartPatches(manifests []byte, localImages []string) ([]ports.Patch, error) {
+	workloads, err := findWorkloads(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	localImageNames := make(map[string]bool, len(localImages))
+	for _, image := range localImages {
+		localImageNames[core.ImageNameWithoutTag(image)] = true
+	}
+
+	manifestsHash := sha256.Sum256(manifests)
+	var patches []ports.Patch
+	for _, w := range workloads {
+		hash := sha256.New()
+		hash.Write(manifestsHash[:])
+		for _, image := range w.images {
+			imageID := ""
+			if localImageNames[core.ImageNameWithoutTag(image)] {
+				// Ignore errors, such as an unreachable builder - the image counts by reference
+				imageID, _ = k.containerImageRepository.ImageID(image)
+			}
+			hash.Write([]byte(image + "=" + imageID))
+			hash.Write([]byte{0})
+		}
+
+		patches = append(patches, ports.Patch{
+			Target: ports.PatchTarget{Kind: w.kind, Name: w.name},
+			Operations: []ports.PatchOperation{
+				{
+					Op:    "add",
+					Path:  "/spec/template/metadata/annotations/" + strings.ReplaceAll(restartChecksumAnnotation, "/", "~1"),
+					Value: hex.EncodeToString(hash.Sum(nil)),
+				},
+			},
+		})
+	}
+	return patches, nil
+}
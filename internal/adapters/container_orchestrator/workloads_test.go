@@ -0,0 +1,121 @@
+package container_orchestrator
+
+import (
+	"errors"
+	"testing"
+
+	"dx/internal/ports"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testWorkloadManifests = `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: migrations:latest
+      containers:
+        - name: api
+          image: api:abc1234
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  template:
+    spec:
+      containers:
+        - name: db
+          image: postgres:15
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+spec:
+  template:
+    spec:
+      containers:
+        - name: agent
+          image: agent:latest
+`
+
+func TestFindWorkloads(t *testing.T) {
+	workloads, err := findWorkloads([]byte(testWorkloadManifests))
+
+	require.NoError(t, err)
+	assert.Equal(t, []workload{
+		{kind: "Deployment", name: "api", images: []string{"migrations:latest", "api:abc1234"}},
+		{kind: "StatefulSet", name: "db", images: []string{"postgres:15"}},
+		{kind: "DaemonSet", name: "agent", images: []string{"agent:latest"}},
+	}, workloads)
+}
+
+func TestFindWorkloads_InvalidManifests(t *testing.T) {
+	_, err := findWorkloads([]byte("kind: [Deployment"))
+
+	assert.ErrorContains(t, err, "failed to parse manifests")
+}
+
+func TestKubernetes_restartPatches(t *testing.T) {
+	checksums := func(manifests string, apiImageID string) map[string]interface{} {
+		containerImageRepository := new(testutil.MockContainerImageRepository)
+		containerImageRepository.On("ImageID", "api:abc1234").Return(apiImageID, nil)
+		containerImageRepository.On("ImageID", "migrations:latest").Return("sha256:migrations", nil)
+		containerImageRepository.On("ImageID", "agent:latest").Return("sha256:agent", nil)
+		sut := &Kubernetes{containerImageRepository: containerImageRepository}
+
+		// postgres is a third-party image, so its ID isn't looked up
+		patches, err := sut.restartPatches([]byte(manifests), []string{"api", "migrations:latest", "agent"})
+
+		require.NoError(t, err)
+		containerImageRepository.AssertNotCalled(t, "ImageID", "postgres:15")
+		result := make(map[string]interface{})
+		for _, patch := range patches {
+			require.Len(t, patch.Operations, 1)
+			assert.Equal(t, ports.PatchOperation{
+				Op:    "add",
+				Path:  "/spec/template/metadata/annotations/dx~1restart-checksum",
+				Value: patch.Operations[0].Value,
+			}, patch.Operations[0])
+			result[patch.Target.Kind+"/"+patch.Target.Name] = patch.Operations[0].Value
+		}
+		return result
+	}
+
+	original := checksums(testWorkloadManifests, "sha256:api-1")
+	unchanged := checksums(testWorkloadManifests, "sha256:api-1")
+	rebuiltAPI := checksums(testWorkloadManifests, "sha256:api-2")
+	changedManifests := checksums(testWorkloadManifests+"---\nkind: ConfigMap\n", "sha256:api-1")
+
+	assert.Len(t, original, 3)
+	assert.Equal(t, original, unchanged)
+	assert.NotEqual(t, original["Deployment/api"], rebuiltAPI["Deployment/api"])
+	assert.Equal(t, original["StatefulSet/db"], rebuiltAPI["StatefulSet/db"])
+	assert.NotEqual(t, original["StatefulSet/db"], changedManifests["StatefulSet/db"])
+}
+
+func TestKubernetes_restartPatches_UnknownImageIDOnBuilderError(t *testing.T) {
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("ImageID", mock.Anything).Return("", errors.New("cannot connect to the docker daemon"))
+	sut := &Kubernetes{containerImageRepository: containerImageRepository}
+
+	patches, err := sut.restartPatches([]byte(testWorkloadManifests), []string{"api", "migrations", "agent"})
+
+	require.NoError(t, err)
+	assert.Len(t, patches, 3)
+}
@@ -0,0 +1,121 @@
+package file_watcher
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"dx/internal/ports"
+)
+
+// Compile-time interface compliance check
+var _ ports.FileWatcher = (*PollingFileWatcher)(nil)
+
+// defaultPollInterval is how often the watched directories are scanned for changes
+const defaultPollInterval = 500 * time.Millisecond
+
+// PollingFileWatcher detects changes by periodically comparing the modification time and size of
+// every file in the watched directories. It works the same on every platform and file system,
+// including network and VM-shared mounts where change notifications aren't delivered.
+type PollingFileWatcher struct {
+	interval time.Duration
+}
+
+// ProvidePollingFileWatcher creates a new PollingFileWatcher.
+func ProvidePollingFileWatcher() *PollingFileWatcher {
+	return &PollingFileWatcher{interval: defaultPollInterval}
+}
+
+// fileState is what a change of a file is detected by.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (w *PollingFileWatcher) Watch(ctx context.Context, directories []string) (<-chan string, error) {
+	snapshots := make(map[string]map[string]fileState, len(directories))
+	for _, directory := range directories {
+		snapshot, err := scan(directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", directory, err)
+		}
+		snapshots[directory] = snapshot
+	}
+
+	changes := make(chan string)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for _, directory := range directories {
+				snapshot, err := scan(directory)
+				if err != nil {
+					// The directory may be briefly missing, e.g. while a branch is checked out
+					continue
+				}
+				if equalSnapshots(snapshots[directory], snapshot) {
+					continue
+				}
+				snapshots[directory] = snapshot
+
+				select {
+				case changes <- directory:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// scan returns the state of every file below the directory, keyed by path.
+func scan(directory string) (map[string]fileState, error) {
+	snapshot := make(map[string]fileState)
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files removed during the scan are picked up by the next one
+			if os.IsNotExist(err) && path != directory {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		snapshot[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return snapshot, err
+}
+
+func equalSnapshots(a map[string]fileState, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, ok := b[path]
+		if !ok || !state.modTime.Equal(other.modTime) || state.size != other.size {
+			return false
+		}
+	}
+	return true
+}
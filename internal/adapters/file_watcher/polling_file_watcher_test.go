@@ -0,0 +1,84 @@
+package file_watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveChange(t *testing.T, changes <-chan string) string {
+	t.Helper()
+	select {
+	case directory := <-changes:
+		return directory
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+		return ""
+	}
+}
+
+func TestPollingFileWatcher_Watch_ReportsChangedDirectory(t *testing.T) {
+	api := t.TempDir()
+	worker := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(api, "main.go"), []byte("package main"), 0o644))
+	sut := &PollingFileWatcher{interval: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := sut.Watch(ctx, []string{api, worker})
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(worker, "cmd"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(worker, "cmd", "main.go"), []byte("package main"), 0o644))
+	assert.Equal(t, worker, receiveChange(t, changes))
+
+	require.NoError(t, os.Remove(filepath.Join(api, "main.go")))
+	assert.Equal(t, api, receiveChange(t, changes))
+}
+
+func TestPollingFileWatcher_Watch_IgnoresGitDirectory(t *testing.T) {
+	directory := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, ".git"), 0o755))
+	sut := &PollingFileWatcher{interval: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := sut.Watch(ctx, []string{directory})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(directory, ".git", "index"), []byte("index"), 0o644))
+	select {
+	case <-changes:
+		t.Fatal("change in .git directory reported")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPollingFileWatcher_Watch_FailsForMissingDirectory(t *testing.T) {
+	sut := ProvidePollingFileWatcher()
+
+	_, err := sut.Watch(context.Background(), []string{filepath.Join(t.TempDir(), "missing")})
+
+	assert.ErrorContains(t, err, "failed to watch")
+}
+
+func TestPollingFileWatcher_Watch_ClosesChannelWhenDone(t *testing.T) {
+	sut := &PollingFileWatcher{interval: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	changes, err := sut.Watch(ctx, []string{t.TempDir()})
+	require.NoError(t, err)
+	cancel()
+
+	select {
+	case _, ok := <-changes:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed")
+	}
+}
@@ -230,6 +230,42 @@ func (f *OsFileSystem) RemoveAll(path string) error {
 	return nil
 }
 
+func (f *OsFileSystem) Rename(oldPath, newPath string) error {
+	validOldPath, err := validatePath(oldPath)
+	if err != nil {
+		return err
+	}
+	validNewPath, err := validatePath(newPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(validOldPath, validNewPath); err != nil {
+		return fmt.Errorf("failed to rename path: %w", err)
+	}
+	return nil
+}
+
+func (f *OsFileSystem) ReadDir(path string) ([]string, error) {
+	validPath, err := validatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(validPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names, nil
+}
+
 func (f *OsFileSystem) HomeDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -195,6 +195,8 @@ func TestOsFileSystem_AllMethods_DenyEmptyPath(t *testing.T) {
 		{"EnsureDirExists", func() error { return fs.EnsureDirExists("") }},
 		{"MkdirAll", func() error { return fs.MkdirAll("", ports.ReadWriteExecute) }},
 		{"RemoveAll", func() error { return fs.RemoveAll("") }},
+		{"Rename", func() error { return fs.Rename("", "~/.dx/target") }},
+		{"ReadDir", func() error { _, err := fs.ReadDir(""); return err }},
 	}
 
 	for _, tt := range tests {
@@ -472,6 +474,34 @@ func TestOsFileSystem_RemoveAll_DeniesAccessOutsideDx(t *testing.T) {
 	}
 }
 
+func TestOsFileSystem_ReadDir_ListsEntries(t *testing.T) {
+	fs := ProvideOsFileSystem()
+	dir := testDir(t)
+
+	if err := os.MkdirAll(filepath.Join(dir, "b"), 0700); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("test"), 0600); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	names, err := fs.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != "a.txt" || names[1] != "b" {
+		t.Errorf("ReadDir = %v, expected [a.txt b]", names)
+	}
+
+	names, err = fs.ReadDir(filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("ReadDir of a missing directory failed: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("ReadDir of a missing directory = %v, expected no entries", names)
+	}
+}
+
 func TestExpandPath_CrossPlatform(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
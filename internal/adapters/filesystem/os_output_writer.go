@@ -0,0 +1,31 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dx/internal/ports"
+)
+
+var _ ports.OutputWriter = (*OsOutputWriter)(nil)
+
+// OsOutputWriter implements ports.OutputWriter using the os package.
+type OsOutputWriter struct{}
+
+// ProvideOsOutputWriter creates an OsOutputWriter for Wire dependency injection.
+func ProvideOsOutputWriter() *OsOutputWriter {
+	return &OsOutputWriter{}
+}
+
+// WriteFile writes content to path, creating missing parent directories. Files are readable by
+// everyone, like files written by other tools into the working directory.
+func (w *OsOutputWriter) WriteFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOsOutputWriter_WriteFileCreatesParentDirectories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rendered", "dev-proxy", "Chart.yaml")
+
+	err := ProvideOsOutputWriter().WriteFile(path, []byte("apiVersion: v2\n"))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+	if string(content) != "apiVersion: v2\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+}
+
+func TestOsOutputWriter_WriteFileFailsWhenParentIsAFile(t *testing.T) {
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0600); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	err := ProvideOsOutputWriter().WriteFile(filepath.Join(parent, "api.yaml"), []byte("kind: Service\n"))
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
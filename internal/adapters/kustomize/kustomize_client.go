@@ -3,6 +3,7 @@ package kustomize
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"dx/internal/ports"
@@ -19,6 +20,7 @@ type Kustomization struct {
 	Resources  []string `yaml:"resources"`
 	Labels     []Label  `yaml:"labels,omitempty"`
 	Patches    []Patch  `yaml:"patches,omitempty"`
+	Images     []Image  `yaml:"images,omitempty"`
 }
 
 // Label represents a kustomize label entry.
@@ -35,6 +37,12 @@ type Patch struct {
 	Target Target `yaml:"target"`
 }
 
+// Image represents a kustomize images entry.
+type Image struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName"`
+}
+
 // PatchFile represents a patch file to be written to disk.
 type PatchFile struct {
 	Filename string
@@ -51,6 +59,7 @@ type Target struct {
 type PatchOperation struct {
 	Op    string      `yaml:"op"`
 	Path  string      `yaml:"path"`
+	From  string      `yaml:"from,omitempty"`
 	Value interface{} `yaml:"value,omitempty"`
 }
 
@@ -68,10 +77,10 @@ func ProvideKustomizeClient(commandRunner ports.CommandRunner, fileSystem ports.
 	}
 }
 
-// Apply takes raw YAML manifests and applies patches using kubectl kustomize.
-// Files are written to workDir for inspection.
-func (c *Client) Apply(manifests []byte, patches []ports.Patch, workDir string) ([]byte, error) {
-	if len(patches) == 0 {
+// Apply takes raw YAML manifests and applies labels, patches and image overrides using kubectl
+// kustomize. Files are written to workDir for inspection.
+func (c *Client) Apply(manifests []byte, kustomization ports.Kustomization, workDir string) ([]byte, error) {
+	if len(kustomization.Patches) == 0 && len(kustomization.Labels) == 0 && len(kustomization.Images) == 0 {
 		return manifests, nil
 	}
 
@@ -87,7 +96,7 @@ func (c *Client) Apply(manifests []byte, patches []ports.Patch, workDir string)
 	}
 
 	// Build kustomization and patch files
-	kustomization, patchFiles, err := buildKustomization(patches)
+	kustomizationFile, patchFiles, err := buildKustomization(kustomization)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build kustomization: %w", err)
 	}
@@ -101,7 +110,7 @@ func (c *Client) Apply(manifests []byte, patches []ports.Patch, workDir string)
 	}
 
 	// Write kustomization.yaml
-	kustomizationYAML, err := yaml.Marshal(kustomization)
+	kustomizationYAML, err := yaml.Marshal(kustomizationFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal kustomization: %w", err)
 	}
@@ -120,23 +129,32 @@ func (c *Client) Apply(manifests []byte, patches []ports.Patch, workDir string)
 	return output, nil
 }
 
-// buildKustomization creates a Kustomization from patches and returns patch files to write.
-func buildKustomization(patches []ports.Patch) (Kustomization, []PatchFile, error) {
+// buildKustomization creates a Kustomization from labels and patches and returns patch files to write.
+func buildKustomization(config ports.Kustomization) (Kustomization, []PatchFile, error) {
+	labels := map[string]string{"managed-by": "dx"}
+	for key, value := range config.Labels {
+		labels[key] = value
+	}
+
 	kustomization := Kustomization{
 		APIVersion: "kustomize.config.k8s.io/v1beta1",
 		Kind:       "Kustomization",
 		Resources:  []string{"resources.yaml"},
 		Labels: []Label{
 			{
-				Pairs:            map[string]string{"managed-by": "dx"},
+				Pairs:            labels,
 				IncludeSelectors: false,
 			},
 		},
 	}
 
+	for _, image := range config.Images {
+		kustomization.Images = append(kustomization.Images, Image{Name: image.Name, NewName: image.NewName})
+	}
+
 	var patchFiles []PatchFile
 
-	for _, p := range patches {
+	for _, p := range config.Patches {
 		// Separate add operations (strategic merge) from replace/remove (JSON patch)
 		var addOps []ports.PatchOperation
 		var jsonPatchOps []ports.PatchOperation
@@ -157,6 +175,15 @@ func buildKustomization(patches []ports.Patch) (Kustomization, []PatchFile, erro
 			}
 			if len(patchContent) > 0 {
 				filename := fmt.Sprintf("patch-%s.yaml", patchNameFromPath(op.Path))
+				if slices.ContainsFunc(patchFiles, func(f PatchFile) bool { return f.Filename == filename }) {
+					// Patches of different targets can share a path, e.g. an annotation set on each workload
+					filename = fmt.Sprintf(
+						"patch-%s-%s-%s.yaml",
+						strings.ToLower(p.Target.Kind),
+						p.Target.Name,
+						patchNameFromPath(op.Path),
+					)
+				}
 
 				patchFiles = append(patchFiles, PatchFile{
 					Filename: filename,
@@ -173,26 +200,28 @@ func buildKustomization(patches []ports.Patch) (Kustomization, []PatchFile, erro
 			}
 		}
 
-		// Create inline JSON patch for replace/remove operations
+		// Create inline JSON patch for replace/remove operations and the operations applied as they are
+		jsonPatchOps = append(jsonPatchOps, p.JSONPatch...)
 		if len(jsonPatchOps) > 0 {
-			var ops []PatchOperation
-			for _, op := range jsonPatchOps {
-				patchOp := PatchOperation{
-					Op:   op.Op,
-					Path: op.Path,
-				}
-				if op.Op != "remove" {
-					patchOp.Value = op.Value
-				}
-				ops = append(ops, patchOp)
+			patch, err := buildJSONPatch(p.Target, jsonPatchOps)
+			if err != nil {
+				return Kustomization{}, nil, err
 			}
+			kustomization.Patches = append(kustomization.Patches, patch)
+		}
 
-			opsYAML, err := yaml.Marshal(ops)
+		if p.StrategicMerge != "" {
+			patchContent, err := completeStrategicMergePatch(p.Target, p.StrategicMerge)
 			if err != nil {
-				return Kustomization{}, nil, fmt.Errorf("failed to marshal JSON patch operations: %w", err)
+				return Kustomization{}, nil, fmt.Errorf("invalid strategic merge patch for %s: %w", p.Target.Kind, err)
 			}
+			filename := fmt.Sprintf("patch-merge-%d.yaml", len(patchFiles)+1)
+			patchFiles = append(patchFiles, PatchFile{
+				Filename: filename,
+				Content:  patchContent,
+			})
 			kustomization.Patches = append(kustomization.Patches, Patch{
-				Patch: string(opsYAML),
+				Path: filename,
 				Target: Target{
 					Kind: p.Target.Kind,
 					Name: p.Target.Name,
@@ -204,6 +233,72 @@ func buildKustomization(patches []ports.Patch) (Kustomization, []PatchFile, erro
 	return kustomization, patchFiles, nil
 }
 
+// buildJSONPatch creates an inline JSON patch (RFC 6902) for the operations.
+func buildJSONPatch(target ports.PatchTarget, operations []ports.PatchOperation) (Patch, error) {
+	var ops []PatchOperation
+	for _, op := range operations {
+		patchOp := PatchOperation{
+			Op:   op.Op,
+			Path: op.Path,
+			From: op.From,
+		}
+		if op.Op != "remove" && op.Op != "move" && op.Op != "copy" {
+			patchOp.Value = op.Value
+		}
+		ops = append(ops, patchOp)
+	}
+
+	opsYAML, err := yaml.Marshal(ops)
+	if err != nil {
+		return Patch{}, fmt.Errorf("failed to marshal JSON patch operations: %w", err)
+	}
+	return Patch{
+		Patch: string(opsYAML),
+		Target: Target{
+			Kind: target.Kind,
+			Name: target.Name,
+		},
+	}, nil
+}
+
+// completeStrategicMergePatch fills in the apiVersion, kind and metadata.name kustomize requires in a
+// strategic merge patch when they are missing. The name is a placeholder, the target selects the
+// resources.
+func completeStrategicMergePatch(target ports.PatchTarget, patch string) ([]byte, error) {
+	var content map[string]interface{}
+	if err := yaml.Unmarshal([]byte(patch), &content); err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, fmt.Errorf("patch is empty")
+	}
+
+	if _, ok := content["apiVersion"]; !ok {
+		content["apiVersion"] = apiVersionForKind(target.Kind)
+	}
+	if _, ok := content["kind"]; !ok {
+		content["kind"] = target.Kind
+	}
+	metadata, ok := content["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		content["metadata"] = metadata
+	}
+	if _, ok := metadata["name"]; !ok {
+		name := target.Name
+		if name == "" {
+			name = "placeholder"
+		}
+		metadata["name"] = name
+	}
+
+	patchYAML, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal strategic merge patch: %w", err)
+	}
+	return patchYAML, nil
+}
+
 // buildStrategicMergePatch creates a YAML strategic merge patch from a JSON pointer path.
 // The patch includes apiVersion, kind, and a placeholder name for kustomize compatibility.
 func buildStrategicMergePatch(target ports.PatchTarget, op ports.PatchOperation) ([]byte, error) {
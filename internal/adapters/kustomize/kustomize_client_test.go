@@ -41,6 +41,10 @@ func (m *mockCommandRunner) RunWithStdin(stdin io.Reader, name string, args ...s
 	return nil, nil
 }
 
+func (m *mockCommandRunner) RunWithEnvAndStdin(stdin io.Reader, env []string, name string, args ...string) ([]byte, error) {
+	return nil, nil
+}
+
 func (m *mockCommandRunner) RunInteractive(name string, args ...string) error {
 	return nil
 }
@@ -51,7 +55,7 @@ func TestClient_Apply_NoPatches(t *testing.T) {
 	client := ProvideKustomizeClient(runner, fs)
 
 	manifests := []byte("apiVersion: v1\nkind: ConfigMap\n")
-	result, err := client.Apply(manifests, nil, t.TempDir())
+	result, err := client.Apply(manifests, ports.Kustomization{}, t.TempDir())
 
 	require.NoError(t, err)
 	assert.Equal(t, manifests, result)
@@ -82,7 +86,7 @@ func TestClient_Apply_CallsKubectl(t *testing.T) {
 	}
 
 	workDir := t.TempDir()
-	_, err := client.Apply(manifests, patches, workDir)
+	_, err := client.Apply(manifests, ports.Kustomization{Patches: patches}, workDir)
 	require.NoError(t, err)
 
 	assert.Equal(t, "kubectl", capturedName)
@@ -93,7 +97,7 @@ func TestClient_Apply_CallsKubectl(t *testing.T) {
 func TestBuildKustomization_Labels(t *testing.T) {
 	patches := []ports.Patch{}
 
-	k, patchFiles, err := buildKustomization(patches)
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
 
 	require.NoError(t, err)
 	assert.Equal(t, "kustomize.config.k8s.io/v1beta1", k.APIVersion)
@@ -104,6 +108,24 @@ func TestBuildKustomization_Labels(t *testing.T) {
 	assert.Empty(t, patchFiles)
 }
 
+func TestBuildKustomization_AdditionalLabels(t *testing.T) {
+	k, _, err := buildKustomization(ports.Kustomization{Labels: map[string]string{"dx-context": "my-context"}})
+
+	require.NoError(t, err)
+	require.Len(t, k.Labels, 1)
+	assert.Equal(t, map[string]string{"managed-by": "dx", "dx-context": "my-context"}, k.Labels[0].Pairs)
+	assert.False(t, k.Labels[0].IncludeSelectors)
+}
+
+func TestBuildKustomization_Images(t *testing.T) {
+	k, _, err := buildKustomization(ports.Kustomization{
+		Images: []ports.ImageOverride{{Name: "api", NewName: "localhost:5001/api"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Image{{Name: "api", NewName: "localhost:5001/api"}}, k.Images)
+}
+
 func TestBuildKustomization_AddOperationUsesStrategicMerge(t *testing.T) {
 	patches := []ports.Patch{
 		{
@@ -114,7 +136,7 @@ func TestBuildKustomization_AddOperationUsesStrategicMerge(t *testing.T) {
 		},
 	}
 
-	k, patchFiles, err := buildKustomization(patches)
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
 
 	require.NoError(t, err)
 	require.Len(t, k.Patches, 1)
@@ -145,7 +167,7 @@ func TestBuildKustomization_ReplaceOperationUsesJSONPatch(t *testing.T) {
 		},
 	}
 
-	k, patchFiles, err := buildKustomization(patches)
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
 
 	require.NoError(t, err)
 	// JSON patches use inline Patch, no files
@@ -168,7 +190,7 @@ func TestBuildKustomization_RemoveOperation(t *testing.T) {
 		},
 	}
 
-	k, patchFiles, err := buildKustomization(patches)
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
 
 	require.NoError(t, err)
 	assert.Empty(t, patchFiles)
@@ -177,6 +199,27 @@ func TestBuildKustomization_RemoveOperation(t *testing.T) {
 	assert.Contains(t, k.Patches[0].Patch, "path: /data/unwanted")
 }
 
+func TestBuildKustomization_SamePathOnSeveralTargets(t *testing.T) {
+	patches := []ports.Patch{
+		{
+			Target:     ports.PatchTarget{Kind: "Deployment", Name: "api"},
+			Operations: []ports.PatchOperation{{Op: "add", Path: "/spec/template/metadata/annotations/foo", Value: "a"}},
+		},
+		{
+			Target:     ports.PatchTarget{Kind: "StatefulSet", Name: "db"},
+			Operations: []ports.PatchOperation{{Op: "add", Path: "/spec/template/metadata/annotations/foo", Value: "b"}},
+		},
+	}
+
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
+
+	require.NoError(t, err)
+	require.Len(t, patchFiles, 2)
+	assert.Equal(t, "patch-foo.yaml", patchFiles[0].Filename)
+	assert.Equal(t, "patch-statefulset-db-foo.yaml", patchFiles[1].Filename)
+	assert.Equal(t, "patch-statefulset-db-foo.yaml", k.Patches[1].Path)
+}
+
 func TestBuildKustomization_MixedOperations(t *testing.T) {
 	patches := []ports.Patch{
 		{
@@ -188,7 +231,7 @@ func TestBuildKustomization_MixedOperations(t *testing.T) {
 		},
 	}
 
-	k, patchFiles, err := buildKustomization(patches)
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
 
 	require.NoError(t, err)
 	// Should have two patches: one strategic merge (file), one JSON patch (inline)
@@ -207,6 +250,69 @@ func TestBuildKustomization_MixedOperations(t *testing.T) {
 	assert.Contains(t, patchContent, "annotations:")
 }
 
+func TestBuildKustomization_JSONPatchKeepsAddOperations(t *testing.T) {
+	patches := []ports.Patch{
+		{
+			Target: ports.PatchTarget{Kind: "Deployment", Name: "api"},
+			JSONPatch: []ports.PatchOperation{
+				{Op: "add", Path: "/spec/template/spec/containers/0/args/-", Value: "--verbose"},
+				{Op: "move", Path: "/metadata/labels/tier", From: "/metadata/labels/layer"},
+				{Op: "test", Path: "/spec/paused", Value: false},
+			},
+		},
+	}
+
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
+
+	require.NoError(t, err)
+	assert.Empty(t, patchFiles)
+	require.Len(t, k.Patches, 1)
+	assert.Equal(t, Target{Kind: "Deployment", Name: "api"}, k.Patches[0].Target)
+	assert.Contains(t, k.Patches[0].Patch, "op: add")
+	assert.Contains(t, k.Patches[0].Patch, "value: --verbose")
+	assert.Contains(t, k.Patches[0].Patch, "from: /metadata/labels/layer")
+	assert.Contains(t, k.Patches[0].Patch, "value: false")
+}
+
+func TestBuildKustomization_StrategicMerge(t *testing.T) {
+	patches := []ports.Patch{
+		{
+			Target:         ports.PatchTarget{Kind: "Deployment", Name: "api"},
+			StrategicMerge: "spec:\n  template:\n    spec:\n      containers:\n        - name: api\n          resources:\n            limits:\n              memory: 1Gi\n",
+		},
+		{
+			Target:         ports.PatchTarget{Kind: "Service"},
+			StrategicMerge: "apiVersion: v1\nkind: Service\nmetadata:\n  name: any\n  annotations:\n    a: b\n",
+		},
+	}
+
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
+
+	require.NoError(t, err)
+	require.Len(t, patchFiles, 2)
+	require.Len(t, k.Patches, 2)
+	assert.Equal(t, "patch-merge-1.yaml", k.Patches[0].Path)
+	assert.Equal(t, "patch-merge-2.yaml", k.Patches[1].Path)
+	assert.Equal(t, Target{Kind: "Service"}, k.Patches[1].Target)
+
+	first := string(patchFiles[0].Content)
+	assert.Contains(t, first, "apiVersion: apps/v1")
+	assert.Contains(t, first, "kind: Deployment")
+	assert.Contains(t, first, "name: api")
+	assert.Contains(t, first, "memory: 1Gi")
+	second := string(patchFiles[1].Content)
+	assert.Contains(t, second, "name: any")
+	assert.Contains(t, second, "a: b")
+}
+
+func TestBuildKustomization_InvalidStrategicMerge(t *testing.T) {
+	patches := []ports.Patch{{Target: ports.PatchTarget{Kind: "Deployment"}, StrategicMerge: "- not a mapping"}}
+
+	_, _, err := buildKustomization(ports.Kustomization{Patches: patches})
+
+	assert.ErrorContains(t, err, "invalid strategic merge patch for Deployment")
+}
+
 func TestBuildStrategicMergePatch(t *testing.T) {
 	target := ports.PatchTarget{Kind: "Deployment"}
 	op := ports.PatchOperation{
@@ -286,7 +392,7 @@ func TestClient_Apply_KubectlError(t *testing.T) {
 		},
 	}
 
-	_, err := client.Apply(manifests, patches, t.TempDir())
+	_, err := client.Apply(manifests, ports.Kustomization{Patches: patches}, t.TempDir())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "kubectl kustomize failed")
@@ -310,7 +416,7 @@ func TestClient_Apply_WritesCorrectFiles(t *testing.T) {
 		},
 	}
 
-	_, err := client.Apply(manifests, patches, workDir)
+	_, err := client.Apply(manifests, ports.Kustomization{Patches: patches}, workDir)
 	require.NoError(t, err)
 
 	// Verify resources.yaml was written
@@ -339,7 +445,7 @@ func TestBuildKustomization_EmptyOperations(t *testing.T) {
 		},
 	}
 
-	k, patchFiles, err := buildKustomization(patches)
+	k, patchFiles, err := buildKustomization(ports.Kustomization{Patches: patches})
 
 	require.NoError(t, err)
 	// Should produce no patches when operations are empty
@@ -448,6 +554,14 @@ func (m *mockFileSystemWithErrors) RemoveAll(path string) error {
 	return nil
 }
 
+func (m *mockFileSystemWithErrors) Rename(oldPath, newPath string) error {
+	return nil
+}
+
+func (m *mockFileSystemWithErrors) ReadDir(path string) ([]string, error) {
+	return nil, nil
+}
+
 func (m *mockFileSystemWithErrors) HomeDir() (string, error) {
 	return "/home/test", nil
 }
@@ -466,7 +580,7 @@ func TestClient_Apply_MkdirAllError(t *testing.T) {
 		},
 	}
 
-	_, err := client.Apply(manifests, patches, "/workdir")
+	_, err := client.Apply(manifests, ports.Kustomization{Patches: patches}, "/workdir")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create work directory")
 }
@@ -490,7 +604,7 @@ func TestClient_Apply_WriteResourcesError(t *testing.T) {
 		},
 	}
 
-	_, err := client.Apply(manifests, patches, "/workdir")
+	_, err := client.Apply(manifests, ports.Kustomization{Patches: patches}, "/workdir")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to write resources")
 }
@@ -514,7 +628,7 @@ func TestClient_Apply_WritePatchFileError(t *testing.T) {
 		},
 	}
 
-	_, err := client.Apply(manifests, patches, "/workdir")
+	_, err := client.Apply(manifests, ports.Kustomization{Patches: patches}, "/workdir")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to write patch file")
 }
@@ -538,7 +652,7 @@ func TestClient_Apply_WriteKustomizationError(t *testing.T) {
 		},
 	}
 
-	_, err := client.Apply(manifests, patches, "/workdir")
+	_, err := client.Apply(manifests, ports.Kustomization{Patches: patches}, "/workdir")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to write kustomization.yaml")
 }
@@ -2,17 +2,23 @@ package scm
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"dx/internal/ports"
 )
 
 var _ ports.Scm = (*Git)(nil)
 
+// Git is safe for concurrent use. Downloads to the same repository path are serialized.
 type Git struct {
 	gitClient  *GitClient
 	fileSystem ports.FileSystem
+	mu         sync.Mutex
 	// Track unique repo+branch combinations to avoid duplicate clones
 	cloned map[string]bool
+	// One lock per repository path, held while the repository is downloaded
+	pathLocks map[string]*sync.Mutex
 }
 
 func ProvideGit(gitClient *GitClient, fileSystem ports.FileSystem) *Git {
@@ -20,12 +26,36 @@ func ProvideGit(gitClient *GitClient, fileSystem ports.FileSystem) *Git {
 		gitClient:  gitClient,
 		fileSystem: fileSystem,
 		cloned:     make(map[string]bool),
+		pathLocks:  make(map[string]*sync.Mutex),
 	}
 }
 
+// lockPath locks the repository path and returns the function that unlocks it.
+func (g *Git) lockPath(repositoryPath string) func() {
+	g.mu.Lock()
+	pathLock, ok := g.pathLocks[repositoryPath]
+	if !ok {
+		pathLock = &sync.Mutex{}
+		g.pathLocks[repositoryPath] = pathLock
+	}
+	g.mu.Unlock()
+
+	pathLock.Lock()
+	return pathLock.Unlock
+}
+
+func (g *Git) isCloned(repoKey string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.cloned[repoKey]
+}
+
 func (g *Git) Download(repositoryUrl string, ref string, repositoryPath string) error {
+	unlock := g.lockPath(repositoryPath)
+	defer unlock()
+
 	repoKey := repositoryPath + ":" + ref
-	if !g.cloned[repoKey] {
+	if !g.isCloned(repoKey) {
 		if g.gitClient.ContainsRepository(repositoryPath) {
 			err := g.gitClient.UpdateOriginUrl(repositoryPath, repositoryUrl)
 			if err != nil {
@@ -82,6 +112,54 @@ func (g *Git) Download(repositoryUrl string, ref string, repositoryPath string)
 		}
 	}
 
+	g.mu.Lock()
 	g.cloned[repoKey] = true
+	g.mu.Unlock()
 	return nil
 }
+
+// Revision returns the commit SHA checked out in the repository.
+func (g *Git) Revision(repositoryPath string) (string, error) {
+	revision, err := g.gitClient.GetRevisionForCommit(repositoryPath, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(revision), nil
+}
+
+// Branch returns the branch checked out in the repository, or an empty string if HEAD is detached.
+func (g *Git) Branch(repositoryPath string) (string, error) {
+	ref, err := g.gitClient.GetCurrentRef(repositoryPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch of %s: %w", repositoryPath, err)
+	}
+	if ref == "HEAD" {
+		return "", nil
+	}
+	return ref, nil
+}
+
+// HasUncommittedChanges reports whether any file under path differs from the commit checked out
+// in its working tree, including untracked files.
+func (g *Git) HasUncommittedChanges(path string) (bool, error) {
+	status, err := g.gitClient.GetStatus(path)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(status) != "", nil
+}
+
+// HasIgnoredFiles reports whether path contains files that are ignored by git, such as build
+// output or dependencies that aren't committed.
+func (g *Git) HasIgnoredFiles(path string) (bool, error) {
+	status, err := g.gitClient.GetIgnoredStatus(path)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(status, "\n") {
+		if strings.HasPrefix(line, "!! ") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
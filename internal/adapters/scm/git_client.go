@@ -112,6 +112,27 @@ func (g *GitClient) GetRevisionForCommit(repositoryPath string, commit string) (
 	return string(output), nil
 }
 
+// GetStatus returns the porcelain status of the files under path in its working tree.
+func (g *GitClient) GetStatus(path string) (string, error) {
+	output, err := g.commandRunner.RunInDir(path, "git", "status", "--porcelain", "--", ".")
+	if err != nil {
+		return "", fmt.Errorf("failed to get status of %s: %v\n%s", path, err, string(output))
+	}
+
+	return string(output), nil
+}
+
+// GetIgnoredStatus returns the porcelain status of the files under path in its working tree,
+// including ignored files, which are listed with the status "!!".
+func (g *GitClient) GetIgnoredStatus(path string) (string, error) {
+	output, err := g.commandRunner.RunInDir(path, "git", "status", "--porcelain", "--ignored", "--", ".")
+	if err != nil {
+		return "", fmt.Errorf("failed to get status of %s: %v\n%s", path, err, string(output))
+	}
+
+	return string(output), nil
+}
+
 func (g *GitClient) ResetToCommit(repositoryPath string, commit string) error {
 	output, err := g.commandRunner.RunInDir(repositoryPath, "git", "-c", "core.autocrlf=false", "reset", "--hard", commit)
 	if err != nil {
@@ -217,6 +217,34 @@ func TestGitClient_GetRevisionForCommit_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get origin revision")
 }
 
+func TestGitClient_GetStatus_Success(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	fileSystem := new(testutil.MockFileSystem)
+	commandRunner.On("RunInDir", "/repo/api", "git", []string{"status", "--porcelain", "--", "."}).
+		Return([]byte(" M main.go\n?? new.go\n"), nil)
+
+	client := ProvideGitClient(commandRunner, fileSystem)
+
+	status, err := client.GetStatus("/repo/api")
+
+	require.NoError(t, err)
+	assert.Equal(t, " M main.go\n?? new.go\n", status)
+}
+
+func TestGitClient_GetStatus_Error(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	fileSystem := new(testutil.MockFileSystem)
+	commandRunner.On("RunInDir", "/src", "git", []string{"status", "--porcelain", "--", "."}).
+		Return([]byte("fatal: not a git repository"), errors.New("exit status 128"))
+
+	client := ProvideGitClient(commandRunner, fileSystem)
+
+	_, err := client.GetStatus("/src")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get status of /src")
+}
+
 func TestGitClient_ResetToCommit_Success(t *testing.T) {
 	commandRunner := new(testutil.MockCommandRunner)
 	fileSystem := new(testutil.MockFileSystem)
@@ -0,0 +1,116 @@
+package scm
+
+import (
+	"sync"
+	"testing"
+
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGit_Download_ConcurrentDownloadsOfSamePathCloneOnce(t *testing.T) {
+	commandRunner := new(testutil.MockCommandRunner)
+	commandRunner.On("RunWithEnv", "git", sshBatchModeEnv, mock.Anything).Return([]byte{}, nil)
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("FileExists", "/repo/.git/HEAD").Return(false, nil)
+	fileSystem.On("MkdirAll", "/repo", mock.Anything).Return(nil)
+	sut := ProvideGit(ProvideGitClient(commandRunner, fileSystem), fileSystem)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = sut.Download("https://github.com/user/repo.git", "main", "/repo")
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	commandRunner.AssertNumberOfCalls(t, "RunWithEnv", 1)
+}
+
+func TestGit_HasUncommittedChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		dirty  bool
+	}{
+		{"clean", "", false},
+		{"modified file", " M main.go\n", true},
+		{"untracked file", "?? new.go\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("RunInDir", "/repo", "git", []string{"status", "--porcelain", "--", "."}).
+				Return([]byte(tt.status), nil)
+			fileSystem := new(testutil.MockFileSystem)
+			sut := ProvideGit(ProvideGitClient(commandRunner, fileSystem), fileSystem)
+
+			dirty, err := sut.HasUncommittedChanges("/repo")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.dirty, dirty)
+		})
+	}
+}
+
+func TestGit_HasIgnoredFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		ignored bool
+	}{
+		{"no ignored files", "", false},
+		{"modified file only", " M main.go\n", false},
+		{"ignored directory", " M main.go\n!! node_modules/\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("RunInDir", "/repo", "git", []string{"status", "--porcelain", "--ignored", "--", "."}).
+				Return([]byte(tt.status), nil)
+			fileSystem := new(testutil.MockFileSystem)
+			sut := ProvideGit(ProvideGitClient(commandRunner, fileSystem), fileSystem)
+
+			ignored, err := sut.HasIgnoredFiles("/repo")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ignored, ignored)
+		})
+	}
+}
+
+func TestGit_Branch(t *testing.T) {
+	tests := []struct {
+		name   string
+		ref    string
+		branch string
+	}{
+		{"branch", "feature/login\n", "feature/login"},
+		{"detached HEAD", "HEAD\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandRunner := new(testutil.MockCommandRunner)
+			commandRunner.On("RunInDir", "/repo", "git", []string{"rev-parse", "--abbrev-ref", "HEAD"}).
+				Return([]byte(tt.ref), nil)
+			fileSystem := new(testutil.MockFileSystem)
+			sut := ProvideGit(ProvideGitClient(commandRunner, fileSystem), fileSystem)
+
+			branch, err := sut.Branch("/repo")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.branch, branch)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// UnifiedDiff returns a unified diff between from and to with three lines of context.
+// Returns an empty string when the contents are equal.
+func UnifiedDiff(fromName, toName string, from, to []byte) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(from)),
+		B:        difflib.SplitLines(string(to)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+	if err != nil {
+		// The diff is written to an in-memory buffer, which cannot fail
+		return ""
+	}
+	return diff
+}
+
+// PrintDiff prints a unified diff, coloring added lines green, removed lines red and hunk headers cyan.
+func PrintDiff(diff string) {
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+			fmt.Println(Bold(text))
+		case strings.HasPrefix(text, "+"):
+			fmt.Println(Success(text))
+		case strings.HasPrefix(text, "-"):
+			fmt.Println(Error(text))
+		case strings.HasPrefix(text, "@@"):
+			fmt.Println(Info(text))
+		default:
+			fmt.Println(text)
+		}
+	}
+}
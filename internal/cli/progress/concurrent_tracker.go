@@ -13,6 +13,7 @@ import (
 // ConcurrentTrackerItem represents a single task being tracked concurrently
 type ConcurrentTrackerItem struct {
 	Name      string
+	Info      string // Progress of the running task (e.g., rollout status)
 	Status    Status
 	Duration  time.Duration
 	Error     error
@@ -36,6 +37,7 @@ type ConcurrentTracker struct {
 	actionVerb   string
 	startTime    time.Time
 	writer       io.Writer
+	latestInfo   int // Index of the item whose info was updated last, -1 if none
 }
 
 // NewConcurrentTracker creates a new concurrent progress tracker
@@ -58,6 +60,7 @@ func NewConcurrentTracker(names []string, verb string) *ConcurrentTracker {
 		stopChan:   make(chan struct{}),
 		actionVerb: verb,
 		writer:     os.Stdout,
+		latestInfo: -1,
 	}
 }
 
@@ -78,6 +81,7 @@ func NewConcurrentTrackerWithWriter(names []string, verb string, writer io.Write
 		stopChan:   make(chan struct{}),
 		actionVerb: verb,
 		writer:     writer,
+		latestInfo: -1,
 	}
 }
 
@@ -107,6 +111,25 @@ func (t *ConcurrentTracker) StartItem(index int) {
 	}
 }
 
+// SetItemInfo updates the progress info of a running item. In TTY mode the most recent update is
+// shown in the status line, otherwise it is printed.
+func (t *ConcurrentTracker) SetItemInfo(index int, info string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.items[index].Info == info {
+		return
+	}
+	t.items[index].Info = info
+	t.latestInfo = index
+
+	if !t.isTTY && info != "" {
+		// Non-TTY mode: print timestamped progress message
+		ts := time.Now().Format("15:04:05")
+		fmt.Fprintf(t.writer, "[%s]   %s: %s\n", ts, t.items[index].Name, info)
+	}
+}
+
 // CompleteItem marks an item as completed (success or failure) and prints its status
 func (t *ConcurrentTracker) CompleteItem(index int, err error) {
 	t.mu.Lock()
@@ -145,6 +168,10 @@ func (t *ConcurrentTracker) CompleteItem(index int, err error) {
 			sym = "x"
 		}
 		suffix = fmt.Sprintf("(%s) FAILED", FormatDuration(item.Duration))
+		if item.Info != "" {
+			// The last progress info tells where the item failed, e.g. the rollout status
+			suffix += ": " + item.Info
+		}
 	}
 
 	counter := fmt.Sprintf("[%d/%d]", t.completed, t.total)
@@ -206,6 +233,12 @@ func (t *ConcurrentTracker) animate() {
 				var line string
 				counter := fmt.Sprintf("[%d/%d]", t.completed, t.total)
 				status := fmt.Sprintf("%d in progress...", t.inProgress)
+				if t.latestInfo >= 0 {
+					latest := t.items[t.latestInfo]
+					if latest.Status == StatusRunning && latest.Info != "" {
+						status = fmt.Sprintf("%d in progress, %s: %s", t.inProgress, latest.Name, latest.Info)
+					}
+				}
 
 				if t.useColor {
 					line = fmt.Sprintf(
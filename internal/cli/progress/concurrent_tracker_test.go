@@ -245,3 +245,29 @@ func TestConcurrentTracker_Start_TTYStartsAndStopsCleanly(t *testing.T) {
 		tracker.Stop()
 	})
 }
+
+func TestConcurrentTracker_SetItemInfo_NonTTYPrintsChanges(t *testing.T) {
+	tracker, buf := newTestTracker([]string{"service-a"}, "Installing")
+	tracker.Start()
+
+	tracker.StartItem(0)
+	tracker.SetItemInfo(0, "0/1 ready")
+	tracker.SetItemInfo(0, "0/1 ready")
+	tracker.CompleteItem(0, nil)
+	tracker.Stop()
+
+	output := buf.String()
+	assert.Equal(t, 1, strings.Count(output, "service-a: 0/1 ready"))
+}
+
+func TestConcurrentTracker_CompleteItem_FailureShowsInfo(t *testing.T) {
+	tracker, buf := newTestTracker([]string{"service-a"}, "Installing")
+	tracker.Start()
+
+	tracker.StartItem(0)
+	tracker.SetItemInfo(0, "chart dependencies could not be built")
+	tracker.CompleteItem(0, errors.New("helm dependency build failed"))
+	tracker.Stop()
+
+	assert.Contains(t, buf.String(), "FAILED: chart dependencies could not be built")
+}
@@ -138,6 +138,31 @@ func (t *Tracker) StartItem(index int) {
 	}
 }
 
+// ItemInfo returns the additional info shown for an item
+func (t *Tracker) ItemInfo(index int) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.items[index].Info
+}
+
+// SetItemInfo replaces the additional info shown for an item, e.g. to report progress of a running item
+func (t *Tracker) SetItemInfo(index int, info string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.items[index].Info == info {
+		return
+	}
+	t.items[index].Info = info
+
+	if !t.isTTY && t.items[index].Status == StatusRunning && info != "" {
+		// Non-TTY mode: print timestamped progress message
+		ts := time.Now().Format("15:04:05")
+		fmt.Printf("[%s]   %s: %s\n", ts, t.items[index].Name, info)
+	}
+}
+
 // CompleteItem marks an item as completed (success or failure)
 func (t *Tracker) CompleteItem(index int, err error) {
 	t.mu.Lock()
@@ -0,0 +1,63 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// StatusLine shows a single line of status that is rewritten in place in TTY mode. Other output
+// must only be written after Clear; the next Set redraws the line below it. In non-TTY mode every
+// change of the status is printed on its own timestamped line.
+type StatusLine struct {
+	mu      sync.Mutex
+	isTTY   bool
+	caps    terminalCapabilities
+	writer  io.Writer
+	text    string
+	visible bool
+}
+
+// NewStatusLine creates a new status line writing to stdout
+func NewStatusLine() *StatusLine {
+	return &StatusLine{
+		isTTY:  term.IsTerminal(int(os.Stdout.Fd())),
+		caps:   detectCapabilities(),
+		writer: os.Stdout,
+	}
+}
+
+// NewStatusLineWithWriter creates a status line with an injectable writer and explicit terminal
+// settings, bypassing auto-detection. Intended for testing.
+func NewStatusLineWithWriter(writer io.Writer, isTTY bool, caps terminalCapabilities) *StatusLine {
+	return &StatusLine{isTTY: isTTY, caps: caps, writer: writer}
+}
+
+// Set shows the status
+func (s *StatusLine) Set(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isTTY {
+		fmt.Fprint(s.writer, clearLine(s.caps)+truncateToWidth(text, s.caps.terminalWidth-1))
+		s.visible = true
+	} else if text != s.text {
+		fmt.Fprintf(s.writer, "[%s] %s\n", time.Now().Format("15:04:05"), text)
+	}
+	s.text = text
+}
+
+// Clear removes the status from the terminal, so other output can be written
+func (s *StatusLine) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isTTY && s.visible {
+		fmt.Fprint(s.writer, clearLine(s.caps))
+		s.visible = false
+	}
+}
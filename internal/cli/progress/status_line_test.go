@@ -0,0 +1,36 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusLine_TTY_RewritesLineInPlace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sut := NewStatusLineWithWriter(buf, true, terminalCapabilities{supportsANSI: true, terminalWidth: 20})
+
+	sut.Set("Watching 2 services")
+	sut.Set("Rebuilding api in a moment")
+	sut.Clear()
+	sut.Clear()
+
+	assert.Equal(t, "\033[2K\rWatching 2 services\033[2K\rRebuilding api in a\033[0m\033[2K\r", buf.String())
+}
+
+func TestStatusLine_NonTTY_PrintsChanges(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sut := NewStatusLineWithWriter(buf, false, terminalCapabilities{terminalWidth: 80})
+
+	sut.Set("Watching 2 services")
+	sut.Set("Watching 2 services")
+	sut.Clear()
+	sut.Set("Rebuilding api")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[0], "] Watching 2 services"))
+	assert.True(t, strings.HasSuffix(lines[1], "] Rebuilding api"))
+}
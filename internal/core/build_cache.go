@@ -0,0 +1,259 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+)
+
+// BuildCache records a fingerprint of the inputs of each built image, so builds whose inputs
+// haven't changed can be skipped, and the reference each image was last built as. The cache is
+// stored in ~/.dx/<context>/build-cache.json.
+type BuildCache struct {
+	configRepository         ConfigRepository
+	secretsRepository        SecretsRepository
+	templater                ports.Templater
+	fileSystem               ports.FileSystem
+	scm                      ports.Scm
+	containerImageRepository ports.ContainerImageRepository
+	// Guards reads and writes of the cache file
+	mu sync.Mutex
+}
+
+// buildCacheEntry is the cached build of a single image.
+type buildCacheEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	ImageID     string `json:"imageId"`
+}
+
+type buildCacheFile struct {
+	Images map[string]buildCacheEntry `json:"images"`
+	// References maps the configured names of images to the reference they were last built as
+	References map[string]string `json:"references,omitempty"`
+}
+
+// ProvideBuildCache creates a new BuildCache.
+func ProvideBuildCache(
+	configRepository ConfigRepository,
+	secretsRepository SecretsRepository,
+	templater ports.Templater,
+	fileSystem ports.FileSystem,
+	scm ports.Scm,
+	containerImageRepository ports.ContainerImageRepository,
+) *BuildCache {
+	return &BuildCache{
+		configRepository:         configRepository,
+		secretsRepository:        secretsRepository,
+		templater:                templater,
+		fileSystem:               fileSystem,
+		scm:                      scm,
+		containerImageRepository: containerImageRepository,
+	}
+}
+
+// RenderBuildArgs renders the templated build args of the image.
+func RenderBuildArgs(
+	image domain.DockerImage,
+	templater ports.Templater,
+	templateValues map[string]interface{},
+) ([]string, error) {
+	renderedArgs := make([]string, 0, len(image.BuildArgs))
+	for i, arg := range image.BuildArgs {
+		renderedArg, err := templater.Render(arg, fmt.Sprintf("build-args.%d", i), templateValues)
+		if err != nil {
+			return nil, err
+		}
+		renderedArgs = append(renderedArgs, renderedArg)
+	}
+	return renderedArgs, nil
+}
+
+// Fingerprint returns a hash of the build inputs of the image: the checked out commit of its
+// source, its Dockerfile, its rendered build options and args and the IDs and keys of its build
+// secrets. The source must already be downloaded.
+// Images built from a local checkout must be clean, so their Dockerfile is part of the commit.
+func (b *BuildCache) Fingerprint(image domain.DockerImage) (string, error) {
+	revision, err := b.scm.Revision(image.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get revision of %s: %w", image.Name, err)
+	}
+
+	dockerfile := []byte(image.DockerfileOverride)
+	if image.DockerfileOverride == "" && image.LocalPath == "" {
+		dockerfile, err = b.fileSystem.ReadFile(filepath.Join(image.Path, image.DockerfilePath))
+		if err != nil {
+			return "", fmt.Errorf("failed to read Dockerfile of %s: %w", image.Name, err)
+		}
+	}
+
+	templateValues, err := CreateTemplatingValues(b.configRepository, b.secretsRepository)
+	if err != nil {
+		return "", err
+	}
+	buildFlags, err := RenderBuildOptions(image, b.templater, templateValues)
+	if err != nil {
+		return "", err
+	}
+	buildArgs, err := RenderBuildArgs(image, b.templater, templateValues)
+	if err != nil {
+		return "", err
+	}
+
+	// Fields are NUL-separated so different splits of the same bytes hash differently
+	hash := sha256.New()
+	for _, field := range []string{revision, image.DockerfilePath, image.BuildContextRelativePath} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	hash.Write(dockerfile)
+	hash.Write([]byte{0})
+	for _, arg := range append(buildFlags, buildArgs...) {
+		hash.Write([]byte(arg))
+		hash.Write([]byte{0})
+	}
+	// Secret values are left out, so rotating a token doesn't rebuild every image using it
+	for _, id := range slices.Sorted(maps.Keys(image.BuildSecrets)) {
+		hash.Write([]byte(id + "=" + image.BuildSecrets[id]))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// IsUpToDate reports whether the image was last built with the given fingerprint and the
+// image built then still exists locally.
+func (b *BuildCache) IsUpToDate(image domain.DockerImage, fingerprint string) (bool, error) {
+	b.mu.Lock()
+	cache, err := b.load()
+	b.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+
+	entry, ok := cache.Images[image.Name]
+	if !ok || entry.Fingerprint != fingerprint || entry.ImageID == "" {
+		return false, nil
+	}
+
+	imageID, err := b.containerImageRepository.ImageID(image.Name)
+	if err != nil {
+		return false, err
+	}
+	return imageID == entry.ImageID, nil
+}
+
+// Record stores the fingerprint of a build of the image that just completed.
+func (b *BuildCache) Record(image domain.DockerImage, fingerprint string) error {
+	imageID, err := b.containerImageRepository.ImageID(image.Name)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cache, err := b.load()
+	if err != nil {
+		return err
+	}
+	cache.Images[image.Name] = buildCacheEntry{Fingerprint: fingerprint, ImageID: imageID}
+	return b.save(cache)
+}
+
+// RecordReference stores the reference the image with the given configured name was just built as,
+// or found up to date as.
+func (b *BuildCache) RecordReference(configuredName, reference string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cache, err := b.load()
+	if err != nil {
+		return err
+	}
+	if cache.References == nil {
+		cache.References = make(map[string]string)
+	}
+	cache.References[configuredName] = reference
+	return b.save(cache)
+}
+
+// ImageReferences returns the references of the images of the context for templating as
+// .Images.<name>.ref, keyed by their name without tag. Images named after the revision of their
+// source resolve to the reference they were last built as, and are left out until they are built.
+func (b *BuildCache) ImageReferences(configContext *domain.ConfigurationContext) (map[string]interface{}, error) {
+	b.mu.Lock()
+	cache, err := b.load()
+	b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	references := make(map[string]interface{})
+	for _, service := range configContext.Services {
+		for _, image := range service.DockerImages {
+			reference := image.Name
+			if IsTemplatedImageName(image) {
+				var ok bool
+				if reference, ok = cache.References[image.Name]; !ok {
+					continue
+				}
+			}
+			references[ImageNameWithoutTag(image.Name)] = map[string]interface{}{"ref": reference}
+		}
+	}
+	return references, nil
+}
+
+// save writes the cache file. Must be called with mu held.
+func (b *BuildCache) save(cache *buildCacheFile) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal build cache: %w", err)
+	}
+	path, err := b.cachePath()
+	if err != nil {
+		return err
+	}
+	if err := b.fileSystem.WriteFile(path, data, ports.ReadWrite); err != nil {
+		return fmt.Errorf("failed to write build cache: %w", err)
+	}
+	return nil
+}
+
+// load reads the cache file. A missing or unreadable cache is treated as empty.
+func (b *BuildCache) load() (*buildCacheFile, error) {
+	cache := &buildCacheFile{Images: make(map[string]buildCacheEntry)}
+
+	path, err := b.cachePath()
+	if err != nil {
+		return nil, err
+	}
+	exists, err := b.fileSystem.FileExists(path)
+	if err != nil || !exists {
+		return cache, nil
+	}
+
+	data, err := b.fileSystem.ReadFile(path)
+	if err != nil {
+		return cache, nil
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Images == nil {
+		return &buildCacheFile{Images: make(map[string]buildCacheEntry)}, nil
+	}
+	return cache, nil
+}
+
+func (b *BuildCache) cachePath() (string, error) {
+	contextName, err := b.configRepository.LoadCurrentContextName()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join("~", ".dx", contextName, "build-cache.json"), nil
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type buildCacheTestFixture struct {
+	sut                      *BuildCache
+	fileSystem               *testutil.TestFileSystem
+	scm                      *testutil.MockScm
+	templater                *testutil.MockTemplater
+	containerImageRepository *testutil.MockContainerImageRepository
+}
+
+func createTestBuildCacheFixture(t *testing.T) buildCacheTestFixture {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentContextName").Return("test-context", nil)
+	configRepository.On("LoadCurrentConfigurationContext").Return(&domain.ConfigurationContext{Name: "test-context"}, nil)
+	secretsRepository := new(testutil.MockSecretsRepository)
+	secretsRepository.On("LoadSecrets", "test-context").Return([]*domain.Secret{}, nil)
+	fixture := buildCacheTestFixture{
+		fileSystem:               testutil.NewTestFileSystem(t),
+		scm:                      new(testutil.MockScm),
+		templater:                new(testutil.MockTemplater),
+		containerImageRepository: new(testutil.MockContainerImageRepository),
+	}
+	fixture.sut = ProvideBuildCache(
+		configRepository,
+		secretsRepository,
+		fixture.templater,
+		fixture.fileSystem,
+		fixture.scm,
+		fixture.containerImageRepository,
+	)
+	return fixture
+}
+
+func TestBuildCache_Fingerprint_ChangesWithBuildInputs(t *testing.T) {
+	fixture := createTestBuildCacheFixture(t)
+	require.NoError(t, fixture.fileSystem.WriteFile("/source/Dockerfile", []byte("FROM alpine"), 0))
+	fixture.scm.On("Revision", "/source").Return("revision-1", nil).Once()
+	fixture.scm.On("Revision", "/source").Return("revision-2", nil).Once()
+	fixture.scm.On("Revision", "/source").Return("revision-1", nil)
+	fixture.templater.On("Render", "VERSION={{ .Secrets.version }}", "build-args.0", mock.Anything).
+		Return("VERSION=1", nil).Times(3)
+	fixture.templater.On("Render", "VERSION={{ .Secrets.version }}", "build-args.0", mock.Anything).
+		Return("VERSION=2", nil)
+	image := domain.DockerImage{
+		Name:           "any-image",
+		DockerfilePath: "Dockerfile",
+		BuildArgs:      []string{"VERSION={{ .Secrets.version }}"},
+		Path:           "/source",
+	}
+
+	original, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+	newRevision, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+	require.NoError(t, fixture.fileSystem.WriteFile("/source/Dockerfile", []byte("FROM debian"), 0))
+	newDockerfile, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+	require.NoError(t, fixture.fileSystem.WriteFile("/source/Dockerfile", []byte("FROM alpine"), 0))
+	newBuildArgs, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, original, newRevision)
+	assert.NotEqual(t, original, newDockerfile)
+	assert.NotEqual(t, original, newBuildArgs)
+}
+
+func TestBuildCache_Fingerprint_UsesDockerfileOverride(t *testing.T) {
+	fixture := createTestBuildCacheFixture(t)
+	fixture.scm.On("Revision", "/source").Return("revision-1", nil)
+	image := domain.DockerImage{Name: "any-image", DockerfileOverride: "FROM alpine", Path: "/source"}
+
+	original, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+	image.DockerfileOverride = "FROM debian"
+	changed, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, original, changed)
+}
+
+func TestBuildCache_Fingerprint_UsesBuildSecretKeysButNotValues(t *testing.T) {
+	fixture := createTestBuildCacheFixture(t)
+	fixture.scm.On("Revision", "/source").Return("revision-1", nil)
+	image := domain.DockerImage{
+		Name:               "any-image",
+		DockerfileOverride: "FROM alpine",
+		BuildSecrets:       map[string]string{"npmrc": "NPM_TOKEN"},
+		Path:               "/source",
+	}
+
+	original, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+	image.BuildSecrets = map[string]string{"npmrc": "OTHER_TOKEN"}
+	changed, err := fixture.sut.Fingerprint(image)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, original, changed)
+}
+
+func TestBuildCache_ImageReferences(t *testing.T) {
+	fixture := createTestBuildCacheFixture(t)
+	configContext := &domain.ConfigurationContext{
+		Name: "test-context",
+		Services: []domain.Service{
+			{
+				Name: "api",
+				DockerImages: []domain.DockerImage{
+					{Name: "api:{{.Git.ShortSha}}"},
+					{Name: "migrations:latest"},
+				},
+			},
+			{Name: "web", DockerImages: []domain.DockerImage{{Name: "web:{{.Git.ShortSha}}"}}},
+		},
+	}
+	require.NoError(t, fixture.sut.RecordReference("api:{{.Git.ShortSha}}", "api:abc1234"))
+
+	references, err := fixture.sut.ImageReferences(configContext)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"api":        map[string]interface{}{"ref": "api:abc1234"},
+		"migrations": map[string]interface{}{"ref": "migrations:latest"},
+	}, references, "web hasn't been built yet")
+}
+
+func TestBuildCache_IsUpToDate(t *testing.T) {
+	image := domain.DockerImage{Name: "any-image"}
+
+	t.Run("not built before", func(t *testing.T) {
+		fixture := createTestBuildCacheFixture(t)
+
+		upToDate, err := fixture.sut.IsUpToDate(image, "fingerprint")
+
+		require.NoError(t, err)
+		assert.False(t, upToDate)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		fixture := createTestBuildCacheFixture(t)
+		fixture.containerImageRepository.On("ImageID", "any-image").Return("sha256:1", nil)
+		require.NoError(t, fixture.sut.Record(image, "fingerprint"))
+
+		upToDate, err := fixture.sut.IsUpToDate(image, "fingerprint")
+
+		require.NoError(t, err)
+		assert.True(t, upToDate)
+		exists, _ := fixture.fileSystem.FileExists("~/.dx/test-context/build-cache.json")
+		assert.True(t, exists)
+	})
+
+	t.Run("fingerprint changed", func(t *testing.T) {
+		fixture := createTestBuildCacheFixture(t)
+		fixture.containerImageRepository.On("ImageID", "any-image").Return("sha256:1", nil)
+		require.NoError(t, fixture.sut.Record(image, "fingerprint"))
+
+		upToDate, err := fixture.sut.IsUpToDate(image, "other-fingerprint")
+
+		require.NoError(t, err)
+		assert.False(t, upToDate)
+	})
+
+	t.Run("image replaced", func(t *testing.T) {
+		fixture := createTestBuildCacheFixture(t)
+		fixture.containerImageRepository.On("ImageID", "any-image").Return("sha256:1", nil).Once()
+		fixture.containerImageRepository.On("ImageID", "any-image").Return("sha256:2", nil)
+		require.NoError(t, fixture.sut.Record(image, "fingerprint"))
+
+		upToDate, err := fixture.sut.IsUpToDate(image, "fingerprint")
+
+		require.NoError(t, err)
+		assert.False(t, upToDate)
+	})
+
+	t.Run("image removed", func(t *testing.T) {
+		fixture := createTestBuildCacheFixture(t)
+		fixture.containerImageRepository.On("ImageID", "any-image").Return("sha256:1", nil).Once()
+		fixture.containerImageRepository.On("ImageID", "any-image").Return("", nil)
+		require.NoError(t, fixture.sut.Record(image, "fingerprint"))
+
+		upToDate, err := fixture.sut.IsUpToDate(image, "fingerprint")
+
+		require.NoError(t, err)
+		assert.False(t, upToDate)
+	})
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+)
+
+// buildOption is a build flag with the templated value it is passed.
+type buildOption struct {
+	flag  string
+	name  string // Name of the template, used in error messages
+	value string
+}
+
+// buildOptions returns the BuildKit options of the image in the order they are passed to the builder.
+func buildOptions(image domain.DockerImage) []buildOption {
+	var options []buildOption
+	if image.Target != "" {
+		options = append(options, buildOption{"--target", "build-options.target", image.Target})
+	}
+	if image.Platform != "" {
+		options = append(options, buildOption{"--platform", "build-options.platform", image.Platform})
+	}
+	for i, cache := range image.CacheFrom {
+		options = append(options, buildOption{"--cache-from", fmt.Sprintf("build-options.cache-from.%d", i), cache})
+	}
+	for i, cache := range image.CacheTo {
+		options = append(options, buildOption{"--cache-to", fmt.Sprintf("build-options.cache-to.%d", i), cache})
+	}
+	for _, key := range slices.Sorted(maps.Keys(image.Labels)) {
+		options = append(options, buildOption{
+			"--label",
+			"build-options.labels." + key,
+			key + "=" + image.Labels[key],
+		})
+	}
+	for i, ssh := range image.SSH {
+		options = append(options, buildOption{"--ssh", fmt.Sprintf("build-options.ssh.%d", i), ssh})
+	}
+	if image.Network != "" {
+		options = append(options, buildOption{"--network", "build-options.network", image.Network})
+	}
+	return options
+}
+
+// RenderBuildOptions renders the templated BuildKit options of the image into build flags.
+func RenderBuildOptions(
+	image domain.DockerImage,
+	templater ports.Templater,
+	templateValues map[string]interface{},
+) ([]string, error) {
+	var flags []string
+	for _, option := range buildOptions(image) {
+		value, err := templater.Render(option.value, option.name, templateValues)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, option.flag, value)
+	}
+	return flags, nil
+}
+
+// BuildSecrets returns the --secret flags of the image and the environment variables holding the
+// values of its build secrets. Values are passed through the environment of the builder, so they
+// don't show up in its arguments or in the image history.
+func BuildSecrets(
+	image domain.DockerImage,
+	secretsRepository SecretsRepository,
+	contextName string,
+) (flags []string, env []string, err error) {
+	if len(image.BuildSecrets) == 0 {
+		return nil, nil, nil
+	}
+
+	secrets, err := secretsRepository.LoadSecrets(contextName)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		values[secret.Key] = secret.Value
+	}
+
+	for i, id := range slices.Sorted(maps.Keys(image.BuildSecrets)) {
+		key := image.BuildSecrets[id]
+		value, ok := values[key]
+		if !ok {
+			return nil, nil, fmt.Errorf(
+				"build secret '%s' of image %s uses secret '%s', which is not configured (run 'dx secret configure')",
+				id,
+				image.Name,
+				key,
+			)
+		}
+		envName := fmt.Sprintf("DX_BUILD_SECRET_%d", i)
+		flags = append(flags, "--secret", fmt.Sprintf("id=%s,env=%s", id, envName))
+		env = append(env, envName+"="+value)
+	}
+	return flags, env, nil
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBuildOptions_MapsOptionsToFlags(t *testing.T) {
+	image := domain.DockerImage{
+		Name:      "api",
+		Target:    "dev",
+		Platform:  "linux/amd64,linux/arm64",
+		CacheFrom: []string{"type=registry,ref=ghcr.io/acme/api:cache", "api:latest"},
+		CacheTo:   []string{"type=inline"},
+		Labels:    map[string]string{"team": "payments", "app": "api"},
+		SSH:       []string{"default", "github=/home/dev/.ssh/id_ed25519"},
+		Network:   "host",
+	}
+	templater := new(testutil.MockTemplater)
+	for _, value := range []string{
+		"dev", "linux/amd64,linux/arm64", "type=registry,ref=ghcr.io/acme/api:cache", "api:latest", "type=inline",
+		"app=api", "team=payments", "default", "github=/home/dev/.ssh/id_ed25519", "host",
+	} {
+		templater.On("Render", value, mock.Anything, mock.Anything).Return(value, nil)
+	}
+
+	flags, err := RenderBuildOptions(image, templater, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--target", "dev",
+		"--platform", "linux/amd64,linux/arm64",
+		"--cache-from", "type=registry,ref=ghcr.io/acme/api:cache",
+		"--cache-from", "api:latest",
+		"--cache-to", "type=inline",
+		"--label", "app=api",
+		"--label", "team=payments",
+		"--ssh", "default",
+		"--ssh", "github=/home/dev/.ssh/id_ed25519",
+		"--network", "host",
+	}, flags)
+}
+
+func TestRenderBuildOptions_RendersTemplates(t *testing.T) {
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", "type=registry,ref={{.Secrets.registry}}/api", "build-options.cache-from.0", mock.Anything).
+		Return("type=registry,ref=ghcr.io/acme/api", nil)
+	image := domain.DockerImage{Name: "api", CacheFrom: []string{"type=registry,ref={{.Secrets.registry}}/api"}}
+
+	flags, err := RenderBuildOptions(image, templater, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--cache-from", "type=registry,ref=ghcr.io/acme/api"}, flags)
+}
+
+func TestRenderBuildOptions_ReturnsRenderError(t *testing.T) {
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", "{{.Missing}}", "build-options.target", mock.Anything).
+		Return("", errors.New("missing key"))
+
+	_, err := RenderBuildOptions(domain.DockerImage{Name: "api", Target: "{{.Missing}}"}, templater, nil)
+
+	assert.EqualError(t, err, "missing key")
+}
+
+func TestRenderBuildOptions_NoOptions(t *testing.T) {
+	flags, err := RenderBuildOptions(domain.DockerImage{Name: "api"}, new(testutil.MockTemplater), nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+}
+
+func TestBuildSecrets_PassesValuesThroughEnvironment(t *testing.T) {
+	secretsRepository := new(testutil.MockSecretsRepository)
+	secretsRepository.On("LoadSecrets", "test-context").Return([]*domain.Secret{
+		{Key: "NPM_TOKEN", Value: "npm-secret"},
+		{Key: "GITHUB_TOKEN", Value: "github-secret"},
+	}, nil)
+	image := domain.DockerImage{
+		Name:         "api",
+		BuildSecrets: map[string]string{"npm": "NPM_TOKEN", "github": "GITHUB_TOKEN"},
+	}
+
+	flags, env, err := BuildSecrets(image, secretsRepository, "test-context")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--secret", "id=github,env=DX_BUILD_SECRET_0",
+		"--secret", "id=npm,env=DX_BUILD_SECRET_1",
+	}, flags)
+	assert.Equal(t, []string{"DX_BUILD_SECRET_0=github-secret", "DX_BUILD_SECRET_1=npm-secret"}, env)
+}
+
+func TestBuildSecrets_MissingSecret(t *testing.T) {
+	secretsRepository := new(testutil.MockSecretsRepository)
+	secretsRepository.On("LoadSecrets", "test-context").Return([]*domain.Secret{}, nil)
+	image := domain.DockerImage{Name: "api", BuildSecrets: map[string]string{"npm": "NPM_TOKEN"}}
+
+	_, _, err := BuildSecrets(image, secretsRepository, "test-context")
+
+	assert.EqualError(
+		t,
+		err,
+		"build secret 'npm' of image api uses secret 'NPM_TOKEN', which is not configured (run 'dx secret configure')",
+	)
+}
+
+func TestBuildSecrets_NoSecretsDoesNotLoadSecrets(t *testing.T) {
+	secretsRepository := new(testutil.MockSecretsRepository)
+
+	flags, env, err := BuildSecrets(domain.DockerImage{Name: "api"}, secretsRepository, "test-context")
+
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+	assert.Empty(t, env)
+	secretsRepository.AssertNotCalled(t, "LoadSecrets", mock.Anything)
+}
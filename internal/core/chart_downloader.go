@@ -0,0 +1,301 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChartDownloader downloads the helm charts of services, either by cloning their git repository or by
+// pulling them from a helm repository or an OCI registry. Pulled charts are cached in their HelmPath;
+// a chart without a pinned version is pulled again once per run to pick up new versions.
+// The dependencies of charts cloned from git are built with helm, and the lock file they were built
+// from is recorded in ~/.dx/chart-dependencies.json, together with the git revisions of local file://
+// dependencies, so they are only rebuilt when either changes.
+// ChartDownloader is safe for concurrent use.
+type ChartDownloader struct {
+	scm        ports.Scm
+	helmClient ports.HelmClient
+	fileSystem ports.FileSystem
+	mu         sync.Mutex
+	// One lock per chart path, held while the chart is pulled or its dependencies are built
+	pathLocks map[string]*sync.Mutex
+	// Charts pulled during this run
+	pulled map[string]bool
+	// Guards reads and writes of the dependency cache file
+	cacheMu sync.Mutex
+}
+
+// ChartDependencyError is returned when the dependencies of a chart can't be built.
+type ChartDependencyError struct {
+	Service string
+	Err     error
+}
+
+func (e *ChartDependencyError) Error() string {
+	message := fmt.Sprintf("failed to build the chart dependencies of service %s: %v", e.Service, e.Err)
+	if strings.Contains(e.Err.Error(), "no repository definition") {
+		message += " (add the missing repositories with 'helm repo add')"
+	}
+	return message
+}
+
+func (e *ChartDependencyError) Unwrap() error {
+	return e.Err
+}
+
+// ProvideChartDownloader creates a new ChartDownloader.
+func ProvideChartDownloader(scm ports.Scm, helmClient ports.HelmClient, fileSystem ports.FileSystem) *ChartDownloader {
+	return &ChartDownloader{
+		scm:        scm,
+		helmClient: helmClient,
+		fileSystem: fileSystem,
+		pathLocks:  make(map[string]*sync.Mutex),
+		pulled:     make(map[string]bool),
+	}
+}
+
+// Download makes the chart of the service available in its HelmPath, with its dependencies built.
+// onStatus is called when the dependencies are built. Returns a *ChartDependencyError if they can't be.
+func (d *ChartDownloader) Download(service *domain.Service, onStatus func(status string)) error {
+	if service.UsesChartRepository() {
+		// Packaged charts contain their dependencies
+		return d.pull(service, onStatus)
+	}
+
+	if err := d.scm.Download(service.HelmRepoPath, service.HelmBranch, service.HelmPath); err != nil {
+		return err
+	}
+	if err := d.buildDependencies(filepath.Join(service.HelmPath, service.HelmChartRelativePath), onStatus); err != nil {
+		return &ChartDependencyError{Service: service.Name, Err: err}
+	}
+	return nil
+}
+
+// pull pulls the chart of the service from a helm repository or an OCI registry, unless it is cached.
+// The chart is pulled into a staging directory and only replaces the cached chart once the pull
+// succeeded. If the pull of a chart without a pinned version fails, the cached chart is used.
+func (d *ChartDownloader) pull(service *domain.Service, onStatus func(status string)) error {
+	unlock := d.lockPath(service.HelmPath)
+	defer unlock()
+
+	if d.isPulled(service.HelmPath) {
+		return nil
+	}
+	cached, err := d.fileSystem.FileExists(filepath.Join(service.HelmPath, "Chart.yaml"))
+	if err != nil {
+		return err
+	}
+	if cached && service.HelmChartVersion != "" {
+		d.setPulled(service.HelmPath)
+		return nil
+	}
+
+	// helm unpacks the chart into a directory named after it, which must not exist yet
+	staging := filepath.Join(filepath.Dir(service.HelmPath), ".pull")
+	if err := d.fileSystem.RemoveAll(staging); err != nil {
+		return fmt.Errorf("failed to prepare the chart directory of service %s: %w", service.Name, err)
+	}
+	defer func() { _ = d.fileSystem.RemoveAll(staging) }()
+	if err := d.fileSystem.MkdirAll(staging, ports.ReadWriteExecute); err != nil {
+		return fmt.Errorf("failed to create chart directory of service %s: %w", service.Name, err)
+	}
+	err = d.helmClient.Pull(service.HelmChart, service.HelmChartRepoURL, service.HelmChartVersion, staging)
+	if err != nil && cached {
+		onStatus("pull failed, using cached chart")
+		d.setPulled(service.HelmPath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := d.fileSystem.RemoveAll(service.HelmPath); err != nil {
+		return fmt.Errorf("failed to remove cached chart of service %s: %w", service.Name, err)
+	}
+	if err := d.fileSystem.Rename(filepath.Join(staging, filepath.Base(service.HelmPath)), service.HelmPath); err != nil {
+		return fmt.Errorf("failed to move pulled chart of service %s: %w", service.Name, err)
+	}
+	d.setPulled(service.HelmPath)
+	return nil
+}
+
+// buildDependencies builds the dependencies declared in the Chart.yaml of a chart, unless they were
+// built from the same Chart.lock before and are still present.
+func (d *ChartDownloader) buildDependencies(chartPath string, onStatus func(status string)) error {
+	// A missing Chart.yaml is reported by helm when the chart is templated
+	chartFilePath := filepath.Join(chartPath, "Chart.yaml")
+	exists, err := d.fileSystem.FileExists(chartFilePath)
+	if err != nil || !exists {
+		return err
+	}
+	chartFile, err := d.fileSystem.ReadFile(chartFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read Chart.yaml: %w", err)
+	}
+	var chart struct {
+		Dependencies []struct {
+			Repository string `yaml:"repository"`
+		} `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal(chartFile, &chart); err != nil {
+		return fmt.Errorf("failed to parse Chart.yaml: %w", err)
+	}
+	if len(chart.Dependencies) == 0 {
+		return nil
+	}
+
+	unlock := d.lockPath(chartPath)
+	defer unlock()
+
+	// Without a lock file helm resolves the dependencies from Chart.yaml
+	lockFile := chartFile
+	lockPath := filepath.Join(chartPath, "Chart.lock")
+	hasLock, err := d.fileSystem.FileExists(lockPath)
+	if err != nil {
+		return err
+	}
+	if hasLock {
+		if lockFile, err = d.fileSystem.ReadFile(lockPath); err != nil {
+			return fmt.Errorf("failed to read Chart.lock: %w", err)
+		}
+	}
+	// Local dependencies are packaged from their directory, which the lock file doesn't describe
+	hash := sha256.New()
+	hash.Write(lockFile)
+	cacheable := true
+	for _, dependency := range chart.Dependencies {
+		localPath, ok := strings.CutPrefix(dependency.Repository, "file://")
+		if !ok {
+			continue
+		}
+		if !filepath.IsAbs(localPath) {
+			localPath = filepath.Join(chartPath, localPath)
+		}
+		revision, ok := d.localDependencyRevision(localPath)
+		if !ok {
+			cacheable = false
+			break
+		}
+		hash.Write([]byte{0})
+		hash.Write([]byte(dependency.Repository + "=" + revision))
+	}
+	fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+	cache, err := d.loadDependencyCache()
+	if err != nil {
+		return err
+	}
+	built, err := d.fileSystem.FileExists(filepath.Join(chartPath, "charts"))
+	if err != nil {
+		return err
+	}
+	if cacheable && built && cache[chartPath] == fingerprint {
+		return nil
+	}
+
+	onStatus("building chart dependencies")
+	if err := d.helmClient.DependencyBuild(chartPath); err != nil {
+		return err
+	}
+	onStatus("")
+	if !cacheable {
+		return nil
+	}
+	return d.saveDependencyFingerprint(chartPath, fingerprint)
+}
+
+// localDependencyRevision returns the git revision checked out where a local chart dependency is.
+// Returns false if the revision doesn't describe the dependency, because it isn't in a git repository
+// or has uncommitted changes.
+func (d *ChartDownloader) localDependencyRevision(path string) (string, bool) {
+	revision, err := d.scm.Revision(path)
+	if err != nil || revision == "" {
+		return "", false
+	}
+	dirty, err := d.scm.HasUncommittedChanges(path)
+	if err != nil || dirty {
+		return "", false
+	}
+	return revision, true
+}
+
+// dependencyCachePath is the file recording the lock files chart dependencies were built from.
+var dependencyCachePath = filepath.Join("~", ".dx", "chart-dependencies.json")
+
+// loadDependencyCache returns the fingerprints of the lock files by chart path.
+func (d *ChartDownloader) loadDependencyCache() (map[string]string, error) {
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+	return d.readDependencyCache()
+}
+
+func (d *ChartDownloader) readDependencyCache() (map[string]string, error) {
+	exists, err := d.fileSystem.FileExists(dependencyCachePath)
+	if err != nil || !exists {
+		return map[string]string{}, err
+	}
+	content, err := d.fileSystem.ReadFile(dependencyCachePath)
+	if err != nil {
+		return nil, err
+	}
+	cache := map[string]string{}
+	if err := json.Unmarshal(content, &cache); err != nil {
+		// A corrupt cache only causes dependencies to be rebuilt
+		return map[string]string{}, nil
+	}
+	return cache, nil
+}
+
+// saveDependencyFingerprint records the fingerprint of the lock file the dependencies of a chart were
+// built from.
+func (d *ChartDownloader) saveDependencyFingerprint(chartPath, fingerprint string) error {
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+
+	cache, err := d.readDependencyCache()
+	if err != nil {
+		return err
+	}
+	cache[chartPath] = fingerprint
+	content, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return d.fileSystem.WriteFile(dependencyCachePath, content, ports.ReadWrite)
+}
+
+// lockPath locks the chart path and returns the function that unlocks it.
+func (d *ChartDownloader) lockPath(path string) func() {
+	d.mu.Lock()
+	pathLock, ok := d.pathLocks[path]
+	if !ok {
+		pathLock = &sync.Mutex{}
+		d.pathLocks[path] = pathLock
+	}
+	d.mu.Unlock()
+
+	pathLock.Lock()
+	return pathLock.Unlock
+}
+
+func (d *ChartDownloader) isPulled(path string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pulled[path]
+}
+
+func (d *ChartDownloader) setPulled(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pulled[path] = true
+}
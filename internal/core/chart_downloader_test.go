@@ -0,0 +1,295 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartDownloader_Download_ClonesGitCharts(t *testing.T) {
+	scm := new(testutil.MockScm)
+	scm.On("Download", "git@example.com:charts.git", "main", "/home/dev/.dx/dev/charts/abc").Return(nil)
+	helmClient := new(testutil.MockHelmClient)
+	sut := ProvideChartDownloader(scm, helmClient, testutil.NewTestFileSystem(t))
+
+	err := sut.Download(&domain.Service{
+		Name:         "api",
+		HelmRepoPath: "git@example.com:charts.git",
+		HelmBranch:   "main",
+		HelmPath:     "/home/dev/.dx/dev/charts/abc",
+	}, func(string) {})
+
+	require.NoError(t, err)
+	scm.AssertExpectations(t)
+	helmClient.AssertNotCalled(t, "Pull")
+}
+
+// pullChart makes a mocked helm pull unpack a chart with the given Chart.yaml into the destination.
+func pullChart(fileSystem ports.FileSystem, chartName, chartFile string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		destination := args.String(3)
+		_ = fileSystem.WriteFile(filepath.Join(destination, chartName, "Chart.yaml"), []byte(chartFile), ports.ReadWrite)
+	}
+}
+
+func TestChartDownloader_Download_PullsChartOncePerRun(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("Pull", "oci://ghcr.io/acme/charts/kafka", "", "", "/home/dev/.dx/dev/charts/abc/.pull").
+		Run(pullChart(fileSystem, "kafka", "name: kafka\n")).Return(nil).Once()
+	sut := ProvideChartDownloader(new(testutil.MockScm), helmClient, fileSystem)
+	service := &domain.Service{
+		Name:      "kafka",
+		HelmChart: "oci://ghcr.io/acme/charts/kafka",
+		HelmPath:  "/home/dev/.dx/dev/charts/abc/kafka",
+	}
+
+	require.NoError(t, sut.Download(service, func(string) {}))
+	require.NoError(t, sut.Download(service, func(string) {}))
+
+	helmClient.AssertExpectations(t)
+	chartFile, err := fileSystem.ReadFile("/home/dev/.dx/dev/charts/abc/kafka/Chart.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "name: kafka\n", string(chartFile))
+	staged, err := fileSystem.FileExists("/home/dev/.dx/dev/charts/abc/.pull")
+	require.NoError(t, err)
+	assert.False(t, staged)
+}
+
+func TestChartDownloader_Download_ReplacesCachedChart(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/kafka/Chart.yaml", []byte("version: 1.0.0\n"), ports.ReadWrite))
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/kafka/templates/old.yaml", []byte("kind: ConfigMap\n"), ports.ReadWrite))
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("Pull", "oci://ghcr.io/acme/charts/kafka", "", "", "/charts/abc/.pull").
+		Run(pullChart(fileSystem, "kafka", "version: 2.0.0\n")).Return(nil)
+	sut := ProvideChartDownloader(new(testutil.MockScm), helmClient, fileSystem)
+
+	err := sut.Download(&domain.Service{
+		Name:      "kafka",
+		HelmChart: "oci://ghcr.io/acme/charts/kafka",
+		HelmPath:  "/charts/abc/kafka",
+	}, func(string) {})
+
+	require.NoError(t, err)
+	chartFile, err := fileSystem.ReadFile("/charts/abc/kafka/Chart.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "version: 2.0.0\n", string(chartFile))
+	oldTemplate, err := fileSystem.FileExists("/charts/abc/kafka/templates/old.yaml")
+	require.NoError(t, err)
+	assert.False(t, oldTemplate)
+}
+
+func TestChartDownloader_Download_KeepsCachedChartWhenPullFails(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/kafka/Chart.yaml", []byte("version: 1.0.0\n"), ports.ReadWrite))
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("Pull", "oci://ghcr.io/acme/charts/kafka", "", "", "/charts/abc/.pull").
+		Return(errors.New("failed to pull helm chart kafka"))
+	sut := ProvideChartDownloader(new(testutil.MockScm), helmClient, fileSystem)
+	var statuses []string
+
+	err := sut.Download(&domain.Service{
+		Name:      "kafka",
+		HelmChart: "oci://ghcr.io/acme/charts/kafka",
+		HelmPath:  "/charts/abc/kafka",
+	}, func(status string) { statuses = append(statuses, status) })
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pull failed, using cached chart"}, statuses)
+	chartFile, err := fileSystem.ReadFile("/charts/abc/kafka/Chart.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1.0.0\n", string(chartFile))
+}
+
+func TestChartDownloader_Download_UsesCachedVersion(t *testing.T) {
+	helmClient := new(testutil.MockHelmClient)
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("FileExists", "/home/dev/.dx/dev/charts/abc/postgresql/Chart.yaml").Return(true, nil)
+	sut := ProvideChartDownloader(new(testutil.MockScm), helmClient, fileSystem)
+
+	err := sut.Download(&domain.Service{
+		Name:             "postgres",
+		HelmChart:        "postgresql",
+		HelmChartRepoURL: "https://charts.bitnami.com/bitnami",
+		HelmChartVersion: "15.5.0",
+		HelmPath:         "/home/dev/.dx/dev/charts/abc/postgresql",
+	}, func(string) {})
+
+	require.NoError(t, err)
+	helmClient.AssertNotCalled(t, "Pull")
+}
+
+func TestChartDownloader_Download_PullsMissingVersion(t *testing.T) {
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("Pull", "postgresql", "https://charts.bitnami.com/bitnami", "15.5.0", "/home/dev/.dx/dev/charts/abc/.pull").
+		Return(errors.New("failed to pull helm chart postgresql"))
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("FileExists", "/home/dev/.dx/dev/charts/abc/postgresql/Chart.yaml").Return(false, nil)
+	fileSystem.On("RemoveAll", "/home/dev/.dx/dev/charts/abc/.pull").Return(nil)
+	fileSystem.On("MkdirAll", "/home/dev/.dx/dev/charts/abc/.pull", ports.AccessMode(ports.ReadWriteExecute)).Return(nil)
+	sut := ProvideChartDownloader(new(testutil.MockScm), helmClient, fileSystem)
+
+	err := sut.Download(&domain.Service{
+		Name:             "postgres",
+		HelmChart:        "postgresql",
+		HelmChartRepoURL: "https://charts.bitnami.com/bitnami",
+		HelmChartVersion: "15.5.0",
+		HelmPath:         "/home/dev/.dx/dev/charts/abc/postgresql",
+	}, func(string) {})
+
+	assert.ErrorContains(t, err, "failed to pull helm chart postgresql")
+	helmClient.AssertExpectations(t)
+}
+
+const chartWithDependencies = `apiVersion: v2
+name: api
+version: 1.0.0
+dependencies:
+  - name: redis
+    version: 19.0.0
+    repository: https://charts.bitnami.com/bitnami
+`
+
+func createChartWithDependencies(t *testing.T, fileSystem ports.FileSystem, lock string) *domain.Service {
+	t.Helper()
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/charts/api/Chart.yaml", []byte(chartWithDependencies), ports.ReadWrite))
+	if lock != "" {
+		require.NoError(t, fileSystem.WriteFile("/charts/abc/charts/api/Chart.lock", []byte(lock), ports.ReadWrite))
+	}
+	return &domain.Service{
+		Name:                  "api",
+		HelmRepoPath:          "git@example.com:charts.git",
+		HelmBranch:            "main",
+		HelmPath:              "/charts/abc",
+		HelmChartRelativePath: "charts/api",
+	}
+}
+
+func TestChartDownloader_Download_BuildsDependenciesOnce(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	service := createChartWithDependencies(t, fileSystem, "digest: sha256:1\n")
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("DependencyBuild", "/charts/abc/charts/api").Return(nil).Run(func(mock.Arguments) {
+		require.NoError(t, fileSystem.MkdirAll("/charts/abc/charts/api/charts", ports.ReadWriteExecute))
+	}).Once()
+	var statuses []string
+	sut := ProvideChartDownloader(scm, helmClient, fileSystem)
+
+	require.NoError(t, sut.Download(service, func(status string) { statuses = append(statuses, status) }))
+	require.NoError(t, ProvideChartDownloader(scm, helmClient, fileSystem).Download(service, func(string) {}))
+
+	helmClient.AssertExpectations(t)
+	assert.Equal(t, []string{"building chart dependencies", ""}, statuses)
+}
+
+func TestChartDownloader_Download_RebuildsDependenciesWhenLockChanges(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	service := createChartWithDependencies(t, fileSystem, "digest: sha256:1\n")
+	require.NoError(t, fileSystem.MkdirAll("/charts/abc/charts/api/charts", ports.ReadWriteExecute))
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("DependencyBuild", "/charts/abc/charts/api").Return(nil).Twice()
+	sut := ProvideChartDownloader(scm, helmClient, fileSystem)
+
+	require.NoError(t, sut.Download(service, func(string) {}))
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/charts/api/Chart.lock", []byte("digest: sha256:2\n"), ports.ReadWrite))
+	require.NoError(t, sut.Download(service, func(string) {}))
+
+	helmClient.AssertExpectations(t)
+}
+
+const chartWithLocalDependency = `apiVersion: v2
+name: api
+version: 1.0.0
+dependencies:
+  - name: common
+    version: 1.0.0
+    repository: file://../common
+`
+
+func TestChartDownloader_Download_RebuildsDependenciesWhenLocalDependencyChanges(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	service := createChartWithDependencies(t, fileSystem, "")
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/charts/api/Chart.yaml", []byte(chartWithLocalDependency), ports.ReadWrite))
+	require.NoError(t, fileSystem.MkdirAll("/charts/abc/charts/api/charts", ports.ReadWriteExecute))
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	scm.On("Revision", "/charts/abc/charts/common").Return("abc123", nil).Twice()
+	scm.On("Revision", "/charts/abc/charts/common").Return("def456", nil).Once()
+	scm.On("HasUncommittedChanges", "/charts/abc/charts/common").Return(false, nil)
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("DependencyBuild", "/charts/abc/charts/api").Return(nil).Twice()
+	sut := ProvideChartDownloader(scm, helmClient, fileSystem)
+
+	// Built, unchanged, then rebuilt after the local dependency moved to another revision
+	require.NoError(t, sut.Download(service, func(string) {}))
+	require.NoError(t, sut.Download(service, func(string) {}))
+	require.NoError(t, sut.Download(service, func(string) {}))
+
+	helmClient.AssertExpectations(t)
+	scm.AssertExpectations(t)
+}
+
+func TestChartDownloader_Download_AlwaysBuildsUncommittedLocalDependency(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	service := createChartWithDependencies(t, fileSystem, "")
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/charts/api/Chart.yaml", []byte(chartWithLocalDependency), ports.ReadWrite))
+	require.NoError(t, fileSystem.MkdirAll("/charts/abc/charts/api/charts", ports.ReadWriteExecute))
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	scm.On("Revision", "/charts/abc/charts/common").Return("abc123", nil)
+	scm.On("HasUncommittedChanges", "/charts/abc/charts/common").Return(true, nil)
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("DependencyBuild", "/charts/abc/charts/api").Return(nil).Twice()
+	sut := ProvideChartDownloader(scm, helmClient, fileSystem)
+
+	require.NoError(t, sut.Download(service, func(string) {}))
+	require.NoError(t, sut.Download(service, func(string) {}))
+
+	helmClient.AssertExpectations(t)
+}
+
+func TestChartDownloader_Download_ReturnsDependencyError(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	service := createChartWithDependencies(t, fileSystem, "")
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	helmClient := new(testutil.MockHelmClient)
+	helmClient.On("DependencyBuild", "/charts/abc/charts/api").
+		Return(errors.New("helm dependency build failed: exit status 1, output: Error: no repository definition for https://charts.bitnami.com/bitnami"))
+	sut := ProvideChartDownloader(scm, helmClient, fileSystem)
+
+	err := sut.Download(service, func(string) {})
+
+	var dependencyErr *ChartDependencyError
+	require.ErrorAs(t, err, &dependencyErr)
+	assert.Equal(t, "api", dependencyErr.Service)
+	assert.ErrorContains(t, err, "failed to build the chart dependencies of service api")
+	assert.ErrorContains(t, err, "(add the missing repositories with 'helm repo add')")
+}
+
+func TestChartDownloader_Download_SkipsChartsWithoutDependencies(t *testing.T) {
+	fileSystem := testutil.NewTestFileSystem(t)
+	require.NoError(t, fileSystem.WriteFile("/charts/abc/api/Chart.yaml", []byte("apiVersion: v2\nname: api\n"), ports.ReadWrite))
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	helmClient := new(testutil.MockHelmClient)
+	sut := ProvideChartDownloader(scm, helmClient, fileSystem)
+
+	err := sut.Download(&domain.Service{Name: "api", HelmPath: "/charts/abc", HelmChartRelativePath: "api"}, func(string) {})
+
+	require.NoError(t, err)
+	helmClient.AssertNotCalled(t, "DependencyBuild", mock.Anything)
+}
@@ -1,9 +1,11 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"dx/internal/ports"
@@ -16,8 +18,25 @@ type WrapperChartConfig struct {
 	PatchedManifests  []byte
 	OriginalChartName string
 	OriginalChartPath string
+	// ManifestsChecksum identifies the patched manifests, see ManifestsChecksumAnnotation.
+	ManifestsChecksum string
+	// Scratch writes the chart to a scratch directory that is overwritten each time, instead of
+	// keeping it as a new generation. Used for charts that are only templated or never rolled back to.
+	Scratch bool
 }
 
+// MaxWrapperChartGenerations is the number of generated wrapper charts kept per release, so a
+// previous deployment can be reinstalled exactly. Older generations are removed.
+const MaxWrapperChartGenerations = 10
+
+// wrapperChartGenerationsFile lists the kept generations of the wrapper charts of a release, oldest first.
+const wrapperChartGenerationsFile = "generations.json"
+
+// ManifestsChecksumAnnotation is the Chart.yaml annotation of a wrapper chart holding the checksum of
+// its patched manifests. It is stored with each release revision, so an upgrade can be skipped when
+// the manifests haven't changed.
+const ManifestsChecksumAnnotation = "dx.manifests-checksum"
+
 // ChartWrapper generates wrapper Helm charts containing patched manifests.
 type ChartWrapper struct {
 	fileSystem ports.FileSystem
@@ -30,23 +49,22 @@ func ProvideChartWrapper(fileSystem ports.FileSystem) *ChartWrapper {
 	}
 }
 
-// Generate creates a wrapper chart containing the patched manifests.
+// Generate creates a wrapper chart containing the patched manifests. Unless config.Scratch is set,
+// the chart is written as the next generation in ~/.dx/<context>/wrapper-charts/<release>/<generation>,
+// which is only kept once Commit is called after it was installed. A generation that isn't committed
+// is overwritten by the next Generate.
 // Returns the absolute path to the generated wrapper chart.
 func (c *ChartWrapper) Generate(config WrapperChartConfig) (string, error) {
-	// Sanitize release name
-	safeName := sanitizeName(config.ReleaseName)
-	if safeName == "" {
-		return "", fmt.Errorf("invalid release name: %s", config.ReleaseName)
+	releasePath, err := wrapperChartReleasePath(config.ContextName, config.ReleaseName)
+	if err != nil {
+		return "", err
 	}
 
-	// Sanitize context name to prevent path traversal
-	safeContext := sanitizeName(config.ContextName)
-	if safeContext == "" {
-		return "", fmt.Errorf("invalid context name: %s", config.ContextName)
+	basePath := filepath.Join(releasePath, "scratch")
+	if !config.Scratch {
+		basePath = c.nextGeneration(releasePath)
 	}
 
-	basePath := filepath.Join("~", ".dx", safeContext, "wrapper-charts", safeName)
-
 	templatesPath := filepath.Join(basePath, "templates")
 	if err := c.fileSystem.MkdirAll(templatesPath, ports.ReadWriteExecute); err != nil {
 		return "", fmt.Errorf("failed to create wrapper chart directory: %w", err)
@@ -79,7 +97,124 @@ func (c *ChartWrapper) Generate(config WrapperChartConfig) (string, error) {
 	return expandTildePath(basePath, homeDir)
 }
 
-// Cleanup removes the wrapper chart directory for a release.
+// Copy copies a wrapper chart of a release, such as a kept generation, to the next generation like
+// Generate, so reinstalling it keeps it like a new chart. Returns the absolute path to the copy.
+func (c *ChartWrapper) Copy(contextName, releaseName, chartPath string) (string, error) {
+	releasePath, err := wrapperChartReleasePath(contextName, releaseName)
+	if err != nil {
+		return "", err
+	}
+	basePath := c.nextGeneration(releasePath)
+
+	for _, file := range []string{"Chart.yaml", filepath.Join("templates", "manifests.yaml")} {
+		content, err := c.fileSystem.ReadFile(filepath.Join(chartPath, file))
+		if err != nil {
+			return "", fmt.Errorf("failed to read wrapper chart: %w", err)
+		}
+		if err := c.fileSystem.WriteFile(filepath.Join(basePath, file), content, ports.ReadAllWriteOwner); err != nil {
+			return "", fmt.Errorf("failed to write wrapper chart: %w", err)
+		}
+	}
+
+	homeDir, err := c.fileSystem.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return expandTildePath(basePath, homeDir)
+}
+
+// nextGeneration returns the directory of the generation after the last kept one of a release.
+func (c *ChartWrapper) nextGeneration(releasePath string) string {
+	generation := 1
+	if generations := c.loadGenerations(releasePath); len(generations) > 0 {
+		generation = generations[len(generations)-1] + 1
+	}
+	return filepath.Join(releasePath, strconv.Itoa(generation))
+}
+
+// Commit keeps the wrapper chart generated by Generate at chartPath as a generation of its release,
+// once it was installed, and removes generations beyond MaxWrapperChartGenerations.
+func (c *ChartWrapper) Commit(contextName, releaseName, chartPath string) error {
+	releasePath, err := wrapperChartReleasePath(contextName, releaseName)
+	if err != nil {
+		return err
+	}
+
+	generation, err := strconv.Atoi(filepath.Base(chartPath))
+	if err != nil {
+		return fmt.Errorf("not a wrapper chart generation: %s", chartPath)
+	}
+
+	generations := c.loadGenerations(releasePath)
+	if len(generations) > 0 && generations[len(generations)-1] >= generation {
+		return nil
+	}
+	return c.saveGenerations(releasePath, append(generations, generation))
+}
+
+// wrapperChartReleasePath returns the directory holding the wrapper charts of a release.
+func wrapperChartReleasePath(contextName, releaseName string) (string, error) {
+	// Sanitize release name
+	safeName := sanitizeName(releaseName)
+	if safeName == "" {
+		return "", fmt.Errorf("invalid release name: %s", releaseName)
+	}
+
+	// Sanitize context name to prevent path traversal
+	safeContext := sanitizeName(contextName)
+	if safeContext == "" {
+		return "", fmt.Errorf("invalid context name: %s", contextName)
+	}
+
+	return filepath.Join("~", ".dx", safeContext, "wrapper-charts", safeName), nil
+}
+
+// saveGenerations records the kept generations of the wrapper charts of a release and removes those
+// beyond MaxWrapperChartGenerations.
+func (c *ChartWrapper) saveGenerations(releasePath string, generations []int) error {
+	if len(generations) > MaxWrapperChartGenerations {
+		pruned := generations[:len(generations)-MaxWrapperChartGenerations]
+		generations = generations[len(pruned):]
+		for _, generation := range pruned {
+			// Ignore removal errors - a leftover chart only takes up space
+			_ = c.fileSystem.RemoveAll(filepath.Join(releasePath, strconv.Itoa(generation)))
+		}
+	}
+
+	data, err := json.Marshal(generations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal wrapper chart generations: %w", err)
+	}
+	if err := c.fileSystem.WriteFile(
+		filepath.Join(releasePath, wrapperChartGenerationsFile),
+		data,
+		ports.ReadAllWriteOwner,
+	); err != nil {
+		return fmt.Errorf("failed to write wrapper chart generations: %w", err)
+	}
+	return nil
+}
+
+// loadGenerations reads the kept generations of the wrapper charts of a release.
+// A missing or unreadable list is treated as empty.
+func (c *ChartWrapper) loadGenerations(releasePath string) []int {
+	path := filepath.Join(releasePath, wrapperChartGenerationsFile)
+	exists, err := c.fileSystem.FileExists(path)
+	if err != nil || !exists {
+		return nil
+	}
+	data, err := c.fileSystem.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var generations []int
+	if err := json.Unmarshal(data, &generations); err != nil {
+		return nil
+	}
+	return generations
+}
+
+// Cleanup removes the wrapper chart directory for a release, including all kept generations.
 func (c *ChartWrapper) Cleanup(contextName, releaseName string) error {
 	safeName := sanitizeName(releaseName)
 	if safeName == "" {
@@ -113,7 +248,7 @@ func (c *ChartWrapper) generateChartYaml(config WrapperChartConfig) string {
 	sb.WriteString("appVersion: \"1.0.0\"\n")
 
 	// Add annotations for traceability
-	if config.OriginalChartName != "" || config.OriginalChartPath != "" {
+	if config.OriginalChartName != "" || config.OriginalChartPath != "" || config.ManifestsChecksum != "" {
 		sb.WriteString("annotations:\n")
 		if config.OriginalChartName != "" {
 			sb.WriteString(fmt.Sprintf("  dx.wrapped-chart: \"%s\"\n", escapeYamlString(config.OriginalChartName)))
@@ -121,6 +256,9 @@ func (c *ChartWrapper) generateChartYaml(config WrapperChartConfig) string {
 		if config.OriginalChartPath != "" {
 			sb.WriteString(fmt.Sprintf("  dx.wrapped-path: \"%s\"\n", escapeYamlString(config.OriginalChartPath)))
 		}
+		if config.ManifestsChecksum != "" {
+			sb.WriteString(fmt.Sprintf("  %s: \"%s\"\n", ManifestsChecksumAnnotation, escapeYamlString(config.ManifestsChecksum)))
+		}
 	}
 
 	return sb.String()
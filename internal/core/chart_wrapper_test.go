@@ -50,11 +50,12 @@ func (m *chartWrapperMockFileSystem) WriteFile(path string, content []byte, acce
 }
 
 func (m *chartWrapperMockFileSystem) ReadFile(path string) ([]byte, error) {
-	return nil, nil
+	return m.writtenFiles[path], nil
 }
 
 func (m *chartWrapperMockFileSystem) FileExists(path string) (bool, error) {
-	return false, nil
+	_, exists := m.writtenFiles[path]
+	return exists, nil
 }
 
 func (m *chartWrapperMockFileSystem) EnsureDirExists(path string) error {
@@ -77,6 +78,14 @@ func (m *chartWrapperMockFileSystem) RemoveAll(path string) error {
 	return nil
 }
 
+func (m *chartWrapperMockFileSystem) Rename(oldPath, newPath string) error {
+	return nil
+}
+
+func (m *chartWrapperMockFileSystem) ReadDir(path string) ([]string, error) {
+	return nil, nil
+}
+
 func (m *chartWrapperMockFileSystem) HomeDir() (string, error) {
 	if m.homeDirError != nil {
 		return "", m.homeDirError
@@ -240,11 +249,11 @@ func TestChartWrapper_Generate_Success(t *testing.T) {
 	// Generate returns an absolute path for external tools like Helm
 	home, err := os.UserHomeDir()
 	require.NoError(t, err)
-	expectedAbsPath := filepath.Join(home, ".dx", "test-context", "wrapper-charts", "test-release")
+	expectedAbsPath := filepath.Join(home, ".dx", "test-context", "wrapper-charts", "test-release", "1")
 	assert.Equal(t, expectedAbsPath, path)
 
 	// FileSystem operations use tilde paths internally
-	tildePath := filepath.Join("~", ".dx", "test-context", "wrapper-charts", "test-release")
+	tildePath := filepath.Join("~", ".dx", "test-context", "wrapper-charts", "test-release", "1")
 
 	// Verify directory was created via MkdirAll
 	templatesPath := filepath.Join(tildePath, "templates")
@@ -262,6 +271,121 @@ func TestChartWrapper_Generate_Success(t *testing.T) {
 	assert.Equal(t, config.PatchedManifests, fs.writtenFiles[manifestsPath])
 }
 
+func TestChartWrapper_Generate_KeepsGenerations(t *testing.T) {
+	fs := newChartWrapperMockFileSystem()
+	wrapper := ProvideChartWrapper(fs)
+	config := WrapperChartConfig{
+		ReleaseName:      "test-release",
+		ContextName:      "test-context",
+		PatchedManifests: []byte("test"),
+	}
+
+	first, err := wrapper.Generate(config)
+	require.NoError(t, err)
+	require.NoError(t, wrapper.Commit(config.ContextName, config.ReleaseName, first))
+	second, err := wrapper.Generate(config)
+	require.NoError(t, err)
+	require.NoError(t, wrapper.Commit(config.ContextName, config.ReleaseName, second))
+
+	assert.Equal(t, "1", filepath.Base(first))
+	assert.Equal(t, "2", filepath.Base(second))
+	releasePath := filepath.Join("~", ".dx", "test-context", "wrapper-charts", "test-release")
+	assert.Contains(t, fs.writtenFiles, filepath.Join(releasePath, "1", "Chart.yaml"))
+	assert.Contains(t, fs.writtenFiles, filepath.Join(releasePath, "2", "Chart.yaml"))
+	assert.Equal(t, "[1,2]", string(fs.writtenFiles[filepath.Join(releasePath, "generations.json")]))
+	assert.Empty(t, fs.removedPaths)
+}
+
+func TestChartWrapper_Generate_PrunesOldGenerations(t *testing.T) {
+	fs := newChartWrapperMockFileSystem()
+	wrapper := ProvideChartWrapper(fs)
+	config := WrapperChartConfig{
+		ReleaseName:      "test-release",
+		ContextName:      "test-context",
+		PatchedManifests: []byte("test"),
+	}
+
+	for i := 0; i < MaxWrapperChartGenerations+2; i++ {
+		path, err := wrapper.Generate(config)
+		require.NoError(t, err)
+		require.NoError(t, wrapper.Commit(config.ContextName, config.ReleaseName, path))
+	}
+
+	releasePath := filepath.Join("~", ".dx", "test-context", "wrapper-charts", "test-release")
+	assert.True(t, fs.removedPaths[filepath.Join(releasePath, "1")])
+	assert.True(t, fs.removedPaths[filepath.Join(releasePath, "2")])
+	assert.False(t, fs.removedPaths[filepath.Join(releasePath, "3")])
+	assert.Equal(t, "[3,4,5,6,7,8,9,10,11,12]", string(fs.writtenFiles[filepath.Join(releasePath, "generations.json")]))
+}
+
+func TestChartWrapper_Generate_ReusesUncommittedGeneration(t *testing.T) {
+	fs := newChartWrapperMockFileSystem()
+	wrapper := ProvideChartWrapper(fs)
+	config := WrapperChartConfig{
+		ReleaseName:      "test-release",
+		ContextName:      "test-context",
+		PatchedManifests: []byte("test"),
+	}
+
+	failed, err := wrapper.Generate(config)
+	require.NoError(t, err)
+	retried, err := wrapper.Generate(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1", filepath.Base(failed))
+	assert.Equal(t, "1", filepath.Base(retried))
+	releasePath := filepath.Join("~", ".dx", "test-context", "wrapper-charts", "test-release")
+	assert.NotContains(t, fs.writtenFiles, filepath.Join(releasePath, "generations.json"))
+}
+
+func TestChartWrapper_Copy_WritesNextGeneration(t *testing.T) {
+	fs := newChartWrapperMockFileSystem()
+	wrapper := ProvideChartWrapper(fs)
+	config := WrapperChartConfig{
+		ReleaseName:      "test-release",
+		ContextName:      "test-context",
+		PatchedManifests: []byte("kind: ConfigMap"),
+	}
+	first, err := wrapper.Generate(config)
+	require.NoError(t, err)
+	require.NoError(t, wrapper.Commit(config.ContextName, config.ReleaseName, first))
+
+	releasePath := filepath.Join("~", ".dx", "test-context", "wrapper-charts", "test-release")
+	copied, err := wrapper.Copy(config.ContextName, config.ReleaseName, filepath.Join(releasePath, "1"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "2", filepath.Base(copied))
+	assert.Equal(t, []byte("kind: ConfigMap"), fs.writtenFiles[filepath.Join(releasePath, "2", "templates", "manifests.yaml")])
+	assert.Equal(t, fs.writtenFiles[filepath.Join(releasePath, "1", "Chart.yaml")], fs.writtenFiles[filepath.Join(releasePath, "2", "Chart.yaml")])
+}
+
+func TestChartWrapper_Commit_NotAGeneration(t *testing.T) {
+	fs := newChartWrapperMockFileSystem()
+	wrapper := ProvideChartWrapper(fs)
+
+	err := wrapper.Commit("test-context", "test-release", "/home/user/.dx/test-context/wrapper-charts/test-release/scratch")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a wrapper chart generation")
+}
+
+func TestChartWrapper_Generate_Scratch(t *testing.T) {
+	fs := newChartWrapperMockFileSystem()
+	wrapper := ProvideChartWrapper(fs)
+
+	path, err := wrapper.Generate(WrapperChartConfig{
+		ReleaseName:      "test-release",
+		ContextName:      "test-context",
+		PatchedManifests: []byte("test"),
+		Scratch:          true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "scratch", filepath.Base(path))
+	releasePath := filepath.Join("~", ".dx", "test-context", "wrapper-charts", "test-release")
+	assert.Contains(t, fs.writtenFiles, filepath.Join(releasePath, "scratch", "Chart.yaml"))
+	assert.NotContains(t, fs.writtenFiles, filepath.Join(releasePath, "generations.json"))
+}
+
 func TestChartWrapper_Generate_InvalidReleaseName(t *testing.T) {
 	fs := newChartWrapperMockFileSystem()
 	wrapper := ProvideChartWrapper(fs)
@@ -443,7 +567,7 @@ func TestChartWrapper_Generate_SanitizesContextName(t *testing.T) {
 			path, err := wrapper.Generate(config)
 			require.NoError(t, err)
 			// Verify the sanitized context name is used in the returned absolute path
-			expectedPath := filepath.Join(home, ".dx", tt.expectedContext, "wrapper-charts", "valid-release")
+			expectedPath := filepath.Join(home, ".dx", tt.expectedContext, "wrapper-charts", "valid-release", "1")
 			assert.Equal(t, expectedPath, path)
 		})
 	}
@@ -491,6 +615,22 @@ func TestGenerateChartYaml_WithAnnotations(t *testing.T) {
 	assert.Contains(t, yaml, "dx.wrapped-path: \"/path/to/chart\"")
 }
 
+func TestGenerateChartYaml_WithManifestsChecksum(t *testing.T) {
+	fs := newChartWrapperMockFileSystem()
+	wrapper := ProvideChartWrapper(fs)
+
+	config := WrapperChartConfig{
+		ReleaseName:       "test",
+		ManifestsChecksum: "abc123",
+	}
+
+	yaml := wrapper.generateChartYaml(config)
+
+	assert.Contains(t, yaml, "annotations:")
+	assert.Contains(t, yaml, "dx.manifests-checksum: \"abc123\"")
+	assert.NotContains(t, yaml, "dx.wrapped-chart")
+}
+
 func TestEscapeYamlString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxDeploymentHistory is the number of deployments kept in the history of each service.
+// It matches MaxWrapperChartGenerations, so the kept deployments can usually be rolled back to.
+const MaxDeploymentHistory = MaxWrapperChartGenerations
+
+// DeploymentHistory records the deployments of the services of a context, so they can be listed and
+// rolled back. The history is stored in ~/.dx/<context>/history.json.
+type DeploymentHistory struct {
+	configRepository ConfigRepository
+	fileSystem       ports.FileSystem
+	scm              ports.Scm
+	// Guards reads and writes of the history file
+	mu sync.Mutex
+}
+
+type deploymentHistoryFile struct {
+	Services map[string][]domain.Deployment `json:"services"`
+}
+
+// ProvideDeploymentHistory creates a new DeploymentHistory.
+func ProvideDeploymentHistory(
+	configRepository ConfigRepository,
+	fileSystem ports.FileSystem,
+	scm ports.Scm,
+) *DeploymentHistory {
+	return &DeploymentHistory{
+		configRepository: configRepository,
+		fileSystem:       fileSystem,
+		scm:              scm,
+	}
+}
+
+// Sources returns the revisions of the chart and images of a service as currently downloaded.
+// Revisions that can't be determined are left empty.
+func (h *DeploymentHistory) Sources(service *domain.Service) (domain.SourceRevision, []domain.SourceRevision) {
+	chart := domain.SourceRevision{Name: service.HelmRepoPath, Ref: service.HelmBranch}
+	if service.UsesChartRepository() {
+		chart = domain.SourceRevision{Name: service.HelmChart, Ref: service.HelmChartVersion, Revision: h.chartVersion(service)}
+	} else if revision, err := h.scm.Revision(service.HelmPath); err == nil {
+		chart.Revision = revision
+	}
+
+	var images []domain.SourceRevision
+	for _, image := range service.DockerImages {
+		source := domain.SourceRevision{Name: image.Name, Ref: image.GitRef}
+		if revision, err := h.scm.Revision(image.Path); err == nil {
+			source.Revision = revision
+		}
+		images = append(images, source)
+	}
+	for _, image := range service.RemoteImages {
+		images = append(images, domain.SourceRevision{Name: image})
+	}
+	return chart, images
+}
+
+// chartVersion returns the version in the Chart.yaml of a pulled chart, or an empty string if it
+// can't be read.
+func (h *DeploymentHistory) chartVersion(service *domain.Service) string {
+	data, err := h.fileSystem.ReadFile(filepath.Join(service.HelmPath, "Chart.yaml"))
+	if err != nil {
+		return ""
+	}
+	var chart struct {
+		Version string `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &chart); err != nil {
+		return ""
+	}
+	return chart.Version
+}
+
+// Record appends a deployment of a service to its history, assigning it the next revision and the
+// current time. Only the last MaxDeploymentHistory deployments are kept.
+// Returns the recorded deployment.
+func (h *DeploymentHistory) Record(serviceName string, deployment domain.Deployment) (domain.Deployment, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history, err := h.load()
+	if err != nil {
+		return domain.Deployment{}, err
+	}
+
+	deployments := history.Services[serviceName]
+	deployment.Revision = 1
+	if len(deployments) > 0 {
+		deployment.Revision = deployments[len(deployments)-1].Revision + 1
+	}
+	deployment.Timestamp = time.Now()
+	deployments = append(deployments, deployment)
+	if len(deployments) > MaxDeploymentHistory {
+		deployments = deployments[len(deployments)-MaxDeploymentHistory:]
+	}
+	history.Services[serviceName] = deployments
+	return deployment, h.save(history)
+}
+
+// List returns the recorded deployments of a service, oldest first.
+func (h *DeploymentHistory) List(serviceName string) ([]domain.Deployment, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+	return history.Services[serviceName], nil
+}
+
+// Services returns the names of the services with a recorded deployment, sorted.
+func (h *DeploymentHistory) Services() ([]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+	services := slices.Collect(maps.Keys(history.Services))
+	slices.Sort(services)
+	return services, nil
+}
+
+// Remove deletes the history of a service.
+func (h *DeploymentHistory) Remove(serviceName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history, err := h.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := history.Services[serviceName]; !ok {
+		return nil
+	}
+	delete(history.Services, serviceName)
+	return h.save(history)
+}
+
+// Find returns the deployment of a service with the given revision. Revision 0 selects the
+// deployment before the latest one.
+func (h *DeploymentHistory) Find(serviceName string, revision int) (domain.Deployment, error) {
+	deployments, err := h.List(serviceName)
+	if err != nil {
+		return domain.Deployment{}, err
+	}
+
+	if revision == 0 {
+		if len(deployments) < 2 {
+			return domain.Deployment{}, fmt.Errorf("service %s has no previous deployment to roll back to", serviceName)
+		}
+		return deployments[len(deployments)-2], nil
+	}
+	for _, deployment := range deployments {
+		if deployment.Revision == revision {
+			return deployment, nil
+		}
+	}
+	return domain.Deployment{}, fmt.Errorf("revision %d of service %s is not in its deployment history", revision, serviceName)
+}
+
+// save writes the history file. Must be called with mu held.
+func (h *DeploymentHistory) save(history *deploymentHistoryFile) error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deployment history: %w", err)
+	}
+	path, err := h.historyPath()
+	if err != nil {
+		return err
+	}
+	if err := h.fileSystem.WriteFile(path, data, ports.ReadWrite); err != nil {
+		return fmt.Errorf("failed to write deployment history: %w", err)
+	}
+	return nil
+}
+
+// load reads the history file. A missing history is treated as empty.
+func (h *DeploymentHistory) load() (*deploymentHistoryFile, error) {
+	history := &deploymentHistoryFile{Services: make(map[string][]domain.Deployment)}
+
+	path, err := h.historyPath()
+	if err != nil {
+		return nil, err
+	}
+	exists, err := h.fileSystem.FileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return history, nil
+	}
+
+	data, err := h.fileSystem.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment history: %w", err)
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse deployment history %s: %w", path, err)
+	}
+	if history.Services == nil {
+		history.Services = make(map[string][]domain.Deployment)
+	}
+	return history, nil
+}
+
+func (h *DeploymentHistory) historyPath() (string, error) {
+	contextName, err := h.configRepository.LoadCurrentContextName()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join("~", ".dx", contextName, "history.json"), nil
+}
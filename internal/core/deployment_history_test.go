@@ -0,0 +1,160 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestDeploymentHistory(t *testing.T) (*DeploymentHistory, *testutil.TestFileSystem, *testutil.MockScm) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentContextName").Return("test-context", nil)
+	fileSystem := testutil.NewTestFileSystem(t)
+	scm := new(testutil.MockScm)
+	return ProvideDeploymentHistory(configRepository, fileSystem, scm), fileSystem, scm
+}
+
+func TestDeploymentHistory_Record_AssignsRevisions(t *testing.T) {
+	sut, fileSystem, _ := createTestDeploymentHistory(t)
+
+	first, err := sut.Record("api", domain.Deployment{ManifestsChecksum: "checksum-1"})
+	require.NoError(t, err)
+	second, err := sut.Record("api", domain.Deployment{ManifestsChecksum: "checksum-2"})
+	require.NoError(t, err)
+	other, err := sut.Record("worker", domain.Deployment{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, first.Revision)
+	assert.Equal(t, 2, second.Revision)
+	assert.Equal(t, 1, other.Revision)
+	assert.False(t, first.Timestamp.IsZero())
+	deployments, err := sut.List("api")
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+	assert.Equal(t, "checksum-1", deployments[0].ManifestsChecksum)
+	assert.Equal(t, 2, deployments[1].Revision)
+	assert.True(t, deployments[1].Timestamp.Equal(second.Timestamp))
+	exists, err := fileSystem.FileExists("~/.dx/test-context/history.json")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestDeploymentHistory_Record_KeepsLastDeployments(t *testing.T) {
+	sut, _, _ := createTestDeploymentHistory(t)
+
+	for i := 0; i < MaxDeploymentHistory+2; i++ {
+		_, err := sut.Record("api", domain.Deployment{})
+		require.NoError(t, err)
+	}
+
+	deployments, err := sut.List("api")
+	require.NoError(t, err)
+	require.Len(t, deployments, MaxDeploymentHistory)
+	assert.Equal(t, 3, deployments[0].Revision)
+	assert.Equal(t, MaxDeploymentHistory+2, deployments[len(deployments)-1].Revision)
+}
+
+func TestDeploymentHistory_List_NoHistory(t *testing.T) {
+	sut, _, _ := createTestDeploymentHistory(t)
+
+	deployments, err := sut.List("api")
+
+	require.NoError(t, err)
+	assert.Empty(t, deployments)
+}
+
+func TestDeploymentHistory_Find(t *testing.T) {
+	sut, _, _ := createTestDeploymentHistory(t)
+	for _, checksum := range []string{"checksum-1", "checksum-2", "checksum-3"} {
+		_, err := sut.Record("api", domain.Deployment{ManifestsChecksum: checksum})
+		require.NoError(t, err)
+	}
+
+	previous, err := sut.Find("api", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "checksum-2", previous.ManifestsChecksum)
+
+	first, err := sut.Find("api", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "checksum-1", first.ManifestsChecksum)
+
+	_, err = sut.Find("api", 4)
+	assert.EqualError(t, err, "revision 4 of service api is not in its deployment history")
+}
+
+func TestDeploymentHistory_Find_NoPreviousDeployment(t *testing.T) {
+	sut, _, _ := createTestDeploymentHistory(t)
+	_, err := sut.Record("api", domain.Deployment{})
+	require.NoError(t, err)
+
+	_, err = sut.Find("api", 0)
+
+	assert.EqualError(t, err, "service api has no previous deployment to roll back to")
+}
+
+func TestDeploymentHistory_Remove(t *testing.T) {
+	sut, _, _ := createTestDeploymentHistory(t)
+	for _, serviceName := range []string{"worker", "api", "old-worker"} {
+		_, err := sut.Record(serviceName, domain.Deployment{})
+		require.NoError(t, err)
+	}
+
+	services, err := sut.Services()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "old-worker", "worker"}, services)
+
+	require.NoError(t, sut.Remove("old-worker"))
+	require.NoError(t, sut.Remove("unknown"))
+
+	services, err = sut.Services()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "worker"}, services)
+}
+
+func TestDeploymentHistory_Sources_GitChartAndImages(t *testing.T) {
+	sut, _, scm := createTestDeploymentHistory(t)
+	scm.On("Revision", "/charts/api").Return("chart-sha", nil)
+	scm.On("Revision", "/images/api").Return("image-sha", nil)
+	scm.On("Revision", "/images/missing").Return("", errors.New("not a git repository"))
+	service := &domain.Service{
+		Name:         "api",
+		HelmRepoPath: "git@github.com:org/charts.git",
+		HelmBranch:   "main",
+		HelmPath:     "/charts/api",
+		DockerImages: []domain.DockerImage{
+			{Name: "api:latest", GitRef: "develop", Path: "/images/api"},
+			{Name: "sidecar:latest", GitRef: "main", Path: "/images/missing"},
+		},
+		RemoteImages: []string{"postgres:16"},
+	}
+
+	chart, images := sut.Sources(service)
+
+	assert.Equal(t, domain.SourceRevision{Name: "git@github.com:org/charts.git", Ref: "main", Revision: "chart-sha"}, chart)
+	assert.Equal(t, []domain.SourceRevision{
+		{Name: "api:latest", Ref: "develop", Revision: "image-sha"},
+		{Name: "sidecar:latest", Ref: "main"},
+		{Name: "postgres:16"},
+	}, images)
+}
+
+func TestDeploymentHistory_Sources_PulledChart(t *testing.T) {
+	sut, fileSystem, scm := createTestDeploymentHistory(t)
+	require.NoError(t, fileSystem.WriteFile("/charts/redis/Chart.yaml", []byte("name: redis\nversion: 19.0.1\n"), 0))
+	service := &domain.Service{
+		Name:      "redis",
+		HelmChart: "oci://registry.example.com/charts/redis",
+		HelmPath:  "/charts/redis",
+	}
+
+	chart, images := sut.Sources(service)
+
+	assert.Equal(t, domain.SourceRevision{Name: "oci://registry.example.com/charts/redis", Revision: "19.0.1"}, chart)
+	assert.Empty(t, images)
+	scm.AssertNotCalled(t, "Revision")
+}
@@ -3,12 +3,14 @@ package core
 import (
 	"crypto/sha256"
 	"embed"
-	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"text/template"
 
 	"dx/internal/core/domain"
+	"dx/internal/ports"
+	"dx/internal/version"
 
 	"gopkg.in/yaml.v3"
 )
@@ -32,6 +34,26 @@ type DevProxyConfigs struct {
 	HelmDeploymentYaml  []byte
 }
 
+// DevProxyConfigFile is a single generated dev-proxy file, addressed relative to the
+// dev-proxy directory of a context.
+type DevProxyConfigFile struct {
+	RelativePath string
+	Content      []byte
+	AccessMode   ports.AccessMode
+}
+
+// Files returns the generated files in a stable order together with the location they are
+// written to relative to $HOME/.dx/$CONTEXT_NAME/dev-proxy/.
+func (c *DevProxyConfigs) Files() []DevProxyConfigFile {
+	return []DevProxyConfigFile{
+		{RelativePath: filepath.Join("haproxy", "haproxy.cfg"), Content: c.HAProxyConfig, AccessMode: ports.ReadAllWriteOwner},
+		{RelativePath: filepath.Join("haproxy", "Dockerfile"), Content: c.HAProxyDockerfile, AccessMode: ports.ReadWrite},
+		{RelativePath: filepath.Join("mitmproxy", "Dockerfile"), Content: c.MitmProxyDockerfile, AccessMode: ports.ReadWrite},
+		{RelativePath: filepath.Join("helm", "Chart.yaml"), Content: c.HelmChartYaml, AccessMode: ports.ReadWrite},
+		{RelativePath: filepath.Join("helm", "templates", "dev-proxy.yaml"), Content: c.HelmDeploymentYaml, AccessMode: ports.ReadWrite},
+	}
+}
+
 // DevProxyConfigGenerator generates dev-proxy configuration files from domain configuration.
 // This is pure business logic with no I/O operations.
 type DevProxyConfigGenerator struct{}
@@ -44,56 +66,47 @@ func ProvideDevProxyConfigGenerator() *DevProxyConfigGenerator {
 // Generate creates all dev-proxy configuration files from the given configuration context.
 // Returns a DevProxyConfigs struct containing all generated content.
 func (g *DevProxyConfigGenerator) Generate(configContext *domain.ConfigurationContext) (*DevProxyConfigs, error) {
-	values := g.buildTemplateValues(configContext)
-
-	haproxyConfig, err := renderTemplate("templates/dev-proxy/haproxy/haproxy.cfg.tpl", values)
+	checksum, err := g.GenerateChecksum(configContext)
 	if err != nil {
-		return nil, fmt.Errorf("failed to render haproxy config: %w", err)
+		return nil, err
 	}
 
-	haproxyDockerfile, err := renderTemplate("templates/dev-proxy/haproxy/Dockerfile.tpl", values)
-	if err != nil {
-		return nil, fmt.Errorf("failed to render haproxy dockerfile: %w", err)
-	}
+	values := g.buildTemplateValues(configContext)
+	values["Checksum"] = checksum
 
-	mitmproxyDockerfile, err := renderTemplate("templates/dev-proxy/mitmproxy/Dockerfile.tpl", values)
-	if err != nil {
-		return nil, fmt.Errorf("failed to render mitmproxy dockerfile: %w", err)
-	}
+	return renderDevProxyConfigs(values)
+}
 
-	helmChartYaml, err := renderTemplate("templates/dev-proxy/helm/Chart.yaml.tpl", values)
-	if err != nil {
-		return nil, fmt.Errorf("failed to render helm chart.yaml: %w", err)
-	}
+// GenerateChecksum computes the configuration checksum for a given context.
+// This checksum is used to detect configuration changes for the dev-proxy deployment.
+// It covers the dx version and every rendered dev-proxy file, so upgrades that change the
+// embedded templates trigger a rebuild just like changes to LocalServices do. The files are
+// rendered with an empty checksum, since the checksum itself ends up in the deployment manifest.
+// The result is a SHA256 hash truncated to 62 characters for readability and to ensure it fits
+// within common annotation display widths.
+func (g *DevProxyConfigGenerator) GenerateChecksum(configContext *domain.ConfigurationContext) (string, error) {
+	values := g.buildTemplateValues(configContext)
+	values["Checksum"] = ""
 
-	helmDeploymentYaml, err := renderTemplate("templates/dev-proxy/helm/deployment.yaml.tpl", values)
+	configs, err := renderDevProxyConfigs(values)
 	if err != nil {
-		return nil, fmt.Errorf("failed to render helm deployment.yaml: %w", err)
+		return "", err
 	}
 
-	return &DevProxyConfigs{
-		HAProxyConfig:       haproxyConfig,
-		HAProxyDockerfile:   haproxyDockerfile,
-		MitmProxyDockerfile: mitmproxyDockerfile,
-		HelmChartYaml:       helmChartYaml,
-		HelmDeploymentYaml:  helmDeploymentYaml,
-	}, nil
-}
-
-// GenerateChecksum computes the configuration checksum for a given context.
-// This checksum is used to detect configuration changes for the dev-proxy deployment.
-// The checksum is a SHA256 hash of the LocalServices configuration, truncated to 62 characters
-// for readability and to ensure it fits within common annotation display widths.
-func (g *DevProxyConfigGenerator) GenerateChecksum(configContext *domain.ConfigurationContext) string {
 	hash := sha256.New()
-	// Error can be safely ignored: LocalServices contains only JSON-serializable primitive types
-	// (strings, ints, and map[string]string). json.Marshal cannot fail for these types.
-	serviceJSON, _ := json.Marshal(configContext.LocalServices)
-	hash.Write(serviceJSON)
-	return fmt.Sprintf("%x", hash.Sum(nil))[:62]
+	hash.Write([]byte(version.Version))
+	hash.Write([]byte{0})
+	for _, file := range configs.Files() {
+		hash.Write([]byte(file.RelativePath))
+		hash.Write([]byte{0})
+		hash.Write(file.Content)
+		hash.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))[:62], nil
 }
 
 // buildTemplateValues constructs the values map for template rendering.
+// The Checksum value is added by the caller.
 func (g *DevProxyConfigGenerator) buildTemplateValues(configContext *domain.ConfigurationContext) map[string]interface{} {
 	frontendPort := devProxyFrontendStartPort
 	proxyPort := devProxyProxyStartPort
@@ -113,13 +126,55 @@ func (g *DevProxyConfigGenerator) buildTemplateValues(configContext *domain.Conf
 		proxyPort++
 	}
 
-	checksum := g.GenerateChecksum(configContext)
-
 	return map[string]interface{}{
-		"Services": services,
-		"Name":     configContext.Name,
-		"Checksum": checksum,
+		"Services":        services,
+		"Name":            configContext.Name,
+		"ImagePullPolicy": devProxyImagePullPolicy(configContext),
+	}
+}
+
+// devProxyImagePullPolicy returns the pull policy of the dev-proxy images. They are pulled from the
+// registry when images are loaded into one, and are otherwise expected to be present on the node.
+func devProxyImagePullPolicy(configContext *domain.ConfigurationContext) string {
+	if configContext.ActiveImageLoader() == domain.ImageLoaderRegistry {
+		return "IfNotPresent"
+	}
+	return "Never"
+}
+
+func renderDevProxyConfigs(values map[string]interface{}) (*DevProxyConfigs, error) {
+	haproxyConfig, err := renderTemplate("templates/dev-proxy/haproxy/haproxy.cfg.tpl", values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render haproxy config: %w", err)
+	}
+
+	haproxyDockerfile, err := renderTemplate("templates/dev-proxy/haproxy/Dockerfile.tpl", values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render haproxy dockerfile: %w", err)
 	}
+
+	mitmproxyDockerfile, err := renderTemplate("templates/dev-proxy/mitmproxy/Dockerfile.tpl", values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render mitmproxy dockerfile: %w", err)
+	}
+
+	helmChartYaml, err := renderTemplate("templates/dev-proxy/helm/Chart.yaml.tpl", values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render helm chart.yaml: %w", err)
+	}
+
+	helmDeploymentYaml, err := renderTemplate("templates/dev-proxy/helm/deployment.yaml.tpl", values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render helm deployment.yaml: %w", err)
+	}
+
+	return &DevProxyConfigs{
+		HAProxyConfig:       haproxyConfig,
+		HAProxyDockerfile:   haproxyDockerfile,
+		MitmProxyDockerfile: mitmproxyDockerfile,
+		HelmChartYaml:       helmChartYaml,
+		HelmDeploymentYaml:  helmDeploymentYaml,
+	}, nil
 }
 
 var templateFunctions = template.FuncMap{
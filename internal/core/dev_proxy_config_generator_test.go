@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"dx/internal/core/domain"
+	"dx/internal/version"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -218,7 +219,7 @@ func TestDevProxyConfigGenerator_buildTemplateValues_PortAssignment(t *testing.T
 	assert.Equal(t, devProxyProxyStartPort+2, services[2]["ProxyPort"])
 }
 
-func TestDevProxyConfigGenerator_buildTemplateValues_Checksum(t *testing.T) {
+func TestDevProxyConfigGenerator_GenerateChecksum_Format(t *testing.T) {
 	configContext := &domain.ConfigurationContext{
 		Name: "test-context",
 		LocalServices: []domain.LocalService{
@@ -227,13 +228,93 @@ func TestDevProxyConfigGenerator_buildTemplateValues_Checksum(t *testing.T) {
 	}
 
 	sut := ProvideDevProxyConfigGenerator()
-	values := sut.buildTemplateValues(configContext)
+	checksum, err := sut.GenerateChecksum(configContext)
 
-	checksum := values["Checksum"].(string)
+	require.NoError(t, err)
 	assert.Len(t, checksum, 62, "Checksum should be 62 characters (truncated SHA256 hex)")
 	assert.True(t, isHexString(checksum), "Checksum should be a valid hex string")
 }
 
+func TestDevProxyConfigGenerator_Generate_EmbedsChecksumInDeployment(t *testing.T) {
+	configContext := &domain.ConfigurationContext{
+		Name: "test-context",
+		LocalServices: []domain.LocalService{
+			{Name: "svc1", KubernetesPort: 80, LocalPort: 3000, HealthCheckPath: "/", Selector: map[string]string{"app": "svc1"}},
+		},
+	}
+
+	sut := ProvideDevProxyConfigGenerator()
+	checksum, err := sut.GenerateChecksum(configContext)
+	require.NoError(t, err)
+	configs, err := sut.Generate(configContext)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(configs.HelmDeploymentYaml), "checksum: "+checksum)
+}
+
+func TestDevProxyConfigGenerator_Generate_ImagePullPolicyFollowsImageLoader(t *testing.T) {
+	tests := []struct {
+		imageLoader string
+		pullPolicy  string
+	}{
+		{"", "Never"},
+		{domain.ImageLoaderKind, "Never"},
+		{domain.ImageLoaderMinikube, "Never"},
+		{domain.ImageLoaderK3d, "Never"},
+		{domain.ImageLoaderRegistry, "IfNotPresent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageLoader, func(t *testing.T) {
+			configContext := &domain.ConfigurationContext{
+				Name:          "test-context",
+				ImageLoader:   tt.imageLoader,
+				ImageRegistry: "localhost:5000",
+			}
+
+			configs, err := ProvideDevProxyConfigGenerator().Generate(configContext)
+
+			require.NoError(t, err)
+			deployment := string(configs.HelmDeploymentYaml)
+			assert.Equal(t, 2, strings.Count(deployment, "imagePullPolicy: "+tt.pullPolicy))
+			assert.Equal(t, 2, strings.Count(deployment, "imagePullPolicy:"))
+		})
+	}
+}
+
+func TestDevProxyConfigGenerator_GenerateChecksum_ChangesWithVersion(t *testing.T) {
+	configContext := &domain.ConfigurationContext{
+		Name: "test-context",
+		LocalServices: []domain.LocalService{
+			{Name: "svc1", KubernetesPort: 80, LocalPort: 3000, HealthCheckPath: "/", Selector: map[string]string{"app": "svc1"}},
+		},
+	}
+	originalVersion := version.Version
+	t.Cleanup(func() { version.Version = originalVersion })
+
+	sut := ProvideDevProxyConfigGenerator()
+	version.Version = "1.0.0"
+	checksum1, err := sut.GenerateChecksum(configContext)
+	require.NoError(t, err)
+	version.Version = "1.1.0"
+	checksum2, err := sut.GenerateChecksum(configContext)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, checksum1, checksum2)
+}
+
+func TestDevProxyConfigGenerator_GenerateChecksum_ChangesWithContextName(t *testing.T) {
+	// The context name only affects the rendered templates, not LocalServices
+	sut := ProvideDevProxyConfigGenerator()
+
+	checksum1, err := sut.GenerateChecksum(&domain.ConfigurationContext{Name: "context-1"})
+	require.NoError(t, err)
+	checksum2, err := sut.GenerateChecksum(&domain.ConfigurationContext{Name: "context-2"})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, checksum1, checksum2)
+}
+
 func isHexString(s string) bool {
 	for _, c := range s {
 		if !strings.ContainsRune("0123456789abcdef", c) {
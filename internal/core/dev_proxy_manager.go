@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 
@@ -53,7 +54,10 @@ func (d *DevProxyManager) ShouldRebuildDevProxy() (bool, error) {
 		return true, nil
 	}
 
-	newChecksum := d.configGenerator.GenerateChecksum(configContext)
+	newChecksum, err := d.configGenerator.GenerateChecksum(configContext)
+	if err != nil {
+		return false, fmt.Errorf("failed to generate dev-proxy checksum: %w", err)
+	}
 	return currentChecksum != newChecksum, nil
 }
 
@@ -71,58 +75,101 @@ func (d *DevProxyManager) SaveConfiguration() error {
 	}
 
 	basePath := filepath.Join("~", ".dx", configContext.Name, "dev-proxy")
+	for _, file := range configs.Files() {
+		err = d.fileService.WriteFile(filepath.Join(basePath, file.RelativePath), file.Content, file.AccessMode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DevProxyFileDiff describes a dev-proxy file whose saved content differs from the freshly generated one.
+// Saved is nil when the file has not been written yet.
+type DevProxyFileDiff struct {
+	Path      string
+	Saved     []byte
+	Generated []byte
+}
 
-	// Write HAProxy config
-	err = d.fileService.WriteFile(
-		filepath.Join(basePath, "haproxy", "haproxy.cfg"),
-		configs.HAProxyConfig,
-		ports.ReadAllWriteOwner,
-	)
+// DevProxyDiff describes the difference between the deployed dev-proxy and the one the current
+// configuration and dx version would produce.
+type DevProxyDiff struct {
+	DeployedChecksum  string
+	GeneratedChecksum string
+	Files             []DevProxyFileDiff
+}
+
+// Diff compares the deployed dev-proxy checksum and the configuration files saved under
+// $HOME/.dx/$CONTEXT_NAME/dev-proxy/ with freshly generated ones. Nothing is written.
+func (d *DevProxyManager) Diff() (*DevProxyDiff, error) {
+	configContext, err := d.configRepository.LoadCurrentConfigurationContext()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to load configuration context: %w", err)
 	}
 
-	// Write HAProxy Dockerfile
-	err = d.fileService.WriteFile(
-		filepath.Join(basePath, "haproxy", "Dockerfile"),
-		configs.HAProxyDockerfile,
-		ports.ReadWrite,
-	)
+	deployedChecksum, err := d.containerOrchestrator.GetDevProxyChecksum()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get current dev-proxy checksum: %w", err)
 	}
 
-	// Write mitmproxy Dockerfile
-	err = d.fileService.WriteFile(
-		filepath.Join(basePath, "mitmproxy", "Dockerfile"),
-		configs.MitmProxyDockerfile,
-		ports.ReadWrite,
-	)
+	generatedChecksum, err := d.configGenerator.GenerateChecksum(configContext)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to generate dev-proxy checksum: %w", err)
 	}
 
-	// Write Helm Chart.yaml
-	err = d.fileService.WriteFile(
-		filepath.Join(basePath, "helm", "Chart.yaml"),
-		configs.HelmChartYaml,
-		ports.ReadWrite,
-	)
+	configs, err := d.configGenerator.Generate(configContext)
 	if err != nil {
+		return nil, err
+	}
+
+	diff := &DevProxyDiff{
+		DeployedChecksum:  deployedChecksum,
+		GeneratedChecksum: generatedChecksum,
+	}
+
+	basePath := filepath.Join("~", ".dx", configContext.Name, "dev-proxy")
+	for _, file := range configs.Files() {
+		path := filepath.Join(basePath, file.RelativePath)
+		exists, err := d.fileService.FileExists(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var saved []byte
+		if exists {
+			saved, err = d.fileService.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if exists && bytes.Equal(saved, file.Content) {
+			continue
+		}
+
+		diff.Files = append(diff.Files, DevProxyFileDiff{
+			Path:      path,
+			Saved:     saved,
+			Generated: file.Content,
+		})
+	}
+
+	return diff, nil
+}
+
+// Rebuild saves the configuration, builds the images and installs the dev-proxy.
+func (d *DevProxyManager) Rebuild() error {
+	if err := d.SaveConfiguration(); err != nil {
 		return err
 	}
 
-	// Write Helm deployment manifest
-	err = d.fileService.WriteFile(
-		filepath.Join(basePath, "helm", "templates", "dev-proxy.yaml"),
-		configs.HelmDeploymentYaml,
-		ports.ReadWrite,
-	)
-	if err != nil {
+	if err := d.BuildDevProxy(); err != nil {
 		return err
 	}
 
-	return nil
+	return d.InstallDevProxy()
 }
 
 // BuildDevProxy builds the HAProxy and mitmproxy Docker images for the dev-proxy.
@@ -137,13 +184,13 @@ func (d *DevProxyManager) BuildDevProxy() error {
 	}
 	dockerImages := []domain.DockerImage{
 		{
-			Name:                     fmt.Sprintf("henriq/haproxy-%s", configContext.Name),
+			Name:                     DevProxyHAProxyImage(configContext.Name),
 			DockerfilePath:           "Dockerfile",
 			BuildContextRelativePath: ".",
 			Path:                     filepath.Join(homeDir, ".dx", configContext.Name, "dev-proxy", "haproxy"),
 		},
 		{
-			Name:                     fmt.Sprintf("henriq/mitmproxy-%s", configContext.Name),
+			Name:                     DevProxyMitmproxyImage(configContext.Name),
 			DockerfilePath:           "Dockerfile",
 			BuildContextRelativePath: ".",
 			Path:                     filepath.Join(homeDir, ".dx", configContext.Name, "dev-proxy", "mitmproxy"),
@@ -172,12 +219,51 @@ func (d *DevProxyManager) InstallDevProxy() error {
 	}
 
 	service := domain.Service{
-		Name:     "dev-proxy",
+		Name:     DevProxyServiceName,
 		HelmPath: filepath.Join(homeDir, ".dx", configContext.Name, "dev-proxy", "helm"),
 	}
 	return d.containerOrchestrator.InstallDevProxy(&service)
 }
 
+// RenderDevProxy generates the dev-proxy files and renders its chart without contacting the cluster.
+// The files are written to $HOME/.dx/$CONTEXT_NAME/dry-run/dev-proxy/ rather than next to the
+// deployed configuration, so 'dx proxy diff' keeps comparing against what is deployed.
+// Returns the generated files and the rendered manifests.
+func (d *DevProxyManager) RenderDevProxy() ([]DevProxyConfigFile, []byte, error) {
+	configContext, err := d.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	homeDir, err := d.fileService.HomeDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configs, err := d.configGenerator.Generate(configContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	basePath := filepath.Join("~", ".dx", configContext.Name, "dry-run", "dev-proxy")
+	files := configs.Files()
+	for _, file := range files {
+		err = d.fileService.WriteFile(filepath.Join(basePath, file.RelativePath), file.Content, file.AccessMode)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	service := domain.Service{
+		Name:     DevProxyServiceName,
+		HelmPath: filepath.Join(homeDir, ".dx", configContext.Name, "dry-run", "dev-proxy", "helm"),
+	}
+	manifests, err := d.containerOrchestrator.RenderDevProxy(&service)
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, manifests, nil
+}
+
 // UninstallDevProxy removes the dev-proxy service from Kubernetes.
 func (d *DevProxyManager) UninstallDevProxy() error {
 	configContext, err := d.configRepository.LoadCurrentConfigurationContext()
@@ -190,7 +276,7 @@ func (d *DevProxyManager) UninstallDevProxy() error {
 	}
 
 	service := domain.Service{
-		Name:     "dev-proxy",
+		Name:     DevProxyServiceName,
 		HelmPath: filepath.Join(homeDir, ".dx", configContext.Name, "dev-proxy", "helm"),
 	}
 	return d.containerOrchestrator.UninstallService(&service)
@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"dx/internal/core/domain"
@@ -582,7 +583,8 @@ func TestShouldRebuildDevProxy_ChecksumUnchanged(t *testing.T) {
 
 	configContext := createTestConfigContext()
 	// Generate the expected checksum from the test config context
-	expectedChecksum := configGenerator.GenerateChecksum(configContext)
+	expectedChecksum, err := configGenerator.GenerateChecksum(configContext)
+	assert.NoError(t, err)
 
 	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
 	containerOrchestrator.On("GetDevProxyChecksum").Return(expectedChecksum, nil)
@@ -640,3 +642,142 @@ func TestShouldRebuildDevProxy_GetChecksumError(t *testing.T) {
 	configRepository.AssertExpectations(t)
 	containerOrchestrator.AssertExpectations(t)
 }
+
+func TestDiff_ReportsOnlyChangedFiles(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	fileSystem := new(testutil.MockFileSystem)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	configGenerator := ProvideDevProxyConfigGenerator()
+
+	configContext := createTestConfigContext()
+	configs, err := configGenerator.Generate(configContext)
+	assert.NoError(t, err)
+	generatedChecksum, err := configGenerator.GenerateChecksum(configContext)
+	assert.NoError(t, err)
+
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	containerOrchestrator.On("GetDevProxyChecksum").Return("old-checksum", nil)
+	for _, file := range configs.Files() {
+		path := filepath.Join("~", ".dx", "test-context", "dev-proxy", file.RelativePath)
+		switch file.RelativePath {
+		case filepath.Join("haproxy", "haproxy.cfg"):
+			fileSystem.On("FileExists", path).Return(true, nil)
+			fileSystem.On("ReadFile", path).Return([]byte("stale config\n"), nil)
+		case filepath.Join("mitmproxy", "Dockerfile"):
+			fileSystem.On("FileExists", path).Return(false, nil)
+		default:
+			fileSystem.On("FileExists", path).Return(true, nil)
+			fileSystem.On("ReadFile", path).Return(file.Content, nil)
+		}
+	}
+
+	sut := ProvideDevProxyManager(configRepository, fileSystem, containerImageRepository, containerOrchestrator, configGenerator)
+
+	diff, err := sut.Diff()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "old-checksum", diff.DeployedChecksum)
+	assert.Equal(t, generatedChecksum, diff.GeneratedChecksum)
+	assert.Len(t, diff.Files, 2)
+	assert.Equal(t, "~/.dx/test-context/dev-proxy/haproxy/haproxy.cfg", diff.Files[0].Path)
+	assert.Equal(t, []byte("stale config\n"), diff.Files[0].Saved)
+	assert.Equal(t, configs.HAProxyConfig, diff.Files[0].Generated)
+	assert.Equal(t, "~/.dx/test-context/dev-proxy/mitmproxy/Dockerfile", diff.Files[1].Path)
+	assert.Nil(t, diff.Files[1].Saved)
+	fileSystem.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything, mock.Anything)
+	fileSystem.AssertExpectations(t)
+	containerOrchestrator.AssertExpectations(t)
+}
+
+func TestDiff_GetChecksumError(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	fileSystem := new(testutil.MockFileSystem)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	configGenerator := ProvideDevProxyConfigGenerator()
+
+	configRepository.On("LoadCurrentConfigurationContext").Return(createTestConfigContext(), nil)
+	containerOrchestrator.On("GetDevProxyChecksum").Return("", errors.New("kubernetes api error"))
+
+	sut := ProvideDevProxyManager(configRepository, fileSystem, containerImageRepository, containerOrchestrator, configGenerator)
+
+	diff, err := sut.Diff()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get current dev-proxy checksum")
+	assert.Nil(t, diff)
+}
+
+func TestRebuild_SavesBuildsAndInstalls(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	fileSystem := new(testutil.MockFileSystem)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	configGenerator := ProvideDevProxyConfigGenerator()
+
+	configRepository.On("LoadCurrentConfigurationContext").Return(createTestConfigContext(), nil)
+	fileSystem.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	fileSystem.On("HomeDir").Return("/home/test", nil)
+	containerImageRepository.On("BuildImage", mock.Anything).Return(nil)
+	containerOrchestrator.On("InstallDevProxy", mock.Anything).Return(nil)
+
+	sut := ProvideDevProxyManager(configRepository, fileSystem, containerImageRepository, containerOrchestrator, configGenerator)
+
+	err := sut.Rebuild()
+
+	assert.NoError(t, err)
+	fileSystem.AssertNumberOfCalls(t, "WriteFile", 5)
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 2)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallDevProxy", 1)
+}
+
+func TestRebuild_StopsOnBuildError(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	fileSystem := new(testutil.MockFileSystem)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	configGenerator := ProvideDevProxyConfigGenerator()
+	buildErr := errors.New("build error")
+
+	configRepository.On("LoadCurrentConfigurationContext").Return(createTestConfigContext(), nil)
+	fileSystem.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	fileSystem.On("HomeDir").Return("/home/test", nil)
+	containerImageRepository.On("BuildImage", mock.Anything).Return(buildErr)
+
+	sut := ProvideDevProxyManager(configRepository, fileSystem, containerImageRepository, containerOrchestrator, configGenerator)
+
+	err := sut.Rebuild()
+
+	assert.ErrorIs(t, err, buildErr)
+	containerOrchestrator.AssertNotCalled(t, "InstallDevProxy", mock.Anything)
+}
+
+func TestRenderDevProxy_RendersChartOutsideDeployedConfiguration(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	fileSystem := new(testutil.MockFileSystem)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	configGenerator := ProvideDevProxyConfigGenerator()
+	homeDir := "/home/testuser"
+	configRepository.On("LoadCurrentConfigurationContext").Return(createTestConfigContext(), nil)
+	fileSystem.On("HomeDir").Return(homeDir, nil)
+	fileSystem.On("WriteFile", mock.MatchedBy(func(path string) bool {
+		return strings.HasPrefix(path, "~/.dx/test-context/dry-run/dev-proxy/")
+	}), mock.Anything, mock.Anything).Return(nil)
+	containerOrchestrator.On("RenderDevProxy", &domain.Service{
+		Name:     "dev-proxy",
+		HelmPath: filepath.Join(homeDir, ".dx", "test-context", "dry-run", "dev-proxy", "helm"),
+	}).Return([]byte("kind: Deployment\n"), nil)
+
+	sut := ProvideDevProxyManager(configRepository, fileSystem, containerImageRepository, containerOrchestrator, configGenerator)
+
+	files, manifests, err := sut.RenderDevProxy()
+
+	assert.NoError(t, err)
+	assert.Len(t, files, 5)
+	assert.Equal(t, "kind: Deployment\n", string(manifests))
+	fileSystem.AssertNumberOfCalls(t, "WriteFile", 5)
+	containerOrchestrator.AssertExpectations(t)
+	containerOrchestrator.AssertNotCalled(t, "GetDevProxyChecksum")
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// stagePattern matches Dockerfile stage names
+	stagePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
+	// platformPattern matches a single os/arch[/variant] platform
+	platformPattern = regexp.MustCompile(`^[a-z0-9_]+/[a-z0-9_]+(/[a-z0-9_]+)?$`)
+	// sshPattern matches an SSH forwarding spec: an ID, optionally followed by sockets or keys
+	sshPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+(=[^,=]+(,[^,=]+)*)?$`)
+	// secretIDPattern matches BuildKit secret IDs
+	secretIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	// BuildNetworks lists the network modes supported for RUN instructions
+	BuildNetworks = []string{"default", "host", "none"}
+)
+
+// isTemplated reports whether the value is rendered before use, so it can only be validated then.
+func isTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// validateBuildOptions checks the BuildKit options of the image. The returned error completes
+// a sentence starting with the name of the image.
+func (img *DockerImage) validateBuildOptions() error {
+	if img.Target != "" && !isTemplated(img.Target) && !stagePattern.MatchString(img.Target) {
+		return fmt.Errorf("has invalid target '%s' (must be a Dockerfile stage name)", img.Target)
+	}
+	if img.Platform != "" && !isTemplated(img.Platform) {
+		for _, platform := range strings.Split(img.Platform, ",") {
+			if !platformPattern.MatchString(strings.TrimSpace(platform)) {
+				return fmt.Errorf("has invalid platform '%s' (must be os/arch[/variant], comma-separated)", img.Platform)
+			}
+		}
+	}
+	for _, cache := range img.CacheFrom {
+		if strings.TrimSpace(cache) == "" {
+			return fmt.Errorf("has an empty cacheFrom entry")
+		}
+	}
+	for _, cache := range img.CacheTo {
+		if !isTemplated(cache) && !strings.HasPrefix(cache, "type=") && !strings.Contains(cache, ",type=") {
+			return fmt.Errorf("has invalid cacheTo '%s' (must include type=, e.g. type=local,dest=/tmp/cache)", cache)
+		}
+	}
+	for key := range img.Labels {
+		if strings.TrimSpace(key) == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("has invalid label key '%s'", key)
+		}
+	}
+	for _, ssh := range img.SSH {
+		if !isTemplated(ssh) && !sshPattern.MatchString(ssh) {
+			return fmt.Errorf("has invalid ssh '%s' (must be an ID, optionally followed by =<socket or key>)", ssh)
+		}
+	}
+	if img.Network != "" && !isTemplated(img.Network) && !slices.Contains(BuildNetworks, img.Network) {
+		return fmt.Errorf(
+			"has invalid network '%s' (must be one of %s)",
+			img.Network,
+			strings.Join(BuildNetworks, ", "),
+		)
+	}
+	for id, key := range img.BuildSecrets {
+		if !secretIDPattern.MatchString(id) {
+			return fmt.Errorf("has invalid build secret id '%s' (must contain only letters, digits, '_', '.' and '-')", id)
+		}
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("has build secret '%s' without a secret key", id)
+		}
+	}
+	return nil
+}
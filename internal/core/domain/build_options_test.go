@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerImage_validateBuildOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   DockerImage
+		wantErr string
+	}{
+		{"no options", DockerImage{}, ""},
+		{"target", DockerImage{Target: "build-env"}, ""},
+		{"invalid target", DockerImage{Target: "dev stage"}, "has invalid target 'dev stage'"},
+		{"templated target", DockerImage{Target: "{{.Secrets.STAGE}}"}, ""},
+		{"platforms", DockerImage{Platform: "linux/amd64, linux/arm64/v8"}, ""},
+		{"invalid platform", DockerImage{Platform: "amd64"}, "has invalid platform 'amd64'"},
+		{"cache", DockerImage{CacheFrom: []string{"api:cache"}, CacheTo: []string{"type=inline"}}, ""},
+		{"empty cacheFrom", DockerImage{CacheFrom: []string{" "}}, "has an empty cacheFrom entry"},
+		{"cacheTo without type", DockerImage{CacheTo: []string{"dest=/tmp/cache"}}, "has invalid cacheTo 'dest=/tmp/cache'"},
+		{"labels", DockerImage{Labels: map[string]string{"org.opencontainers.image.source": "x"}}, ""},
+		{"invalid label key", DockerImage{Labels: map[string]string{"a=b": "c"}}, "has invalid label key 'a=b'"},
+		{"ssh", DockerImage{SSH: []string{"default", "github=/keys/a,/keys/b"}}, ""},
+		{"invalid ssh", DockerImage{SSH: []string{"=/keys/a"}}, "has invalid ssh '=/keys/a'"},
+		{"network", DockerImage{Network: "host"}, ""},
+		{"build secrets", DockerImage{BuildSecrets: map[string]string{"npm_token": "NPM_TOKEN"}}, ""},
+		{"invalid build secret id", DockerImage{BuildSecrets: map[string]string{"npm,env=X": "NPM_TOKEN"}}, "has invalid build secret id"},
+		{"build secret without key", DockerImage{BuildSecrets: map[string]string{"npm": ""}}, "has build secret 'npm' without a secret key"},
+		{"invalid network", DockerImage{Network: "bridge"}, "has invalid network 'bridge' (must be one of default, host, none)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.image.validateBuildOptions()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_BuildOptions(t *testing.T) {
+	config := Config{
+		Contexts: []ConfigurationContext{
+			{
+				Name: "my-context",
+				Services: []Service{
+					{
+						Name:                  "api",
+						HelmRepoPath:          "any-repo",
+						HelmBranch:            "any-branch",
+						HelmChartRelativePath: "any-chart",
+						DockerImages: []DockerImage{
+							{
+								Name:                     "api",
+								DockerfilePath:           "Dockerfile",
+								BuildContextRelativePath: ".",
+								LocalPath:                "/src/api",
+								Network:                  "bridge",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.EqualError(
+		t,
+		config.Validate(),
+		"docker image 'api' for service 'api' in context 'my-context' has invalid network 'bridge' (must be one of default, host, none)",
+	)
+}
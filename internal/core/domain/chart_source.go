@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// UsesChartRepository reports whether the chart of the service is pulled from a helm repository or an
+// OCI registry rather than cloned from a git repository.
+func (s *Service) UsesChartRepository() bool {
+	return s.HelmChart != ""
+}
+
+// IsOCIChart reports whether the chart of the service is an oci:// reference.
+func (s *Service) IsOCIChart() bool {
+	return strings.HasPrefix(s.HelmChart, "oci://")
+}
+
+// ChartName returns the name of the chart pulled from a helm repository or an OCI registry, which is
+// the directory helm unpacks it to.
+func (s *Service) ChartName() string {
+	return path.Base(strings.TrimPrefix(s.HelmChart, "oci://"))
+}
+
+// validateChartSource checks that the service has either a git repository or a helm chart reference.
+// The returned error completes a sentence starting with the service.
+func (s *Service) validateChartSource() error {
+	if !s.UsesChartRepository() {
+		if s.HelmChartRepoURL != "" || s.HelmChartVersion != "" {
+			return fmt.Errorf("has helmChartRepoUrl or helmChartVersion but no helmChart")
+		}
+		if s.HelmRepoPath == "" {
+			return fmt.Errorf("has empty helmPath")
+		}
+		if s.HelmBranch == "" {
+			return fmt.Errorf("has empty helmBranch")
+		}
+		if s.HelmChartRelativePath == "" {
+			return fmt.Errorf("has empty helmChartRelativePath")
+		}
+		return nil
+	}
+
+	if s.HelmRepoPath != "" || s.HelmBranch != "" {
+		return fmt.Errorf("has both helmChart and helmRepoPath or helmBranch (must have one)")
+	}
+	if s.IsOCIChart() {
+		if s.HelmChartRepoURL != "" {
+			return fmt.Errorf("has oci:// helmChart '%s' and helmChartRepoUrl (must have one)", s.HelmChart)
+		}
+	} else {
+		if s.HelmChartRepoURL == "" {
+			return fmt.Errorf("has helmChart '%s' without helmChartRepoUrl (required unless it is an oci:// reference)", s.HelmChart)
+		}
+		if strings.Contains(s.HelmChart, "/") {
+			return fmt.Errorf("has invalid helmChart '%s' (must be a chart name in helmChartRepoUrl or an oci:// reference)", s.HelmChart)
+		}
+	}
+	if name := s.ChartName(); name == "" || name == "." || name == "/" {
+		return fmt.Errorf("has invalid helmChart '%s'", s.HelmChart)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_validateChartSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{"git", Service{HelmRepoPath: "any-repo", HelmBranch: "main", HelmChartRelativePath: "charts/api"}, ""},
+		{"git without branch", Service{HelmRepoPath: "any-repo", HelmChartRelativePath: "charts/api"}, "has empty helmBranch"},
+		{"repository", Service{HelmChart: "postgresql", HelmChartRepoURL: "https://charts.bitnami.com/bitnami", HelmChartVersion: "15.5.0"}, ""},
+		{"oci", Service{HelmChart: "oci://ghcr.io/acme/charts/kafka"}, ""},
+		{"repository without url", Service{HelmChart: "postgresql"}, "has helmChart 'postgresql' without helmChartRepoUrl"},
+		{"oci with url", Service{HelmChart: "oci://ghcr.io/acme/kafka", HelmChartRepoURL: "https://charts.example.com"}, "has oci:// helmChart 'oci://ghcr.io/acme/kafka' and helmChartRepoUrl"},
+		{"repository alias", Service{HelmChart: "bitnami/postgresql", HelmChartRepoURL: "https://charts.bitnami.com/bitnami"}, "has invalid helmChart 'bitnami/postgresql'"},
+		{"chart and git", Service{HelmChart: "oci://ghcr.io/acme/kafka", HelmRepoPath: "any-repo"}, "has both helmChart and helmRepoPath or helmBranch"},
+		{"version without chart", Service{HelmRepoPath: "any-repo", HelmBranch: "main", HelmChartRelativePath: "api", HelmChartVersion: "1.0.0"}, "has helmChartRepoUrl or helmChartVersion but no helmChart"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.service.validateChartSource()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_ChartName(t *testing.T) {
+	assert.Equal(t, "kafka", (&Service{HelmChart: "oci://ghcr.io/acme/charts/kafka"}).ChartName())
+	assert.Equal(t, "postgresql", (&Service{HelmChart: "postgresql"}).ChartName())
+}
@@ -2,32 +2,116 @@ package domain
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 )
 
+// labelPattern matches lowercase RFC 1123 labels, as required for Kubernetes namespaces, Helm
+// release names and label values.
+var labelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func isValidLabel(name string) bool {
+	return len(name) <= 63 && labelPattern.MatchString(name)
+}
+
+// maxReleaseNameLength is the maximum length of a Helm release name.
+const maxReleaseNameLength = 53
+
+// releaseNameLength returns the length of the Helm release name of a service in a context,
+// which is "<context>-<service>".
+func releaseNameLength(contextName, serviceName string) int {
+	return len(contextName) + 1 + len(serviceName)
+}
+
 type ConfigurationContext struct {
-	Name          string            `yaml:"name"`
-	Scripts       map[string]string `yaml:"scripts"`
-	Import        *string           `yaml:"import,omitempty"`
-	Services      []Service         `yaml:"services"`
-	LocalServices []LocalService    `yaml:"localServices,omitempty"`
+	Name    string            `yaml:"name"`
+	Scripts map[string]string `yaml:"scripts"`
+	Import  *string           `yaml:"import,omitempty"`
+	// Namespace is the Kubernetes namespace services are deployed to.
+	// Defaults to the namespace of the kube context.
+	Namespace string `yaml:"namespace,omitempty"`
+	// KubeContext is the kubeconfig context used for all cluster operations.
+	// Defaults to the current kubeconfig context.
+	KubeContext string `yaml:"kubeContext,omitempty"`
+	// Builder is the CLI images are built and pulled with. Defaults to docker.
+	Builder string `yaml:"builder,omitempty"`
+	// ImageLoader makes built and pulled images available to the cluster. Defaults to none, for
+	// clusters that use the image store of the builder.
+	ImageLoader string `yaml:"imageLoader,omitempty"`
+	// ImageRegistry is the registry images are pushed to when ImageLoader is registry, e.g. localhost:5001.
+	ImageRegistry string         `yaml:"imageRegistry,omitempty"`
+	Services      []Service      `yaml:"services"`
+	LocalServices []LocalService `yaml:"localServices,omitempty"`
+}
+
+// Supported container image builders
+const (
+	BuilderDocker  = "docker"
+	BuilderPodman  = "podman"
+	BuilderNerdctl = "nerdctl"
+	BuilderBuildah = "buildah"
+)
+
+// Builders lists the supported container image builders
+var Builders = []string{BuilderDocker, BuilderPodman, BuilderNerdctl, BuilderBuildah}
+
+// ActiveBuilder returns the builder images are built with in this context.
+func (c *ConfigurationContext) ActiveBuilder() string {
+	if c.Builder == "" {
+		return BuilderDocker
+	}
+	return c.Builder
+}
+
+// Supported image loaders
+const (
+	ImageLoaderNone     = "none"
+	ImageLoaderKind     = "kind"
+	ImageLoaderMinikube = "minikube"
+	ImageLoaderK3d      = "k3d"
+	ImageLoaderRegistry = "registry"
+)
+
+// ImageLoaders lists the supported image loaders
+var ImageLoaders = []string{ImageLoaderNone, ImageLoaderKind, ImageLoaderMinikube, ImageLoaderK3d, ImageLoaderRegistry}
+
+// ActiveImageLoader returns the loader images are made available to the cluster with in this context.
+func (c *ConfigurationContext) ActiveImageLoader() string {
+	if c.ImageLoader == "" {
+		return ImageLoaderNone
+	}
+	return c.ImageLoader
+}
+
+// RegistryImage returns the reference an image is pushed to when images are loaded into a registry.
+func (c *ConfigurationContext) RegistryImage(image string) string {
+	return strings.TrimSuffix(c.ImageRegistry, "/") + "/" + image
 }
 
 // Service represents a deployable service with its Docker configuration
 type Service struct {
-	Name                  string        `yaml:"name"`
-	HelmRepoPath          string        `yaml:"helmRepoPath"`
-	HelmPath              string        `yaml:"-"` // Will be ignored during YAML serialization
-	HelmChartRelativePath string        `yaml:"helmChartRelativePath"`
-	HelmBranch            string        `yaml:"helmBranch"`
-	HelmArgs              []string      `yaml:"helmArgs"`
-	LocalPort             *int          `yaml:"localPort,omitempty"` // Using pointer to make nullable
-	DockerImages          []DockerImage `yaml:"dockerImages"`
-	RemoteImages          []string      `yaml:"remoteImages"`
-	Profiles              []string      `yaml:"profiles,omitempty"`
-	GitRepoPath           string        `yaml:"gitRepoPath"`
-	GitRef                string        `yaml:"gitRef"`
-	Path                  string        `yaml:"-"` // Will be ignored during YAML serialization
+	Name                  string                 `yaml:"name"`
+	HelmRepoPath          string                 `yaml:"helmRepoPath"`
+	HelmPath              string                 `yaml:"-"` // Will be ignored during YAML serialization
+	HelmChartRelativePath string                 `yaml:"helmChartRelativePath"`
+	HelmBranch            string                 `yaml:"helmBranch"`
+	HelmArgs              []string               `yaml:"helmArgs"`
+	HelmChart             string                 `yaml:"helmChart,omitempty"`        // Chart pulled from a helm repository or an oci:// reference, instead of git
+	HelmChartRepoURL      string                 `yaml:"helmChartRepoUrl,omitempty"` // Helm repository of HelmChart, unless it is an OCI reference
+	HelmChartVersion      string                 `yaml:"helmChartVersion,omitempty"` // Latest version if empty
+	HelmValuesFiles       []string               `yaml:"helmValuesFiles,omitempty"`  // Relative to the chart repo or absolute
+	HelmValues            map[string]interface{} `yaml:"helmValues,omitempty"`       // Take precedence over the values files
+	LocalPort             *int                   `yaml:"localPort,omitempty"`        // Using pointer to make nullable
+	DockerImages          []DockerImage          `yaml:"dockerImages"`
+	RemoteImages          []string               `yaml:"remoteImages"`
+	Profiles              []string               `yaml:"profiles,omitempty"`
+	DependsOn             []string               `yaml:"dependsOn,omitempty"` // Services installed before this one
+	Patches               []ServicePatch         `yaml:"patches,omitempty"`   // Applied to the rendered manifests after dx's own patches
+	GitRepoPath           string                 `yaml:"gitRepoPath"`
+	GitRef                string                 `yaml:"gitRef"`
+	Path                  string                 `yaml:"-"` // Will be ignored during YAML serialization
 }
 
 type DockerImage struct {
@@ -36,9 +120,21 @@ type DockerImage struct {
 	DockerfileOverride       string   `yaml:"dockerfileOverride,omitempty"`
 	BuildContextRelativePath string   `yaml:"buildContextRelativePath"`
 	BuildArgs                []string `yaml:"buildArgs"`
-	GitRepoPath              string   `yaml:"gitRepoPath"`
-	GitRef                   string   `yaml:"gitRef"`
-	Path                     string   `yaml:"-"` // Will be ignored during YAML serialization
+	// BuildKit options. Like build args, their values support templating.
+	Target    string            `yaml:"target,omitempty"`    // Dockerfile stage to build
+	Platform  string            `yaml:"platform,omitempty"`  // Target platforms, e.g. linux/amd64,linux/arm64
+	CacheFrom []string          `yaml:"cacheFrom,omitempty"` // External cache sources, e.g. type=registry,ref=...
+	CacheTo   []string          `yaml:"cacheTo,omitempty"`   // Cache export destinations, e.g. type=local,dest=...
+	Labels    map[string]string `yaml:"labels,omitempty"`    // Labels added to the image
+	SSH       []string          `yaml:"ssh,omitempty"`       // SSH agent sockets or keys to forward, e.g. default
+	Network   string            `yaml:"network,omitempty"`   // Network mode of RUN instructions: default, host or none
+	// BuildSecrets maps BuildKit secret IDs to the keys of the secrets mounted under them
+	BuildSecrets map[string]string `yaml:"buildSecrets,omitempty"`
+
+	GitRepoPath string `yaml:"gitRepoPath"`
+	GitRef      string `yaml:"gitRef"`
+	LocalPath   string `yaml:"localPath,omitempty"` // Builds from a local checkout instead of gitRepoPath
+	Path        string `yaml:"-"`                   // Will be ignored during YAML serialization
 }
 
 type LocalService struct {
@@ -122,6 +218,84 @@ func (c *ConfigurationContext) GetService(name string) *Service {
 	return nil
 }
 
+// validateServiceDependencies checks that services only depend on other services of the context and
+// that the dependencies don't form a cycle.
+func validateServiceDependencies(ctx *ConfigurationContext) error {
+	for _, svc := range ctx.Services {
+		for _, dependency := range svc.DependsOn {
+			if dependency == svc.Name {
+				return fmt.Errorf("service '%s' in context '%s' depends on itself", svc.Name, ctx.Name)
+			}
+			if ctx.GetService(dependency) == nil {
+				return fmt.Errorf(
+					"service '%s' in context '%s' depends on unknown service '%s'",
+					svc.Name,
+					ctx.Name,
+					dependency,
+				)
+			}
+		}
+	}
+
+	if cycle := findDependencyCycle(ctx.Services); cycle != nil {
+		return fmt.Errorf(
+			"services in context '%s' have a dependency cycle: %s",
+			ctx.Name,
+			strings.Join(cycle, " -> "),
+		)
+	}
+	return nil
+}
+
+// findDependencyCycle returns the services of a dependency cycle, starting and ending with the same
+// service, or nil if there is none. Unknown dependencies are ignored.
+func findDependencyCycle(services []Service) []string {
+	dependencies := make(map[string][]string, len(services))
+	for _, svc := range services {
+		dependencies[svc.Name] = svc.DependsOn
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(services))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			return append(slices.Clone(path[start:]), name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			if _, ok := dependencies[dependency]; !ok {
+				continue
+			}
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, svc := range services {
+		if cycle := visit(svc.Name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
 func (c *Config) Validate() error {
 	for i, ctx := range c.Contexts {
 		if ctx.Name == "" {
@@ -134,19 +308,79 @@ func (c *Config) Validate() error {
 			strings.Contains(ctx.Name, "\x00") {
 			return fmt.Errorf("context '%s' contains invalid characters (path traversal not allowed)", ctx.Name)
 		}
+		// Context names go into release names and the dx-context label
+		if !isValidLabel(ctx.Name) {
+			return fmt.Errorf("context '%s' has an invalid name (must be a lowercase RFC 1123 label)", ctx.Name)
+		}
+		if ctx.Namespace != "" && !isValidLabel(ctx.Namespace) {
+			return fmt.Errorf(
+				"context '%s' has invalid namespace '%s' (must be a lowercase RFC 1123 label)",
+				ctx.Name,
+				ctx.Namespace,
+			)
+		}
+		if ctx.Builder != "" && !slices.Contains(Builders, ctx.Builder) {
+			return fmt.Errorf(
+				"context '%s' has invalid builder '%s' (must be one of %s)",
+				ctx.Name,
+				ctx.Builder,
+				strings.Join(Builders, ", "),
+			)
+		}
+		if ctx.ImageLoader != "" && !slices.Contains(ImageLoaders, ctx.ImageLoader) {
+			return fmt.Errorf(
+				"context '%s' has invalid imageLoader '%s' (must be one of %s)",
+				ctx.Name,
+				ctx.ImageLoader,
+				strings.Join(ImageLoaders, ", "),
+			)
+		}
+		if ctx.ImageLoader == ImageLoaderRegistry && ctx.ImageRegistry == "" {
+			return fmt.Errorf("context '%s' has imageLoader 'registry' but no imageRegistry", ctx.Name)
+		}
+		// The dev-proxy is installed as the release "<context>-dev-proxy", and its Deployment and
+		// Service are named "dev-proxy-<context>"
+		if releaseNameLength(ctx.Name, "dev-proxy") > maxReleaseNameLength {
+			return fmt.Errorf(
+				"context '%s' has a name that is too long (release names must not exceed %d characters)",
+				ctx.Name,
+				maxReleaseNameLength,
+			)
+		}
 
 		for j, svc := range ctx.Services {
 			if svc.Name == "" {
 				return fmt.Errorf("service at index %d in context '%s' has empty name", j, ctx.Name)
 			}
-			if svc.HelmRepoPath == "" {
-				return fmt.Errorf("service '%s' in context '%s' has empty helmPath", svc.Name, ctx.Name)
+			if !isValidLabel(svc.Name) {
+				return fmt.Errorf(
+					"service '%s' in context '%s' has an invalid name (must be a lowercase RFC 1123 label)",
+					svc.Name,
+					ctx.Name,
+				)
+			}
+			if releaseNameLength(ctx.Name, svc.Name) > maxReleaseNameLength {
+				return fmt.Errorf(
+					"service '%s' in context '%s' has a release name longer than %d characters",
+					svc.Name,
+					ctx.Name,
+					maxReleaseNameLength,
+				)
+			}
+			if err := svc.validateChartSource(); err != nil {
+				return fmt.Errorf("service '%s' in context '%s' %w", svc.Name, ctx.Name, err)
 			}
-			if svc.HelmBranch == "" {
-				return fmt.Errorf("service '%s' in context '%s' has empty helmBranch", svc.Name, ctx.Name)
+
+			for _, valuesFile := range svc.HelmValuesFiles {
+				if strings.TrimSpace(valuesFile) == "" {
+					return fmt.Errorf("service '%s' in context '%s' has an empty helmValuesFiles entry", svc.Name, ctx.Name)
+				}
 			}
-			if svc.HelmChartRelativePath == "" {
-				return fmt.Errorf("service '%s' in context '%s' has empty helmChartRelativePath", svc.Name, ctx.Name)
+
+			for k, patch := range svc.Patches {
+				if err := patch.validate(); err != nil {
+					return fmt.Errorf("patch %d of service '%s' in context '%s' %w", k, svc.Name, ctx.Name, err)
+				}
 			}
 
 			for k, img := range svc.DockerImages {
@@ -174,6 +408,27 @@ func (c *Config) Validate() error {
 						ctx.Name,
 					)
 				}
+				if err := img.validateBuildOptions(); err != nil {
+					return fmt.Errorf(
+						"docker image '%s' for service '%s' in context '%s' %w",
+						img.Name,
+						svc.Name,
+						ctx.Name,
+						err,
+					)
+				}
+				if img.LocalPath != "" {
+					if !filepath.IsAbs(img.LocalPath) && !strings.HasPrefix(img.LocalPath, "~") {
+						return fmt.Errorf(
+							"docker image '%s' for service '%s' in context '%s' has localPath '%s', which must be absolute or start with ~",
+							img.Name,
+							svc.Name,
+							ctx.Name,
+							img.LocalPath,
+						)
+					}
+					continue
+				}
 				if img.GitRepoPath == "" {
 					return fmt.Errorf(
 						"docker image '%s' for service '%s' in context '%s' has empty gitRepoPath",
@@ -205,6 +460,10 @@ func (c *Config) Validate() error {
 			}
 		}
 
+		if err := validateServiceDependencies(&ctx); err != nil {
+			return err
+		}
+
 		// Validate LocalServices
 		for j, localSvc := range ctx.LocalServices {
 			if localSvc.Name == "" {
@@ -231,5 +490,27 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("no contexts defined in configuration")
 	}
 
+	return c.validateNamespaces()
+}
+
+// validateNamespaces rejects contexts that deploy to the same configured namespace of the same kube
+// context. Charts are rendered with the service name as the release name, so the resources of a
+// service deployed by both contexts would collide.
+func (c *Config) validateNamespaces() error {
+	for i, ctx := range c.Contexts {
+		if ctx.Namespace == "" {
+			continue
+		}
+		for _, other := range c.Contexts[:i] {
+			if other.Namespace == ctx.Namespace && other.KubeContext == ctx.KubeContext {
+				return fmt.Errorf(
+					"contexts '%s' and '%s' both deploy to namespace '%s' (each context needs its own namespace)",
+					other.Name,
+					ctx.Name,
+					ctx.Namespace,
+				)
+			}
+		}
+	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -108,3 +109,313 @@ func TestConfig_Validate_ContextNamePathTraversal(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_Validate_Namespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		wantErr   bool
+	}{
+		{"not set", "", false},
+		{"valid namespace", "my-namespace", false},
+		{"uppercase", "MyNamespace", true},
+		{"underscore", "my_namespace", true},
+		{"leading dash", "-namespace", true},
+		{"too long", strings.Repeat("a", 64), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{
+				Contexts: []ConfigurationContext{
+					{Name: "my-context", Namespace: tt.namespace},
+				},
+			}
+
+			err := config.Validate()
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "invalid namespace")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ReleaseNameLength(t *testing.T) {
+	service := func(name string) Service {
+		return Service{
+			Name:                  name,
+			HelmRepoPath:          "any-repo",
+			HelmBranch:            "any-branch",
+			HelmChartRelativePath: "any-chart",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		contextName string
+		services    []Service
+		wantErr     string
+	}{
+		{"within limit", "my-context", []Service{service(strings.Repeat("a", 42))}, ""},
+		{
+			"service release name too long",
+			"my-context",
+			[]Service{service(strings.Repeat("a", 43))},
+			"has a release name longer than 53 characters",
+		},
+		{"context name within limit", strings.Repeat("c", 43), nil, ""},
+		{"context name too long", strings.Repeat("c", 44), nil, "has a name that is too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{
+				Contexts: []ConfigurationContext{
+					{Name: tt.contextName, Services: tt.services},
+				},
+			}
+
+			err := config.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Names(t *testing.T) {
+	tests := []struct {
+		name        string
+		contextName string
+		serviceName string
+		wantErr     string
+	}{
+		{"valid", "my-context", "api-2", ""},
+		{"uppercase context", "My-Context", "api", "context 'My-Context' has an invalid name"},
+		{"underscore in context", "my_ctx", "api", "context 'my_ctx' has an invalid name"},
+		{"uppercase service", "my-context", "Api", "service 'Api' in context 'my-context' has an invalid name"},
+		{"service with trailing dash", "my-context", "api-", "service 'api-' in context 'my-context' has an invalid name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{
+				Contexts: []ConfigurationContext{{
+					Name: tt.contextName,
+					Services: []Service{{
+						Name:                  tt.serviceName,
+						HelmRepoPath:          "any-repo",
+						HelmBranch:            "any-branch",
+						HelmChartRelativePath: "any-chart",
+					}},
+				}},
+			}
+
+			err := config.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_SharedNamespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		contexts []ConfigurationContext
+		wantErr  string
+	}{
+		{
+			"same namespace and kube context",
+			[]ConfigurationContext{{Name: "a", Namespace: "dev"}, {Name: "b", Namespace: "dev"}},
+			"contexts 'a' and 'b' both deploy to namespace 'dev'",
+		},
+		{
+			"same namespace in other kube contexts",
+			[]ConfigurationContext{{Name: "a", Namespace: "dev", KubeContext: "kind-a"}, {Name: "b", Namespace: "dev", KubeContext: "kind-b"}},
+			"",
+		},
+		{
+			"default namespaces",
+			[]ConfigurationContext{{Name: "a"}, {Name: "b"}},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Contexts: tt.contexts}
+
+			err := config.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_DependsOn(t *testing.T) {
+	service := func(name string, dependsOn ...string) Service {
+		return Service{
+			Name:                  name,
+			HelmRepoPath:          "any-repo",
+			HelmBranch:            "any-branch",
+			HelmChartRelativePath: "any-chart",
+			DependsOn:             dependsOn,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		services []Service
+		wantErr  string
+	}{
+		{"no dependencies", []Service{service("api"), service("db")}, ""},
+		{"valid dependencies", []Service{service("api", "db", "cache"), service("db"), service("cache", "db")}, ""},
+		{"unknown dependency", []Service{service("api", "db")}, "depends on unknown service 'db'"},
+		{"self dependency", []Service{service("api", "api")}, "depends on itself"},
+		{
+			"cycle",
+			[]Service{service("api", "worker"), service("worker", "db"), service("db", "api")},
+			"dependency cycle: api -> worker -> db -> api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{
+				Contexts: []ConfigurationContext{
+					{Name: "my-context", Services: tt.services},
+				},
+			}
+
+			err := config.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_HelmValuesFiles(t *testing.T) {
+	config := Config{
+		Contexts: []ConfigurationContext{
+			{
+				Name: "my-context",
+				Services: []Service{
+					{
+						Name:                  "api",
+						HelmRepoPath:          "any-repo",
+						HelmBranch:            "any-branch",
+						HelmChartRelativePath: "any-chart",
+						HelmValuesFiles:       []string{"values.yaml", " "},
+					},
+				},
+			},
+		},
+	}
+
+	assert.EqualError(t, config.Validate(), "service 'api' in context 'my-context' has an empty helmValuesFiles entry")
+}
+
+func TestConfig_Validate_LocalPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   DockerImage
+		wantErr string
+	}{
+		{"git source", DockerImage{GitRepoPath: "any-repo", GitRef: "main"}, ""},
+		{"absolute local path without git source", DockerImage{LocalPath: "/src/api"}, ""},
+		{"local path in home directory", DockerImage{LocalPath: "~/src/api"}, ""},
+		{"relative local path", DockerImage{LocalPath: "src/api"}, "must be absolute or start with ~"},
+		{"no source", DockerImage{}, "has empty gitRepoPath"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image := tt.image
+			image.Name = "api"
+			image.DockerfilePath = "Dockerfile"
+			image.BuildContextRelativePath = "."
+			config := Config{
+				Contexts: []ConfigurationContext{
+					{
+						Name: "my-context",
+						Services: []Service{
+							{
+								Name:                  "api",
+								HelmRepoPath:          "any-repo",
+								HelmBranch:            "any-branch",
+								HelmChartRelativePath: "any-chart",
+								DockerImages:          []DockerImage{image},
+							},
+						},
+					},
+				},
+			}
+
+			err := config.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Builder(t *testing.T) {
+	for _, builder := range append([]string{""}, Builders...) {
+		config := Config{Contexts: []ConfigurationContext{{Name: "my-context", Builder: builder}}}
+		assert.NoError(t, config.Validate(), builder)
+	}
+
+	config := Config{Contexts: []ConfigurationContext{{Name: "my-context", Builder: "kaniko"}}}
+	assert.EqualError(
+		t,
+		config.Validate(),
+		"context 'my-context' has invalid builder 'kaniko' (must be one of docker, podman, nerdctl, buildah)",
+	)
+}
+
+func TestConfigurationContext_ActiveBuilder(t *testing.T) {
+	assert.Equal(t, BuilderDocker, (&ConfigurationContext{}).ActiveBuilder())
+	assert.Equal(t, BuilderPodman, (&ConfigurationContext{Builder: BuilderPodman}).ActiveBuilder())
+}
+
+func TestConfig_Validate_ImageLoader(t *testing.T) {
+	for _, loader := range []string{"", ImageLoaderNone, ImageLoaderKind, ImageLoaderMinikube, ImageLoaderK3d} {
+		config := Config{Contexts: []ConfigurationContext{{Name: "my-context", ImageLoader: loader}}}
+		assert.NoError(t, config.Validate(), loader)
+	}
+
+	config := Config{Contexts: []ConfigurationContext{{Name: "my-context", ImageLoader: "microk8s"}}}
+	assert.EqualError(
+		t,
+		config.Validate(),
+		"context 'my-context' has invalid imageLoader 'microk8s' (must be one of none, kind, minikube, k3d, registry)",
+	)
+
+	config = Config{Contexts: []ConfigurationContext{{Name: "my-context", ImageLoader: ImageLoaderRegistry}}}
+	assert.EqualError(t, config.Validate(), "context 'my-context' has imageLoader 'registry' but no imageRegistry")
+
+	config.Contexts[0].ImageRegistry = "localhost:5001"
+	assert.NoError(t, config.Validate())
+}
+
+func TestConfigurationContext_RegistryImage(t *testing.T) {
+	configContext := ConfigurationContext{ImageRegistry: "localhost:5001/"}
+
+	assert.Equal(t, "localhost:5001/api:latest", configContext.RegistryImage("api:latest"))
+	assert.Equal(t, ImageLoaderNone, configContext.ActiveImageLoader())
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Deployment is an install of a service recorded in its deployment history.
+type Deployment struct {
+	Revision  int              `json:"revision"`
+	Timestamp time.Time        `json:"timestamp"`
+	Chart     SourceRevision   `json:"chart"`
+	Images    []SourceRevision `json:"images,omitempty"`
+	// ArgsHash identifies the rendered helm args and values the chart was templated with
+	ArgsHash string `json:"argsHash"`
+	// ManifestsChecksum identifies the patched manifests, see core.ManifestsChecksumAnnotation
+	ManifestsChecksum string `json:"manifestsChecksum"`
+	// WrapperChart is the absolute path of the generated wrapper chart that was installed
+	WrapperChart string `json:"wrapperChart"`
+	// RollbackOf is the revision this deployment reinstalled, or 0 if it wasn't a rollback
+	RollbackOf int `json:"rollbackOf,omitempty"`
+}
+
+// SourceRevision identifies the source a chart or image was deployed from.
+type SourceRevision struct {
+	Name string `json:"name"`
+	// Ref is the configured git ref, or the configured version of a pulled chart
+	Ref string `json:"ref,omitempty"`
+	// Revision is the checked out commit SHA, or the version of a pulled chart
+	Revision string `json:"revision,omitempty"`
+}
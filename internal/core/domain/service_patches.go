@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PatchOps lists the JSON patch (RFC 6902) operations supported in service patches
+var PatchOps = []string{"add", "remove", "replace", "move", "copy", "test"}
+
+// ServicePatch is a kustomize patch applied to the rendered manifests of a service, for changes its
+// chart's values don't expose. Either Operations or Patch is set. The values of operations and the
+// strategic merge patch support templating, e.g. {{ .Secrets.API_KEY }}.
+type ServicePatch struct {
+	Target     ServicePatchTarget      `yaml:"target"`
+	Operations []ServicePatchOperation `yaml:"operations,omitempty"` // JSON patch (RFC 6902) operations
+	Patch      string                  `yaml:"patch,omitempty"`      // Strategic merge patch as YAML
+}
+
+// ServicePatchTarget selects the resources a service patch applies to.
+type ServicePatchTarget struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name,omitempty"` // Empty matches all resources of the kind
+}
+
+// ServicePatchOperation is a JSON patch (RFC 6902) operation.
+type ServicePatchOperation struct {
+	Op    string      `yaml:"op"`
+	Path  string      `yaml:"path"`
+	From  string      `yaml:"from,omitempty"` // Source of move and copy operations
+	Value interface{} `yaml:"value,omitempty"`
+}
+
+// validate checks the patch. The returned error completes a sentence starting with the patch.
+func (p *ServicePatch) validate() error {
+	if strings.TrimSpace(p.Target.Kind) == "" {
+		return fmt.Errorf("has no target kind")
+	}
+	if len(p.Operations) > 0 && p.Patch != "" {
+		return fmt.Errorf("has both operations and patch (must have one)")
+	}
+	if len(p.Operations) == 0 && strings.TrimSpace(p.Patch) == "" {
+		return fmt.Errorf("has neither operations nor patch")
+	}
+
+	for i, op := range p.Operations {
+		if !slices.Contains(PatchOps, op.Op) {
+			return fmt.Errorf("has operation %d with invalid op '%s' (must be one of %s)", i, op.Op, strings.Join(PatchOps, ", "))
+		}
+		if !strings.HasPrefix(op.Path, "/") {
+			return fmt.Errorf("has operation %d with invalid path '%s' (must be a JSON pointer starting with /)", i, op.Path)
+		}
+		if (op.Op == "move" || op.Op == "copy") && !strings.HasPrefix(op.From, "/") {
+			return fmt.Errorf("has %s operation %d without a from path", op.Op, i)
+		}
+	}
+
+	if p.Patch != "" && !isTemplated(p.Patch) {
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(p.Patch), &content); err != nil {
+			return fmt.Errorf("has invalid patch (must be a YAML mapping): %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServicePatch_validate(t *testing.T) {
+	deployment := ServicePatchTarget{Kind: "Deployment", Name: "api"}
+	tests := []struct {
+		name    string
+		patch   ServicePatch
+		wantErr string
+	}{
+		{"operations", ServicePatch{Target: deployment, Operations: []ServicePatchOperation{{Op: "replace", Path: "/spec/replicas", Value: 2}}}, ""},
+		{"strategic merge", ServicePatch{Target: deployment, Patch: "spec:\n  replicas: 2\n"}, ""},
+		{"templated patch", ServicePatch{Target: deployment, Patch: "{{ .Secrets.PATCH }}"}, ""},
+		{"no kind", ServicePatch{Patch: "spec: {}"}, "has no target kind"},
+		{"neither", ServicePatch{Target: deployment}, "has neither operations nor patch"},
+		{"both", ServicePatch{Target: deployment, Patch: "spec: {}", Operations: []ServicePatchOperation{{Op: "remove", Path: "/spec"}}}, "has both operations and patch"},
+		{"invalid op", ServicePatch{Target: deployment, Operations: []ServicePatchOperation{{Op: "merge", Path: "/spec"}}}, "has operation 0 with invalid op 'merge'"},
+		{"invalid path", ServicePatch{Target: deployment, Operations: []ServicePatchOperation{{Op: "remove", Path: "spec"}}}, "has operation 0 with invalid path 'spec'"},
+		{"move without from", ServicePatch{Target: deployment, Operations: []ServicePatchOperation{{Op: "move", Path: "/spec/a"}}}, "has move operation 0 without a from path"},
+		{"patch not a mapping", ServicePatch{Target: deployment, Patch: "- replicas: 2"}, "has invalid patch (must be a YAML mapping)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.patch.validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Patches(t *testing.T) {
+	config := Config{
+		Contexts: []ConfigurationContext{
+			{
+				Name: "my-context",
+				Services: []Service{
+					{
+						Name:                  "api",
+						HelmRepoPath:          "any-repo",
+						HelmBranch:            "any-branch",
+						HelmChartRelativePath: "any-chart",
+						Patches:               []ServicePatch{{Target: ServicePatchTarget{Kind: "Deployment"}}},
+					},
+				},
+			},
+		},
+	}
+
+	assert.EqualError(
+		t,
+		config.Validate(),
+		"patch 0 of service 'api' in context 'my-context' has neither operations nor patch",
+	)
+}
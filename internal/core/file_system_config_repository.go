@@ -3,6 +3,7 @@ package core
 import (
 	"crypto/sha256"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -170,9 +171,17 @@ func (c *FileSystemConfigRepository) LoadConfig() (*domain.Config, error) {
 				service.Profiles = append(service.Profiles, "all")
 			}
 			hasher := sha256.New()
-			hasher.Write([]byte(fmt.Sprintf("%s-%s", service.HelmRepoPath, service.HelmBranch)))
+			if service.UsesChartRepository() {
+				hasher.Write([]byte(fmt.Sprintf("%s-%s-%s", service.HelmChartRepoURL, service.HelmChart, service.HelmChartVersion)))
+			} else {
+				hasher.Write([]byte(fmt.Sprintf("%s-%s", service.HelmRepoPath, service.HelmBranch)))
+			}
 			hashedName := fmt.Sprintf("%x", hasher.Sum(nil))[:12]
 			service.HelmPath = filepath.Join(home, ".dx", context.Name, "charts", hashedName)
+			if service.UsesChartRepository() {
+				// Pulled charts are unpacked into a directory named after the chart
+				service.HelmPath = filepath.Join(service.HelmPath, service.ChartName())
+			}
 			for k, _ := range context.Services[j].DockerImages {
 				image := &config.Contexts[i].Services[j].DockerImages[k]
 				if image.GitRepoPath == "" {
@@ -182,6 +191,11 @@ func (c *FileSystemConfigRepository) LoadConfig() (*domain.Config, error) {
 					image.GitRef = service.GitRef
 				}
 
+				if image.LocalPath != "" {
+					image.Path = expandImportPath(image.LocalPath, home)
+					continue
+				}
+
 				hasher := sha256.New()
 				hasher.Write([]byte(fmt.Sprintf("%s-%s", image.GitRepoPath, image.GitRef)))
 				hashedName = fmt.Sprintf("%x", hasher.Sum(nil))[:12]
@@ -328,6 +342,24 @@ func mergeConfigurationContexts(
 		base.Name = overlay.Name
 	}
 
+	if overlay.Namespace != "" {
+		base.Namespace = overlay.Namespace
+	}
+
+	if overlay.KubeContext != "" {
+		base.KubeContext = overlay.KubeContext
+	}
+
+	if overlay.Builder != "" {
+		base.Builder = overlay.Builder
+	}
+	if overlay.ImageLoader != "" {
+		base.ImageLoader = overlay.ImageLoader
+	}
+	if overlay.ImageRegistry != "" {
+		base.ImageRegistry = overlay.ImageRegistry
+	}
+
 	if overlay.Scripts != nil {
 		if base.Scripts == nil {
 			base.Scripts = make(map[string]string)
@@ -370,6 +402,15 @@ func overlayService(baseService domain.Service, overlayService domain.Service) d
 	if overlayService.HelmChartRelativePath != "" {
 		baseService.HelmChartRelativePath = overlayService.HelmChartRelativePath
 	}
+	if overlayService.HelmChart != "" {
+		baseService.HelmChart = overlayService.HelmChart
+	}
+	if overlayService.HelmChartRepoURL != "" {
+		baseService.HelmChartRepoURL = overlayService.HelmChartRepoURL
+	}
+	if overlayService.HelmChartVersion != "" {
+		baseService.HelmChartVersion = overlayService.HelmChartVersion
+	}
 	if overlayService.DockerImages != nil {
 		for _, overlayImage := range overlayService.DockerImages {
 			for i, baseImage := range baseService.DockerImages {
@@ -382,10 +423,41 @@ func overlayService(baseService domain.Service, overlayService domain.Service) d
 	if overlayService.RemoteImages != nil {
 		baseService.RemoteImages = append(baseService.RemoteImages, overlayService.RemoteImages...)
 	}
+	if overlayService.DependsOn != nil {
+		baseService.DependsOn = append(baseService.DependsOn, overlayService.DependsOn...)
+	}
+	if overlayService.Patches != nil {
+		baseService.Patches = append(baseService.Patches, overlayService.Patches...)
+	}
+	if overlayService.HelmValuesFiles != nil {
+		baseService.HelmValuesFiles = append(baseService.HelmValuesFiles, overlayService.HelmValuesFiles...)
+	}
+	if overlayService.HelmValues != nil {
+		baseService.HelmValues = mergeHelmValues(baseService.HelmValues, overlayService.HelmValues)
+	}
 
 	return baseService
 }
 
+// mergeHelmValues merges overlay values into base values like helm merges values files: nested maps
+// are merged, other overlay values replace the base values.
+func mergeHelmValues(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overlayMap, overlayIsMap := value.(map[string]interface{})
+		if baseIsMap && overlayIsMap {
+			merged[key] = mergeHelmValues(baseMap, overlayMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
 func overlayDockerImage(baseImage *domain.DockerImage, overlayImage *domain.DockerImage) {
 	if overlayImage.Name != "" {
 		baseImage.Name = overlayImage.Name
@@ -396,6 +468,9 @@ func overlayDockerImage(baseImage *domain.DockerImage, overlayImage *domain.Dock
 	if overlayImage.GitRef != "" {
 		baseImage.GitRef = overlayImage.GitRef
 	}
+	if overlayImage.LocalPath != "" {
+		baseImage.LocalPath = overlayImage.LocalPath
+	}
 	if overlayImage.DockerfilePath != "" {
 		baseImage.DockerfilePath = overlayImage.DockerfilePath
 	}
@@ -405,4 +480,38 @@ func overlayDockerImage(baseImage *domain.DockerImage, overlayImage *domain.Dock
 	if overlayImage.BuildArgs != nil {
 		baseImage.BuildArgs = append(baseImage.BuildArgs, overlayImage.BuildArgs...)
 	}
+	if overlayImage.Target != "" {
+		baseImage.Target = overlayImage.Target
+	}
+	if overlayImage.Platform != "" {
+		baseImage.Platform = overlayImage.Platform
+	}
+	if overlayImage.CacheFrom != nil {
+		baseImage.CacheFrom = overlayImage.CacheFrom
+	}
+	if overlayImage.CacheTo != nil {
+		baseImage.CacheTo = overlayImage.CacheTo
+	}
+	if overlayImage.Labels != nil {
+		labels := maps.Clone(baseImage.Labels)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		maps.Copy(labels, overlayImage.Labels)
+		baseImage.Labels = labels
+	}
+	if overlayImage.SSH != nil {
+		baseImage.SSH = overlayImage.SSH
+	}
+	if overlayImage.Network != "" {
+		baseImage.Network = overlayImage.Network
+	}
+	if overlayImage.BuildSecrets != nil {
+		buildSecrets := maps.Clone(baseImage.BuildSecrets)
+		if buildSecrets == nil {
+			buildSecrets = make(map[string]string)
+		}
+		maps.Copy(buildSecrets, overlayImage.BuildSecrets)
+		baseImage.BuildSecrets = buildSecrets
+	}
 }
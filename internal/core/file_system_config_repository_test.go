@@ -1,6 +1,7 @@
 package core
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -409,6 +410,42 @@ func TestMergeConfigurationContexts(t *testing.T) {
 	assert.Len(t, result.LocalServices, 2)
 }
 
+func TestMergeConfigurationContexts_NamespaceAndKubeContext(t *testing.T) {
+	base := domain.ConfigurationContext{Name: "base", Namespace: "base-namespace", KubeContext: "kind-base"}
+
+	assert.Equal(t, base, mergeConfigurationContexts(base, domain.ConfigurationContext{Name: "base"}))
+
+	result := mergeConfigurationContexts(base, domain.ConfigurationContext{Namespace: "overlay-namespace", KubeContext: "kind-overlay"})
+
+	assert.Equal(t, "overlay-namespace", result.Namespace)
+	assert.Equal(t, "kind-overlay", result.KubeContext)
+}
+
+func TestMergeConfigurationContexts_Builder(t *testing.T) {
+	base := domain.ConfigurationContext{Name: "base", Builder: domain.BuilderPodman}
+
+	assert.Equal(t, domain.BuilderPodman, mergeConfigurationContexts(base, domain.ConfigurationContext{}).Builder)
+	assert.Equal(
+		t,
+		domain.BuilderNerdctl,
+		mergeConfigurationContexts(base, domain.ConfigurationContext{Builder: domain.BuilderNerdctl}).Builder,
+	)
+}
+
+func TestMergeConfigurationContexts_ImageLoader(t *testing.T) {
+	base := domain.ConfigurationContext{Name: "base", ImageLoader: domain.ImageLoaderKind}
+
+	result := mergeConfigurationContexts(base, domain.ConfigurationContext{})
+	assert.Equal(t, domain.ImageLoaderKind, result.ImageLoader)
+
+	result = mergeConfigurationContexts(
+		base,
+		domain.ConfigurationContext{ImageLoader: domain.ImageLoaderRegistry, ImageRegistry: "localhost:5001"},
+	)
+	assert.Equal(t, domain.ImageLoaderRegistry, result.ImageLoader)
+	assert.Equal(t, "localhost:5001", result.ImageRegistry)
+}
+
 func TestOverlayService(t *testing.T) {
 	base := domain.Service{
 		Name:        "base-svc",
@@ -432,6 +469,45 @@ func TestOverlayService(t *testing.T) {
 	assert.Equal(t, "main", result.HelmBranch)
 }
 
+func TestOverlayService_AppendsPatches(t *testing.T) {
+	base := domain.Service{
+		Name:    "api",
+		Patches: []domain.ServicePatch{{Target: domain.ServicePatchTarget{Kind: "Deployment"}, Patch: "spec: {}"}},
+	}
+	overlay := domain.Service{
+		Name:    "api",
+		Patches: []domain.ServicePatch{{Target: domain.ServicePatchTarget{Kind: "Service"}, Patch: "spec: {}"}},
+	}
+
+	result := overlayService(base, overlay)
+
+	require.Len(t, result.Patches, 2)
+	assert.Equal(t, "Deployment", result.Patches[0].Target.Kind)
+	assert.Equal(t, "Service", result.Patches[1].Target.Kind)
+}
+
+func TestOverlayService_MergesHelmValues(t *testing.T) {
+	base := domain.Service{
+		Name:            "api",
+		HelmValuesFiles: []string{"values.yaml"},
+		HelmValues:      map[string]interface{}{"image": map[string]interface{}{"repository": "api", "tag": "v1"}, "replicas": 1},
+	}
+	overlay := domain.Service{
+		HelmValuesFiles: []string{"values-dev.yaml"},
+		HelmValues:      map[string]interface{}{"image": map[string]interface{}{"tag": "v2"}, "debug": true},
+	}
+
+	result := overlayService(base, overlay)
+
+	assert.Equal(t, []string{"values.yaml", "values-dev.yaml"}, result.HelmValuesFiles)
+	assert.Equal(t, map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "api", "tag": "v2"},
+		"replicas": 1,
+		"debug":    true,
+	}, result.HelmValues)
+	assert.Equal(t, "v1", base.HelmValues["image"].(map[string]interface{})["tag"], "the base values must not be modified")
+}
+
 func TestFileSystemConfigRepository_LoadConfig_CachesResult(t *testing.T) {
 	fs := testutil.NewTestFileSystem(t)
 	repo := ProvideFileSystemConfigRepository(fs, &mockSecretsRepository{}, &mockTemplater{})
@@ -466,6 +542,64 @@ func TestFileSystemConfigRepository_LoadConfig_CachesResult(t *testing.T) {
 	assert.Same(t, config1, config2, "LoadConfig should return cached result")
 }
 
+func TestFileSystemConfigRepository_LoadConfig_UsesLocalPathAsImagePath(t *testing.T) {
+	fs := testutil.NewTestFileSystem(t)
+	repo := ProvideFileSystemConfigRepository(fs, &mockSecretsRepository{}, &mockTemplater{})
+
+	configContent := `contexts:
+  - name: test-context
+    services:
+      - name: test-service
+        helmRepoPath: /tmp/foo
+        helmBranch: main
+        helmChartRelativePath: helm
+        dockerImages:
+          - name: local-image
+            dockerfilePath: Dockerfile
+            buildContextRelativePath: "."
+            localPath: ~/src/api
+          - name: git-image
+            dockerfilePath: Dockerfile
+            buildContextRelativePath: "."
+            gitRepoPath: /tmp/repo
+            gitRef: main
+`
+	err := fs.WriteFile(filepath.Join("~", ".dx-config.yaml"), []byte(configContent), ports.ReadWrite)
+	require.NoError(t, err)
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	config, err := repo.LoadConfig()
+
+	require.NoError(t, err)
+	images := config.Contexts[0].Services[0].DockerImages
+	assert.Equal(t, filepath.Join(home, "src", "api"), images[0].Path)
+	assert.Equal(t, filepath.Join(home, ".dx", "test-context", "test-service"), filepath.Dir(images[1].Path))
+}
+
+func TestFileSystemConfigRepository_LoadConfig_PulledChartPath(t *testing.T) {
+	fs := testutil.NewTestFileSystem(t)
+	repo := ProvideFileSystemConfigRepository(fs, &mockSecretsRepository{}, &mockTemplater{})
+	configContent := `contexts:
+  - name: test-context
+    services:
+      - name: kafka
+        helmChart: oci://ghcr.io/acme/charts/kafka
+        helmChartVersion: 1.2.0
+`
+	err := fs.WriteFile(filepath.Join("~", ".dx-config.yaml"), []byte(configContent), ports.ReadWrite)
+	require.NoError(t, err)
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	config, err := repo.LoadConfig()
+
+	require.NoError(t, err)
+	helmPath := config.Contexts[0].Services[0].HelmPath
+	assert.Equal(t, "kafka", filepath.Base(helmPath))
+	assert.Equal(t, filepath.Join(home, ".dx", "test-context", "charts"), filepath.Dir(filepath.Dir(helmPath)))
+}
+
 func TestFileSystemConfigRepository_LoadCurrentConfigurationContext_NotFound(t *testing.T) {
 	fs := testutil.NewTestFileSystem(t)
 	repo := ProvideFileSystemConfigRepository(fs, &mockSecretsRepository{}, &mockTemplater{})
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"dx/internal/cli/output"
@@ -11,27 +14,49 @@ import (
 	"dx/internal/core"
 	"dx/internal/core/domain"
 	"dx/internal/ports"
+
+	"golang.org/x/sync/errgroup"
 )
 
 type BuildCommandHandler struct {
 	configRepository         core.ConfigRepository
 	scm                      ports.Scm
 	containerImageRepository ports.ContainerImageRepository
+	buildCache               *core.BuildCache
+	templater                ports.Templater
 }
 
 func ProvideBuildCommandHandler(
 	configRepository core.ConfigRepository,
 	scm ports.Scm,
 	containerImageRepository ports.ContainerImageRepository,
+	buildCache *core.BuildCache,
+	templater ports.Templater,
 ) BuildCommandHandler {
 	return BuildCommandHandler{
 		configRepository:         configRepository,
 		scm:                      scm,
 		containerImageRepository: containerImageRepository,
+		buildCache:               buildCache,
+		templater:                templater,
 	}
 }
 
-func (h *BuildCommandHandler) Handle(services []string, selectedProfile string) error {
+// DefaultBuildJobs is the default number of images built at the same time
+const DefaultBuildJobs = 4
+
+// BuildOptions controls how images are built.
+type BuildOptions struct {
+	// Jobs limits the number of images built at the same time
+	Jobs int
+	// Force rebuilds images even if their build inputs haven't changed
+	Force bool
+	// Worktrees maps service or image names to local checkouts their images are built from
+	// instead of their configured source
+	Worktrees map[string]string
+}
+
+func (h *BuildCommandHandler) Handle(services []string, selectedProfile string, options BuildOptions) error {
 	var dockerImagesToBuild []domain.DockerImage
 	var dockerImagesToPull []string
 
@@ -40,6 +65,10 @@ func (h *BuildCommandHandler) Handle(services []string, selectedProfile string)
 		return err
 	}
 
+	if err := validateWorktrees(configContext, options.Worktrees); err != nil {
+		return err
+	}
+
 	for _, service := range configContext.Services {
 		if len(services) == 0 && !slices.Contains(service.Profiles, selectedProfile) {
 			continue
@@ -49,7 +78,9 @@ func (h *BuildCommandHandler) Handle(services []string, selectedProfile string)
 			continue
 		}
 
-		dockerImagesToBuild = append(dockerImagesToBuild, service.DockerImages...)
+		for _, image := range service.DockerImages {
+			dockerImagesToBuild = append(dockerImagesToBuild, withWorktree(service, image, options.Worktrees))
+		}
 		dockerImagesToPull = append(dockerImagesToPull, service.RemoteImages...)
 	}
 
@@ -64,55 +95,18 @@ func (h *BuildCommandHandler) Handle(services []string, selectedProfile string)
 		output.PrintHeader("Building Docker images")
 		fmt.Println()
 
-		// Create progress tracker with repo/ref info
-		imageNames := make([]string, len(dockerImagesToBuild))
-		imageInfos := make([]string, len(dockerImagesToBuild))
-		for i, img := range dockerImagesToBuild {
-			imageNames[i] = img.Name
-			if img.GitRepoPath != "" && img.GitRef != "" {
-				imageInfos[i] = fmt.Sprintf("%s @ %s", img.GitRepoPath, img.GitRef)
-			}
-		}
-		tracker := progress.NewTrackerWithInfoAndVerb(imageNames, imageInfos, "Building")
-		tracker.Start()
-
-		var buildErr error
-		for i, image := range dockerImagesToBuild {
-			tracker.StartItem(i)
-
-			// Show dockerfile override note (only in non-TTY mode, TTY shows spinner)
-			if image.DockerfileOverride != "" {
-				output.PrintSecondary("Using inline Dockerfile from configuration")
-			}
-
-			// Download source
-			if err := h.scm.Download(image.GitRepoPath, image.GitRef, image.Path); err != nil {
-				tracker.CompleteItem(i, err)
-				tracker.PrintItemComplete(i)
-				buildErr = err
-				break
-			}
-
-			// Build image
-			if err := h.containerImageRepository.BuildImage(image); err != nil {
-				tracker.CompleteItem(i, err)
-				tracker.PrintItemComplete(i)
-				buildErr = err
-				break
-			}
-
-			tracker.CompleteItem(i, nil)
-			tracker.PrintItemComplete(i)
+		unchanged, err := h.buildImages(dockerImagesToBuild, options)
+		if err != nil {
+			return err
 		}
 
-		tracker.Stop()
-
-		if buildErr != nil {
-			return buildErr
+		built := len(dockerImagesToBuild) - unchanged
+		message := fmt.Sprintf("Built %d Docker %s in %s", built, output.Plural(built, "image", "images"), progress.FormatDuration(time.Since(buildStartTime)))
+		if unchanged > 0 {
+			message += fmt.Sprintf(" (%d unchanged, use --force to rebuild)", unchanged)
 		}
-
 		fmt.Println()
-		output.PrintSuccess(fmt.Sprintf("Built %d Docker %s in %s", len(dockerImagesToBuild), output.Plural(len(dockerImagesToBuild), "image", "images"), progress.FormatDuration(time.Since(buildStartTime))))
+		output.PrintSuccess(message)
 		fmt.Println()
 	}
 
@@ -154,3 +148,205 @@ func (h *BuildCommandHandler) Handle(services []string, selectedProfile string)
 
 	return nil
 }
+
+// buildImages downloads the sources of and builds the images, running up to options.Jobs builds at
+// the same time. Images whose build inputs haven't changed since they were last built are skipped
+// unless options.Force is set. No new builds are started once a build fails. On failure a summary of
+// which images were built, failed or skipped is printed and the errors of all failed builds are
+// returned. Returns the number of unchanged images.
+func (h *BuildCommandHandler) buildImages(images []domain.DockerImage, options BuildOptions) (int, error) {
+	imageNames := make([]string, len(images))
+	for i, image := range images {
+		imageNames[i] = image.Name
+	}
+	tracker := progress.NewConcurrentTracker(imageNames, "Building")
+	tracker.Start()
+
+	results := make([]imageBuildResult, len(images))
+	var failed atomic.Bool
+
+	g := new(errgroup.Group)
+	g.SetLimit(max(options.Jobs, 1))
+	for i, image := range images {
+		g.Go(func() error {
+			if failed.Load() {
+				return nil
+			}
+			tracker.StartItem(i)
+
+			var info []string
+			cacheable := true
+			if image.LocalPath != "" {
+				var worktreeInfo string
+				worktreeInfo, cacheable = h.worktreeStatus(image)
+				info = append(info, worktreeInfo)
+			}
+			if image.DockerfileOverride != "" {
+				info = append(info, "using inline Dockerfile from configuration")
+			}
+			if len(info) > 0 {
+				tracker.SetItemInfo(i, strings.Join(info, ", "))
+			}
+
+			results[i] = h.buildImage(image, cacheable, options)
+			if results[i].unchanged {
+				info = []string{"unchanged"}
+			}
+			if reference := results[i].reference; reference != "" && reference != image.Name {
+				info = append(info, "as "+reference)
+			}
+			if len(info) > 0 {
+				tracker.SetItemInfo(i, strings.Join(info, ", "))
+			}
+
+			tracker.CompleteItem(i, results[i].err)
+			if results[i].err != nil {
+				failed.Store(true)
+			}
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+	tracker.Stop()
+
+	unchanged := 0
+	for _, result := range results {
+		if result.unchanged {
+			unchanged++
+		}
+	}
+
+	if !failed.Load() {
+		return unchanged, nil
+	}
+
+	fmt.Println()
+	output.PrintHeader("Build summary")
+	var errs []error
+	for i, image := range images {
+		switch {
+		case results[i].err != nil:
+			errs = append(errs, results[i].err)
+			fmt.Printf("  %s %s  %s\n", output.Error(output.SymbolError), image.Name, output.Error("failed"))
+		case results[i].unchanged:
+			fmt.Printf("  %s %s  %s\n", output.Success(output.SymbolSuccess), image.Name, output.Dim("unchanged"))
+		case results[i].built:
+			fmt.Printf("  %s %s  %s\n", output.Success(output.SymbolSuccess), image.Name, output.Dim("built"))
+		default:
+			fmt.Printf("  %s %s  %s\n", output.Dim(output.SymbolBullet), image.Name, output.Dim("skipped"))
+		}
+	}
+	fmt.Println()
+
+	return unchanged, errors.Join(errs...)
+}
+
+// imageBuildResult is the outcome of building a single image.
+type imageBuildResult struct {
+	built     bool
+	unchanged bool
+	// reference is the name the image was built as, see core.ResolveImageName
+	reference string
+	err       error
+}
+
+// buildImage downloads the source of the image and builds it, unless the build cache shows its
+// build inputs haven't changed. An unchanged image is loaded into the cluster again, since the
+// cluster may have been recreated or the image loader changed since it was built, and is built if
+// that fails. Images built from a local checkout aren't downloaded, and only use
+// the build cache when cacheable, since uncommitted changes aren't part of the fingerprint. The
+// build cache is best effort: when the fingerprint can't be computed or recorded, the image is
+// built and the cache is left as is. Images are built as their name resolved with the revision of
+// their source, which is recorded for templating Helm args.
+func (h *BuildCommandHandler) buildImage(image domain.DockerImage, cacheable bool, options BuildOptions) imageBuildResult {
+	if image.LocalPath == "" {
+		if err := h.scm.Download(image.GitRepoPath, image.GitRef, image.Path); err != nil {
+			return imageBuildResult{err: fmt.Errorf("failed to build %s: %w", image.Name, err)}
+		}
+	}
+
+	configuredName := image.Name
+	reference, err := core.ResolveImageName(image, h.scm, h.templater)
+	if err != nil {
+		return imageBuildResult{err: fmt.Errorf("failed to build %s: %w", image.Name, err)}
+	}
+	image.Name = reference
+
+	var fingerprint string
+	if cacheable {
+		var err error
+		fingerprint, err = h.buildCache.Fingerprint(image)
+		cacheable = err == nil
+	}
+	if cacheable && !options.Force {
+		upToDate, err := h.buildCache.IsUpToDate(image, fingerprint)
+		if err == nil && upToDate && h.containerImageRepository.LoadImage(reference) == nil {
+			_ = h.buildCache.RecordReference(configuredName, reference)
+			return imageBuildResult{unchanged: true, reference: reference}
+		}
+	}
+
+	if err := h.containerImageRepository.BuildImage(image); err != nil {
+		return imageBuildResult{err: fmt.Errorf("failed to build %s: %w", configuredName, err)}
+	}
+
+	if cacheable {
+		_ = h.buildCache.Record(image, fingerprint)
+	}
+	_ = h.buildCache.RecordReference(configuredName, reference)
+	return imageBuildResult{built: true, reference: reference}
+}
+
+// validateWorktrees checks that each worktree override names a service or image of the context.
+func validateWorktrees(configContext *domain.ConfigurationContext, worktrees map[string]string) error {
+	for name := range worktrees {
+		known := slices.ContainsFunc(configContext.Services, func(service domain.Service) bool {
+			return service.Name == name || slices.ContainsFunc(service.DockerImages, func(image domain.DockerImage) bool {
+				return image.Name == name
+			})
+		})
+		if !known {
+			return fmt.Errorf("cannot build '%s' from a worktree: no service or image with that name in context '%s'", name, configContext.Name)
+		}
+	}
+	return nil
+}
+
+// withWorktree returns the image with its source replaced by the worktree given for the image or,
+// failing that, for its service.
+func withWorktree(service domain.Service, image domain.DockerImage, worktrees map[string]string) domain.DockerImage {
+	path, ok := worktrees[image.Name]
+	if !ok {
+		path, ok = worktrees[service.Name]
+	}
+	if ok {
+		image.LocalPath = path
+		image.Path = path
+	}
+	return image
+}
+
+// worktreeStatus describes the local checkout an image is built from and reports whether it is
+// clean, meaning its checked out commit describes all of its files. Files ignored by git in the
+// build context are sent to the builder but not described by the commit, so a build context with
+// ignored files isn't clean either.
+func (h *BuildCommandHandler) worktreeStatus(image domain.DockerImage) (string, bool) {
+	dirty, err := h.scm.HasUncommittedChanges(image.Path)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("from %s", image.Path), false
+	case dirty:
+		return fmt.Sprintf("from worktree %s (dirty)", image.Path), false
+	}
+
+	ignored, err := h.scm.HasIgnoredFiles(filepath.Join(image.Path, image.BuildContextRelativePath))
+	switch {
+	case err != nil:
+		return fmt.Sprintf("from %s", image.Path), false
+	case ignored:
+		return fmt.Sprintf("from worktree %s (ignored files)", image.Path), false
+	default:
+		return fmt.Sprintf("from worktree %s (clean)", image.Path), true
+	}
+}
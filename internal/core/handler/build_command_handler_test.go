@@ -1,14 +1,42 @@
 package handler
 
 import (
+	"errors"
 	"testing"
 
+	"dx/internal/core"
 	"dx/internal/core/domain"
 	"dx/internal/testutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// createTestBuildCache returns a build cache backed by a sandboxed file system. Sources are at
+// revision "any-revision" and images have no local ID unless the test mocks them first.
+func createTestBuildCache(
+	t *testing.T,
+	configRepository *testutil.MockConfigRepository,
+	scm *testutil.MockScm,
+	containerImageRepository *testutil.MockContainerImageRepository,
+) *core.BuildCache {
+	configRepository.On("LoadCurrentContextName").Return("test-context", nil)
+	secretsRepository := new(testutil.MockSecretsRepository)
+	secretsRepository.On("LoadSecrets", mock.Anything).Return([]*domain.Secret{}, nil)
+	scm.On("Revision", mock.Anything).Return("any-revision", nil).Maybe()
+	containerImageRepository.On("ImageID", mock.Anything).Return("", nil).Maybe()
+
+	return core.ProvideBuildCache(
+		configRepository,
+		secretsRepository,
+		new(testutil.MockTemplater),
+		testutil.NewTestFileSystem(t),
+		scm,
+		containerImageRepository,
+	)
+}
+
 func TestBuildCommandHandler_HandleBuildsAllServices(t *testing.T) {
 	configContext := &domain.ConfigurationContext{
 		Services: []domain.Service{
@@ -67,9 +95,10 @@ func TestBuildCommandHandler_HandleBuildsAllServices(t *testing.T) {
 		configRepository:         configRepository,
 		scm:                      scm,
 		containerImageRepository: containerImageRepository,
+		buildCache:               createTestBuildCache(t, configRepository, scm, containerImageRepository),
 	}
 
-	result := sut.Handle([]string{}, "all")
+	result := sut.Handle([]string{}, "all", BuildOptions{Jobs: DefaultBuildJobs})
 
 	assert.Nil(t, result)
 	scm.AssertExpectations(t)
@@ -126,9 +155,11 @@ func TestBuildCommandHandler_HandleBuildsOnlySelectedService(t *testing.T) {
 		configRepository,
 		scm,
 		containerImageRepository,
+		createTestBuildCache(t, configRepository, scm, containerImageRepository),
+		new(testutil.MockTemplater),
 	)
 
-	result := sut.Handle([]string{configContext.Services[0].Name}, "default")
+	result := sut.Handle([]string{configContext.Services[0].Name}, "default", BuildOptions{Jobs: DefaultBuildJobs})
 
 	assert.Nil(t, result)
 	scm.AssertExpectations(t)
@@ -185,11 +216,317 @@ func TestBuildCommandHandler_HandleBuildsOnlyServicesInSelectedProfile(t *testin
 		configRepository,
 		scm,
 		containerImageRepository,
+		createTestBuildCache(t, configRepository, scm, containerImageRepository),
+		new(testutil.MockTemplater),
 	)
 
-	result := sut.Handle([]string{}, "selected")
+	result := sut.Handle([]string{}, "selected", BuildOptions{Jobs: DefaultBuildJobs})
 
 	assert.Nil(t, result)
 	scm.AssertExpectations(t)
 	containerImageRepository.AssertExpectations(t)
 }
+
+func TestBuildCommandHandler_HandleSkipsRemainingImagesAfterFailure(t *testing.T) {
+	image := func(name string) domain.DockerImage {
+		return domain.DockerImage{Name: name, DockerfilePath: ".", GitRepoPath: "repo-" + name, GitRef: "main"}
+	}
+	configContext := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name:         "service-1",
+				DockerImages: []domain.DockerImage{image("image-c"), image("image-a"), image("image-b")},
+				Profiles:     []string{"default"},
+			},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	buildErr := errors.New("build failed")
+	containerImageRepository.On("BuildImage", image("image-a")).Return(buildErr)
+
+	sut := BuildCommandHandler{
+		configRepository:         configRepository,
+		scm:                      scm,
+		containerImageRepository: containerImageRepository,
+		buildCache:               createTestBuildCache(t, configRepository, scm, containerImageRepository),
+	}
+
+	result := sut.Handle([]string{}, "default", BuildOptions{Jobs: 1})
+
+	assert.ErrorIs(t, result, buildErr)
+	assert.ErrorContains(t, result, "failed to build image-a")
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 1)
+}
+
+func TestBuildCommandHandler_HandleReturnsErrorsOfAllFailedBuilds(t *testing.T) {
+	image := func(name string) domain.DockerImage {
+		return domain.DockerImage{Name: name, DockerfilePath: ".", GitRepoPath: "repo-" + name, GitRef: "main"}
+	}
+	configContext := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name:         "service-1",
+				DockerImages: []domain.DockerImage{image("image-a"), image("image-b")},
+				Profiles:     []string{"default"},
+			},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	// Neither build fails before both have started
+	imageAStarted := make(chan struct{})
+	imageBStarted := make(chan struct{})
+	scm := new(testutil.MockScm)
+	scm.On("Download", "repo-image-a", "main", "").Run(func(mock.Arguments) {
+		close(imageAStarted)
+		<-imageBStarted
+	}).Return(errors.New("clone failed"))
+	scm.On("Download", "repo-image-b", "main", "").Run(func(mock.Arguments) {
+		close(imageBStarted)
+		<-imageAStarted
+	}).Return(nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuildImage", image("image-b")).Return(errors.New("build failed"))
+
+	sut := BuildCommandHandler{
+		configRepository:         configRepository,
+		scm:                      scm,
+		containerImageRepository: containerImageRepository,
+		buildCache:               createTestBuildCache(t, configRepository, scm, containerImageRepository),
+	}
+
+	result := sut.Handle([]string{}, "default", BuildOptions{Jobs: 2})
+
+	assert.ErrorContains(t, result, "failed to build image-a: clone failed")
+	assert.ErrorContains(t, result, "failed to build image-b: build failed")
+}
+
+func TestBuildCommandHandler_HandleSkipsUnchangedImages(t *testing.T) {
+	image := domain.DockerImage{
+		Name:               "any-image",
+		DockerfileOverride: "FROM scratch",
+		GitRepoPath:        "any-repo",
+		GitRef:             "main",
+		Path:               "/source",
+	}
+	configContext := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{Name: "service-1", DockerImages: []domain.DockerImage{image}, Profiles: []string{"default"}},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	scm := new(testutil.MockScm)
+	scm.On("Download", "any-repo", "main", "/source").Return(nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuildImage", image).Return(nil)
+	containerImageRepository.On("ImageID", "any-image").Return("sha256:built", nil)
+	containerImageRepository.On("LoadImage", "any-image").Return(nil)
+	sut := ProvideBuildCommandHandler(
+		configRepository,
+		scm,
+		containerImageRepository,
+		createTestBuildCache(t, configRepository, scm, containerImageRepository),
+		new(testutil.MockTemplater),
+	)
+
+	assert.NoError(t, sut.Handle([]string{}, "default", BuildOptions{Jobs: 1}))
+	assert.NoError(t, sut.Handle([]string{}, "default", BuildOptions{Jobs: 1}))
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 1)
+	// The unchanged image is loaded into the cluster, which may have been recreated
+	containerImageRepository.AssertNumberOfCalls(t, "LoadImage", 1)
+
+	assert.NoError(t, sut.Handle([]string{}, "default", BuildOptions{Jobs: 1, Force: true}))
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 2)
+}
+
+func TestBuildCommandHandler_HandleRebuildsUnchangedImageThatFailsToLoad(t *testing.T) {
+	image := domain.DockerImage{
+		Name:               "any-image",
+		DockerfileOverride: "FROM scratch",
+		GitRepoPath:        "any-repo",
+		GitRef:             "main",
+		Path:               "/source",
+	}
+	configContext := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{Name: "service-1", DockerImages: []domain.DockerImage{image}, Profiles: []string{"default"}},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	scm := new(testutil.MockScm)
+	scm.On("Download", "any-repo", "main", "/source").Return(nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuildImage", image).Return(nil)
+	containerImageRepository.On("ImageID", "any-image").Return("sha256:built", nil)
+	containerImageRepository.On("LoadImage", "any-image").Return(errors.New("image not found"))
+	sut := ProvideBuildCommandHandler(
+		configRepository,
+		scm,
+		containerImageRepository,
+		createTestBuildCache(t, configRepository, scm, containerImageRepository),
+		new(testutil.MockTemplater),
+	)
+
+	assert.NoError(t, sut.Handle([]string{}, "default", BuildOptions{Jobs: 1}))
+	assert.NoError(t, sut.Handle([]string{}, "default", BuildOptions{Jobs: 1}))
+
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 2)
+}
+
+func TestBuildCommandHandler_HandleBuildsFromWorktree(t *testing.T) {
+	localImage := domain.DockerImage{
+		Name:                     "local-image",
+		DockerfilePath:           "Dockerfile",
+		BuildContextRelativePath: "app",
+		LocalPath:                "/src/local",
+		Path:                     "/src/local",
+	}
+	gitImage := domain.DockerImage{
+		Name:                     "git-image",
+		DockerfilePath:           "Dockerfile",
+		BuildContextRelativePath: ".",
+		GitRepoPath:              "any-repo",
+		GitRef:                   "main",
+		Path:                     "/source",
+	}
+	configContext := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{Name: "local-service", DockerImages: []domain.DockerImage{localImage}, Profiles: []string{"default"}},
+			{Name: "git-service", DockerImages: []domain.DockerImage{gitImage}, Profiles: []string{"default"}},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	scm := new(testutil.MockScm)
+	scm.On("HasUncommittedChanges", "/src/local").Return(true, nil)
+	scm.On("HasUncommittedChanges", "/src/checkout").Return(false, nil)
+	scm.On("HasIgnoredFiles", "/src/checkout").Return(false, nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuildImage", localImage).Return(nil)
+	containerImageRepository.On("ImageID", "git-image").Return("sha256:built", nil)
+	containerImageRepository.On("LoadImage", "git-image").Return(nil)
+	worktreeImage := gitImage
+	worktreeImage.LocalPath = "/src/checkout"
+	worktreeImage.Path = "/src/checkout"
+	containerImageRepository.On("BuildImage", worktreeImage).Return(nil)
+	sut := ProvideBuildCommandHandler(
+		configRepository,
+		scm,
+		containerImageRepository,
+		createTestBuildCache(t, configRepository, scm, containerImageRepository),
+		new(testutil.MockTemplater),
+	)
+	options := BuildOptions{Jobs: 1, Worktrees: map[string]string{"git-service": "/src/checkout"}}
+
+	assert.NoError(t, sut.Handle([]string{}, "default", options))
+	assert.NoError(t, sut.Handle([]string{}, "default", options))
+
+	// The dirty worktree is rebuilt, the clean one is cached
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 3)
+	scm.AssertNotCalled(t, "Download", mock.Anything, mock.Anything, mock.Anything)
+	scm.AssertExpectations(t)
+	containerImageRepository.AssertExpectations(t)
+}
+
+func TestBuildCommandHandler_HandleRebuildsWorktreeWithIgnoredFiles(t *testing.T) {
+	image := domain.DockerImage{
+		Name:                     "api-image",
+		DockerfilePath:           "Dockerfile",
+		BuildContextRelativePath: "app",
+		GitRepoPath:              "any-repo",
+		GitRef:                   "main",
+		Path:                     "/source",
+	}
+	configContext := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{Name: "api", DockerImages: []domain.DockerImage{image}, Profiles: []string{"default"}},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	scm := new(testutil.MockScm)
+	scm.On("HasUncommittedChanges", "/src/api").Return(false, nil)
+	// Only the build context is checked for ignored files
+	scm.On("HasIgnoredFiles", "/src/api/app").Return(true, nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuildImage", mock.Anything).Return(nil)
+	sut := ProvideBuildCommandHandler(
+		configRepository,
+		scm,
+		containerImageRepository,
+		createTestBuildCache(t, configRepository, scm, containerImageRepository),
+		new(testutil.MockTemplater),
+	)
+	options := BuildOptions{Jobs: 1, Worktrees: map[string]string{"api": "/src/api"}}
+
+	assert.NoError(t, sut.Handle([]string{}, "default", options))
+	assert.NoError(t, sut.Handle([]string{}, "default", options))
+
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 2)
+	scm.AssertExpectations(t)
+}
+
+func TestBuildCommandHandler_HandleRejectsUnknownWorktree(t *testing.T) {
+	configContext := &domain.ConfigurationContext{
+		Name:     "test-context",
+		Services: []domain.Service{{Name: "service-1", Profiles: []string{"default"}}},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	sut := ProvideBuildCommandHandler(
+		configRepository,
+		new(testutil.MockScm),
+		new(testutil.MockContainerImageRepository),
+		nil,
+		new(testutil.MockTemplater),
+	)
+
+	err := sut.Handle([]string{}, "default", BuildOptions{Worktrees: map[string]string{"unknown": "/src"}})
+
+	assert.EqualError(t, err, "cannot build 'unknown' from a worktree: no service or image with that name in context 'test-context'")
+}
+
+func TestBuildCommandHandler_HandleBuildsImagesAsResolvedName(t *testing.T) {
+	configContext := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name: "api",
+				DockerImages: []domain.DockerImage{
+					{
+						Name:           "api:{{.Git.ShortSha}}",
+						DockerfilePath: "Dockerfile",
+						GitRepoPath:    "any-repo",
+						GitRef:         "main",
+						Path:           "/source",
+					},
+				},
+			},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	scm := new(testutil.MockScm)
+	scm.On("Download", "any-repo", "main", "/source").Return(nil)
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", "api:{{.Git.ShortSha}}", "image-name", mock.Anything).Return("api:abc1234", nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	builtImage := configContext.Services[0].DockerImages[0]
+	builtImage.Name = "api:abc1234"
+	containerImageRepository.On("BuildImage", builtImage).Return(nil)
+	buildCache := createTestBuildCache(t, configRepository, scm, containerImageRepository)
+	sut := ProvideBuildCommandHandler(configRepository, scm, containerImageRepository, buildCache, templater)
+
+	err := sut.Handle([]string{"api"}, "", BuildOptions{Jobs: DefaultBuildJobs})
+
+	require.NoError(t, err)
+	containerImageRepository.AssertExpectations(t)
+	references, err := buildCache.ImageReferences(configContext)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"api": map[string]interface{}{"ref": "api:abc1234"}}, references)
+}
@@ -13,21 +13,24 @@ type ContextCommandHandler struct {
 	configRepository         core.ConfigRepository
 	scm                      ports.Scm
 	containerImageRepository ports.ContainerImageRepository
+	containerOrchestrator    ports.ContainerOrchestrator
 }
 
 func ProvideContextCommandHandler(
 	configRepository core.ConfigRepository,
 	scm ports.Scm,
 	containerImageRepository ports.ContainerImageRepository,
+	containerOrchestrator ports.ContainerOrchestrator,
 ) ContextCommandHandler {
 	return ContextCommandHandler{
 		configRepository:         configRepository,
 		scm:                      scm,
 		containerImageRepository: containerImageRepository,
+		containerOrchestrator:    containerOrchestrator,
 	}
 }
 
-func (h *ContextCommandHandler) HandleSet(contextName string) error {
+func (h *ContextCommandHandler) HandleSet(contextName string, createNamespace bool) error {
 	config, err := h.configRepository.LoadConfig()
 	if err != nil {
 		return err
@@ -40,6 +43,20 @@ func (h *ContextCommandHandler) HandleSet(contextName string) error {
 		return err
 	}
 	output.PrintSuccess(fmt.Sprintf("Switched to context '%s'", contextName))
+
+	if !createNamespace {
+		return nil
+	}
+
+	namespace, created, err := h.containerOrchestrator.EnsureNamespace()
+	if err != nil {
+		return err
+	}
+	if created {
+		output.PrintSuccess(fmt.Sprintf("Created namespace '%s'", namespace))
+	} else {
+		output.PrintInfo(fmt.Sprintf("Namespace '%s' already exists", namespace))
+	}
 	return nil
 }
 
@@ -14,6 +14,7 @@ func TestContextCommandHandler_HandleSet_Success(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	config := &domain.Config{
 		Contexts: []domain.ConfigurationContext{
@@ -25,25 +26,98 @@ func TestContextCommandHandler_HandleSet_Success(t *testing.T) {
 	configRepository.On("LoadConfig").Return(config, nil)
 	configRepository.On("SaveCurrentContextName", "production").Return(nil)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
-	err := sut.HandleSet("production")
+	err := sut.HandleSet("production", false)
 
 	assert.NoError(t, err)
 	configRepository.AssertExpectations(t)
 }
 
+func TestContextCommandHandler_HandleSet_CreatesNamespace(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	scm := new(testutil.MockScm)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+
+	config := &domain.Config{
+		Contexts: []domain.ConfigurationContext{
+			{Name: "production", Namespace: "prod"},
+		},
+	}
+
+	configRepository.On("LoadConfig").Return(config, nil)
+	configRepository.On("SaveCurrentContextName", "production").Return(nil)
+	containerOrchestrator.On("EnsureNamespace").Return("prod", true, nil)
+
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
+
+	err := sut.HandleSet("production", true)
+
+	assert.NoError(t, err)
+	configRepository.AssertExpectations(t)
+	containerOrchestrator.AssertExpectations(t)
+}
+
+func TestContextCommandHandler_HandleSet_SkipsNamespaceByDefault(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	scm := new(testutil.MockScm)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+
+	config := &domain.Config{
+		Contexts: []domain.ConfigurationContext{
+			{Name: "production", Namespace: "prod"},
+		},
+	}
+
+	configRepository.On("LoadConfig").Return(config, nil)
+	configRepository.On("SaveCurrentContextName", "production").Return(nil)
+
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
+
+	err := sut.HandleSet("production", false)
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertNotCalled(t, "EnsureNamespace")
+}
+
+func TestContextCommandHandler_HandleSet_EnsureNamespaceError(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	scm := new(testutil.MockScm)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+
+	config := &domain.Config{
+		Contexts: []domain.ConfigurationContext{
+			{Name: "production", Namespace: "prod"},
+		},
+	}
+	expectedErr := errors.New("forbidden")
+
+	configRepository.On("LoadConfig").Return(config, nil)
+	configRepository.On("SaveCurrentContextName", "production").Return(nil)
+	containerOrchestrator.On("EnsureNamespace").Return("", false, expectedErr)
+
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
+
+	err := sut.HandleSet("production", true)
+
+	assert.ErrorIs(t, err, expectedErr)
+}
+
 func TestContextCommandHandler_HandleSet_LoadConfigError(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	expectedErr := errors.New("load config error")
 	configRepository.On("LoadConfig").Return(nil, expectedErr)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
-	err := sut.HandleSet("production")
+	err := sut.HandleSet("production", false)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
@@ -54,6 +128,7 @@ func TestContextCommandHandler_HandleSet_ContextNotFound(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	config := &domain.Config{
 		Contexts: []domain.ConfigurationContext{
@@ -63,9 +138,9 @@ func TestContextCommandHandler_HandleSet_ContextNotFound(t *testing.T) {
 
 	configRepository.On("LoadConfig").Return(config, nil)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
-	err := sut.HandleSet("non-existent")
+	err := sut.HandleSet("non-existent", false)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "context not found: non-existent")
@@ -76,6 +151,7 @@ func TestContextCommandHandler_HandleSet_SaveError(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	config := &domain.Config{
 		Contexts: []domain.ConfigurationContext{
@@ -88,9 +164,9 @@ func TestContextCommandHandler_HandleSet_SaveError(t *testing.T) {
 	configRepository.On("LoadConfig").Return(config, nil)
 	configRepository.On("SaveCurrentContextName", "production").Return(expectedErr)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
-	err := sut.HandleSet("production")
+	err := sut.HandleSet("production", false)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
@@ -101,6 +177,7 @@ func TestContextCommandHandler_HandleList_Success(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	config := &domain.Config{
 		Contexts: []domain.ConfigurationContext{
@@ -112,7 +189,7 @@ func TestContextCommandHandler_HandleList_Success(t *testing.T) {
 	configRepository.On("LoadConfig").Return(config, nil)
 	configRepository.On("LoadCurrentContextName").Return("default", nil)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
 	err := sut.HandleList()
 
@@ -124,6 +201,7 @@ func TestContextCommandHandler_HandleList_Empty(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	config := &domain.Config{
 		Contexts: []domain.ConfigurationContext{},
@@ -132,7 +210,7 @@ func TestContextCommandHandler_HandleList_Empty(t *testing.T) {
 	configRepository.On("LoadConfig").Return(config, nil)
 	configRepository.On("LoadCurrentContextName").Return("", nil)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
 	err := sut.HandleList()
 
@@ -144,11 +222,12 @@ func TestContextCommandHandler_HandleList_LoadConfigError(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	expectedErr := errors.New("load config error")
 	configRepository.On("LoadConfig").Return(nil, expectedErr)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
 	err := sut.HandleList()
 
@@ -163,6 +242,7 @@ func TestContextCommandHandler_HandleList_LoadCurrentContextNameError(t *testing
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	config := &domain.Config{
 		Contexts: []domain.ConfigurationContext{
@@ -174,7 +254,7 @@ func TestContextCommandHandler_HandleList_LoadCurrentContextNameError(t *testing
 	configRepository.On("LoadConfig").Return(config, nil)
 	configRepository.On("LoadCurrentContextName").Return("", errors.New("context name error"))
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
 	err := sut.HandleList()
 
@@ -187,6 +267,7 @@ func TestContextCommandHandler_HandlePrint_Success(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	configContext := &domain.ConfigurationContext{
 		Name: "test-context",
@@ -197,7 +278,7 @@ func TestContextCommandHandler_HandlePrint_Success(t *testing.T) {
 
 	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
 	err := sut.HandlePrint()
 
@@ -209,11 +290,12 @@ func TestContextCommandHandler_HandlePrint_LoadConfigError(t *testing.T) {
 	configRepository := new(testutil.MockConfigRepository)
 	scm := new(testutil.MockScm)
 	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 
 	expectedErr := errors.New("load config error")
 	configRepository.On("LoadCurrentConfigurationContext").Return(nil, expectedErr)
 
-	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository)
+	sut := ProvideContextCommandHandler(configRepository, scm, containerImageRepository, containerOrchestrator)
 
 	err := sut.HandlePrint()
 
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"fmt"
+
+	"dx/internal/cli/output"
+	"dx/internal/core"
+	"dx/internal/ports"
+)
+
+type DiffCommandHandler struct {
+	configRepository      core.ConfigRepository
+	containerOrchestrator ports.ContainerOrchestrator
+	environmentEnsurer    core.EnvironmentEnsurer
+	chartDownloader       *core.ChartDownloader
+}
+
+func ProvideDiffCommandHandler(
+	configRepository core.ConfigRepository,
+	containerOrchestrator ports.ContainerOrchestrator,
+	environmentEnsurer core.EnvironmentEnsurer,
+	chartDownloader *core.ChartDownloader,
+) DiffCommandHandler {
+	return DiffCommandHandler{
+		configRepository:      configRepository,
+		containerOrchestrator: containerOrchestrator,
+		environmentEnsurer:    environmentEnsurer,
+		chartDownloader:       chartDownloader,
+	}
+}
+
+// Handle prints the changes installing the services would make to the objects in the cluster.
+func (h *DiffCommandHandler) Handle(services []string, selectedProfile string) error {
+	err := h.environmentEnsurer.EnsureExpectedClusterIsSelected()
+	if err != nil {
+		return err
+	}
+
+	configContext, err := h.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return err
+	}
+
+	changedObjects := 0
+	changedServices := 0
+	for _, service := range selectServices(configContext, services, selectedProfile) {
+		if err := h.chartDownloader.Download(&service, func(string) {}); err != nil {
+			return err
+		}
+
+		diffs, err := h.containerOrchestrator.DiffService(&service)
+		if err != nil {
+			return fmt.Errorf("failed to diff service %s: %v", service.Name, err)
+		}
+
+		output.PrintHeader(service.Name)
+		if len(diffs) == 0 {
+			output.PrintSecondary("No changes")
+			fmt.Println()
+			continue
+		}
+		fmt.Println()
+
+		for _, diff := range diffs {
+			objectPath := objectDiffPath(diff)
+			fromName := "live/" + objectPath
+			if diff.Live == "" {
+				fromName = "/dev/null"
+			}
+			toName := "rendered/" + objectPath
+			if diff.Desired == "" {
+				toName = "/dev/null"
+			}
+			output.PrintDiff(output.UnifiedDiff(fromName, toName, []byte(diff.Live), []byte(diff.Desired)))
+			fmt.Println()
+		}
+		changedObjects += len(diffs)
+		changedServices++
+	}
+
+	if changedObjects == 0 {
+		output.PrintSuccess("No changes")
+		return nil
+	}
+
+	output.PrintInfo(fmt.Sprintf(
+		"%d %s in %d %s would change, run 'dx install' to apply them",
+		changedObjects, output.Plural(changedObjects, "object", "objects"),
+		changedServices, output.Plural(changedServices, "service", "services"),
+	))
+	return nil
+}
+
+// objectDiffPath identifies the object of a diff as kind/namespace/name, leaving out the namespace of
+// cluster-scoped objects.
+func objectDiffPath(diff ports.ObjectDiff) string {
+	if diff.Namespace == "" {
+		return fmt.Sprintf("%s/%s", diff.Kind, diff.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", diff.Kind, diff.Namespace, diff.Name)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func createTestDiffCommandHandler(
+	containerOrchestrator *testutil.MockContainerOrchestrator,
+	scm *testutil.MockScm,
+) DiffCommandHandler {
+	configContext := &domain.ConfigurationContext{
+		Name: "Test",
+		Services: []domain.Service{
+			{Name: "api", HelmRepoPath: "any-repo", HelmBranch: "feature", HelmPath: "/charts/api", Profiles: []string{"default"}},
+			{Name: "worker", HelmRepoPath: "any-repo", HelmBranch: "main", HelmPath: "/charts/worker", Profiles: []string{"default"}},
+			{Name: "db", HelmRepoPath: "any-repo", HelmBranch: "main", HelmPath: "/charts/db", Profiles: []string{"data"}},
+		},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadEnvKey", mock.Anything).Return("any-key", nil)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(configRepository, containerOrchestrator)
+	return ProvideDiffCommandHandler(
+		configRepository,
+		containerOrchestrator,
+		environmentEnsurer,
+		createTestChartDownloader(scm),
+	)
+}
+
+func TestDiffCommandHandler_HandleDiffsServicesOfProfile(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("DiffService", mock.MatchedBy(func(s *domain.Service) bool { return s.Name == "api" })).
+		Return([]ports.ObjectDiff{{Kind: "ConfigMap", Namespace: "dev", Name: "api", Live: "a: 1\n", Desired: "a: 2\n"}}, nil)
+	containerOrchestrator.On("DiffService", mock.MatchedBy(func(s *domain.Service) bool { return s.Name == "worker" })).
+		Return(nil, nil)
+	scm := new(testutil.MockScm)
+	scm.On("Download", "any-repo", "feature", "/charts/api").Return(nil)
+	scm.On("Download", "any-repo", "main", "/charts/worker").Return(nil)
+	sut := createTestDiffCommandHandler(containerOrchestrator, scm)
+
+	err := sut.Handle(nil, "default")
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertNumberOfCalls(t, "DiffService", 2)
+	scm.AssertExpectations(t)
+}
+
+func TestDiffCommandHandler_HandleDiffsSelectedServices(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("DiffService", mock.Anything).Return(nil, nil)
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	sut := createTestDiffCommandHandler(containerOrchestrator, scm)
+
+	err := sut.Handle([]string{"db"}, "default")
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertCalled(t, "DiffService", mock.MatchedBy(func(s *domain.Service) bool { return s.Name == "db" }))
+	containerOrchestrator.AssertNumberOfCalls(t, "DiffService", 1)
+}
+
+func TestDiffCommandHandler_HandleReturnsDiffError(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("DiffService", mock.Anything).Return(nil, errors.New("helm template failed"))
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	sut := createTestDiffCommandHandler(containerOrchestrator, scm)
+
+	err := sut.Handle([]string{"api"}, "default")
+
+	assert.ErrorContains(t, err, "failed to diff service api: helm template failed")
+}
+
+func TestObjectDiffPath(t *testing.T) {
+	assert.Equal(t, "ConfigMap/dev/api", objectDiffPath(ports.ObjectDiff{Kind: "ConfigMap", Namespace: "dev", Name: "api"}))
+	assert.Equal(t, "ClusterRole/api", objectDiffPath(ports.ObjectDiff{Kind: "ClusterRole", Name: "api"}))
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"dx/internal/cli/output"
+	"dx/internal/core"
+	"dx/internal/ports"
+)
+
+type DoctorCommandHandler struct {
+	configRepository         core.ConfigRepository
+	containerImageRepository ports.ContainerImageRepository
+}
+
+func ProvideDoctorCommandHandler(
+	configRepository core.ConfigRepository,
+	containerImageRepository ports.ContainerImageRepository,
+) DoctorCommandHandler {
+	return DoctorCommandHandler{
+		configRepository:         configRepository,
+		containerImageRepository: containerImageRepository,
+	}
+}
+
+// Handle checks that the current context can be loaded and that its builder is available, and
+// reports which builder is active.
+func (h *DoctorCommandHandler) Handle() error {
+	output.PrintHeader("Checking your environment")
+	fmt.Println()
+
+	configContext, err := h.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		printCheck("Context", false, err.Error())
+		fmt.Println()
+		return errors.New("the current context could not be loaded")
+	}
+	printCheck("Context", true, configContext.Name)
+
+	builder := configContext.ActiveBuilder()
+	version, err := h.containerImageRepository.BuilderVersion()
+	if err != nil {
+		printCheck("Builder", false, fmt.Sprintf("%s is not available: %v", builder, err))
+		fmt.Println()
+		return fmt.Errorf("builder %s is not available", builder)
+	}
+	printCheck("Builder", true, fmt.Sprintf("%s (%s)", builder, version))
+	fmt.Println()
+
+	output.PrintSuccess("No problems found")
+	return nil
+}
+
+func printCheck(name string, passed bool, detail string) {
+	if passed {
+		fmt.Printf("  %s %-8s %s\n", output.Success(output.SymbolSuccess), name, detail)
+	} else {
+		fmt.Printf("  %s %-8s %s\n", output.Error(output.SymbolError), name, output.Error(detail))
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctorCommandHandler_Handle_Success(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").
+		Return(&domain.ConfigurationContext{Name: "dev", Builder: domain.BuilderPodman}, nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuilderVersion").Return("podman version 5.0.1", nil)
+	sut := ProvideDoctorCommandHandler(configRepository, containerImageRepository)
+
+	err := sut.Handle()
+
+	assert.NoError(t, err)
+	containerImageRepository.AssertExpectations(t)
+}
+
+func TestDoctorCommandHandler_Handle_BuilderNotAvailable(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").
+		Return(&domain.ConfigurationContext{Name: "dev", Builder: domain.BuilderNerdctl}, nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuilderVersion").Return("", errors.New("executable file not found"))
+	sut := ProvideDoctorCommandHandler(configRepository, containerImageRepository)
+
+	err := sut.Handle()
+
+	assert.EqualError(t, err, "builder nerdctl is not available")
+}
+
+func TestDoctorCommandHandler_Handle_ContextNotLoaded(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(nil, errors.New("invalid builder"))
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	sut := ProvideDoctorCommandHandler(configRepository, containerImageRepository)
+
+	err := sut.Handle()
+
+	assert.EqualError(t, err, "the current context could not be loaded")
+	containerImageRepository.AssertNotCalled(t, "BuilderVersion")
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"fmt"
+
+	"dx/internal/cli/output"
+	"dx/internal/core"
+	"dx/internal/core/domain"
+)
+
+type HistoryCommandHandler struct {
+	deploymentHistory *core.DeploymentHistory
+}
+
+func ProvideHistoryCommandHandler(deploymentHistory *core.DeploymentHistory) HistoryCommandHandler {
+	return HistoryCommandHandler{
+		deploymentHistory: deploymentHistory,
+	}
+}
+
+// Handle prints the recorded deployments of a service, oldest first.
+func (h *HistoryCommandHandler) Handle(serviceName string) error {
+	deployments, err := h.deploymentHistory.List(serviceName)
+	if err != nil {
+		return err
+	}
+	if len(deployments) == 0 {
+		output.PrintInfo(fmt.Sprintf("No deployments of %s recorded", serviceName))
+		return nil
+	}
+
+	output.PrintHeader(fmt.Sprintf("Deployment history of %s", serviceName))
+	fmt.Println()
+
+	for i, deployment := range deployments {
+		revision := fmt.Sprintf("revision %d", deployment.Revision)
+		timestamp := deployment.Timestamp.Local().Format("2006-01-02 15:04:05")
+		if i == len(deployments)-1 {
+			fmt.Printf("  %s %s %s %s\n", output.SymbolArrow, output.Bold(revision), timestamp, output.Dim("(current)"))
+		} else {
+			fmt.Printf("  %s %s %s\n", output.SymbolBullet, output.Bold(revision), timestamp)
+		}
+		if deployment.RollbackOf != 0 {
+			fmt.Printf("      rollback to revision %d\n", deployment.RollbackOf)
+		}
+		fmt.Printf("      chart  %s\n", formatSourceRevision(deployment.Chart))
+		for _, image := range deployment.Images {
+			fmt.Printf("      image  %s\n", formatSourceRevision(image))
+		}
+		fmt.Printf("      args   %s\n", output.Dim(shortHash(deployment.ArgsHash)))
+	}
+	return nil
+}
+
+// formatSourceRevision formats the source of a chart or image with its ref and revision, if known.
+func formatSourceRevision(source domain.SourceRevision) string {
+	formatted := source.Name
+	if source.Ref != "" {
+		formatted += " " + source.Ref
+	}
+	if source.Revision != "" && source.Revision != source.Ref {
+		formatted += " " + output.Dim(fmt.Sprintf("(%s)", shortHash(source.Revision)))
+	}
+	return formatted
+}
+
+// shortHash abbreviates a commit SHA or checksum for display.
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryCommandHandler_HandlePrintsDeployments(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentContextName").Return("test-context", nil)
+	deploymentHistory := core.ProvideDeploymentHistory(configRepository, testutil.NewTestFileSystem(t), new(testutil.MockScm))
+	_, err := deploymentHistory.Record("api", domain.Deployment{
+		Chart:    domain.SourceRevision{Name: "charts-repo", Ref: "main", Revision: "0123456789abcdef"},
+		Images:   []domain.SourceRevision{{Name: "api:latest", Ref: "develop", Revision: "fedcba9876543210"}},
+		ArgsHash: "aaaabbbbccccdddd",
+	})
+	require.NoError(t, err)
+	_, err = deploymentHistory.Record("api", domain.Deployment{RollbackOf: 1})
+	require.NoError(t, err)
+	sut := ProvideHistoryCommandHandler(deploymentHistory)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = sut.Handle("api")
+
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	_, copyErr := io.Copy(&buf, r)
+	require.NoError(t, copyErr)
+	output := buf.String()
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "revision 1")
+	assert.Contains(t, output, "charts-repo main")
+	assert.Contains(t, output, "0123456789ab")
+	assert.NotContains(t, output, "0123456789abc")
+	assert.Contains(t, output, "api:latest develop")
+	assert.Contains(t, output, "aaaabbbbcccc")
+	assert.Contains(t, output, "rollback to revision 1")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("revision 1")), bytes.Index(buf.Bytes(), []byte("revision 2")))
+}
+
+func TestHistoryCommandHandler_HandleNoDeployments(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentContextName").Return("test-context", nil)
+	deploymentHistory := core.ProvideDeploymentHistory(configRepository, testutil.NewTestFileSystem(t), new(testutil.MockScm))
+	sut := ProvideHistoryCommandHandler(deploymentHistory)
+
+	err := sut.Handle("api")
+
+	assert.NoError(t, err)
+}
@@ -1,23 +1,55 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"dx/internal/cli/output"
 	"dx/internal/cli/progress"
 	"dx/internal/core"
 	"dx/internal/core/domain"
 	"dx/internal/ports"
+
+	"golang.org/x/sync/errgroup"
 )
 
+// DefaultInstallJobs is the default number of services installed at the same time
+const DefaultInstallJobs = 4
+
+// InstallOptions controls how services are installed.
+type InstallOptions struct {
+	SkipDevProxy bool
+	// Wait blocks after each release until its Deployments, StatefulSets and Jobs are ready
+	Wait bool
+	// WaitForDependencies waits for readiness only of services that other selected services depend on
+	WaitForDependencies bool
+	Timeout             time.Duration
+	// Jobs limits the number of services installed at the same time
+	Jobs int
+	// Force upgrades releases even if their manifests haven't changed
+	Force bool
+	// DryRun renders the manifests of the services and the dev-proxy instead of installing them,
+	// without contacting the cluster
+	DryRun bool
+	// OutputDir is the directory DryRun writes the manifests to. They are printed to stdout if empty.
+	OutputDir string
+}
+
 type InstallCommandHandler struct {
 	configRepository         core.ConfigRepository
 	containerImageRepository ports.ContainerImageRepository
 	containerOrchestrator    ports.ContainerOrchestrator
 	devProxyManager          *core.DevProxyManager
 	environmentEnsurer       core.EnvironmentEnsurer
-	scm                      ports.Scm
+	chartDownloader          *core.ChartDownloader
+	outputWriter             ports.OutputWriter
 }
 
 func ProvideInstallCommandHandler(
@@ -26,7 +58,8 @@ func ProvideInstallCommandHandler(
 	containerOrchestrator ports.ContainerOrchestrator,
 	devProxyManager *core.DevProxyManager,
 	environmentEnsurer core.EnvironmentEnsurer,
-	scm ports.Scm,
+	chartDownloader *core.ChartDownloader,
+	outputWriter ports.OutputWriter,
 ) InstallCommandHandler {
 	return InstallCommandHandler{
 		configRepository:         configRepository,
@@ -34,11 +67,16 @@ func ProvideInstallCommandHandler(
 		containerOrchestrator:    containerOrchestrator,
 		devProxyManager:          devProxyManager,
 		environmentEnsurer:       environmentEnsurer,
-		scm:                      scm,
+		chartDownloader:          chartDownloader,
+		outputWriter:             outputWriter,
 	}
 }
 
-func (h *InstallCommandHandler) Handle(services []string, selectedProfile string, skipDevProxy bool) error {
+func (h *InstallCommandHandler) Handle(services []string, selectedProfile string, options InstallOptions) error {
+	if options.DryRun {
+		return h.render(services, selectedProfile, options)
+	}
+
 	err := h.environmentEnsurer.EnsureExpectedClusterIsSelected()
 	if err != nil {
 		return err
@@ -49,23 +87,11 @@ func (h *InstallCommandHandler) Handle(services []string, selectedProfile string
 		return err
 	}
 
-	// Collect services to install
-	var servicesToInstall []domain.Service
-	for _, service := range configContext.Services {
-		if len(services) == 0 && !slices.Contains(service.Profiles, selectedProfile) {
-			continue
-		}
-
-		if len(services) > 0 && !slices.ContainsFunc(services, func(s string) bool { return s == service.Name }) {
-			continue
-		}
-
-		servicesToInstall = append(servicesToInstall, service)
-	}
+	servicesToInstall := selectServices(configContext, services, selectedProfile)
 
 	// Check if dev-proxy needs to be rebuilt before setting up the tracker
 	shouldRebuildDevProxy := false
-	if !skipDevProxy {
+	if !options.SkipDevProxy {
 		var err error
 		shouldRebuildDevProxy, err = h.devProxyManager.ShouldRebuildDevProxy()
 		if err != nil {
@@ -85,83 +111,264 @@ func (h *InstallCommandHandler) Handle(services []string, selectedProfile string
 	output.PrintHeader("Installing services")
 	fmt.Println()
 
-	// Build names and infos for tracker
-	names := make([]string, 0, totalItems)
-	infos := make([]string, 0, totalItems)
-
+	var tasks []installTask
 	if shouldRebuildDevProxy {
-		names = append(names, "dev-proxy")
-		infos = append(infos, "dx")
+		tasks = append(tasks, installTask{
+			service: domain.Service{Name: core.DevProxyServiceName},
+			install: func(func(string)) (bool, error) {
+				return false, h.devProxyManager.Rebuild()
+			},
+		})
+	}
+
+	// Dependencies are only ordered within the selection, services that aren't selected are
+	// expected to be installed already
+	taskIndices := make(map[string]int, len(servicesToInstall))
+	for _, service := range servicesToInstall {
+		taskIndices[service.Name] = len(tasks)
+		tasks = append(tasks, installTask{service: service})
+	}
+	// The selectors of the Services named by LocalServices are patched to point at the dev-proxy.
+	// Which release contains them is only known once rendered, so all services wait for the
+	// dev-proxy to not route traffic to a missing or outdated proxy.
+	if shouldRebuildDevProxy && len(configContext.LocalServices) > 0 {
+		for i := 1; i < len(tasks); i++ {
+			tasks[0].hasDependents = true
+			tasks[i].dependencies = append(tasks[i].dependencies, 0)
+		}
+	}
+	for i := range tasks {
+		for _, dependency := range tasks[i].service.DependsOn {
+			if index, ok := taskIndices[dependency]; ok {
+				tasks[index].hasDependents = true
+				tasks[i].dependencies = append(tasks[i].dependencies, index)
+			}
+		}
 	}
 
-	for _, svc := range servicesToInstall {
-		names = append(names, svc.Name)
-		infos = append(infos, "")
+	for i := range tasks {
+		if tasks[i].install != nil {
+			continue
+		}
+		service := tasks[i].service
+		tasks[i].install = func(onStatus func(string)) (bool, error) {
+			if err := h.chartDownloader.Download(&service, onStatus); err != nil {
+				return false, err
+			}
+
+			upgraded, err := h.containerOrchestrator.InstallService(&service, options.Force)
+			if err != nil {
+				return false, fmt.Errorf("failed to install service %s: %v", service.Name, err)
+			}
+			return !upgraded, nil
+		}
 	}
 
-	tracker := progress.NewTrackerWithInfoAndVerb(names, infos, "Installing")
+	names := make([]string, len(tasks))
+	for i, task := range tasks {
+		names[i] = task.service.Name
+	}
+	tracker := progress.NewConcurrentTracker(names, "Installing")
 	tracker.Start()
 
-	currentIndex := 0
+	unchanged, err := h.runInstallTasks(tracker, tasks, options)
+	tracker.Stop()
+	if err != nil {
+		printRolloutDiagnostics(err)
+		return err
+	}
 
-	// Install dev-proxy first if needed
-	if shouldRebuildDevProxy {
-		tracker.StartItem(currentIndex)
+	message := fmt.Sprintf("Installed %d %s", len(tasks), output.Plural(len(tasks), "service", "services"))
+	if unchanged > 0 {
+		message += fmt.Sprintf(" (%d unchanged, use --force to reinstall)", unchanged)
+	}
+	fmt.Println()
+	output.PrintSuccess(message)
 
-		if err := h.devProxyManager.SaveConfiguration(); err != nil {
-			tracker.CompleteItem(currentIndex, err)
-			tracker.PrintItemComplete(currentIndex)
-			tracker.Stop()
-			return err
-		}
+	return nil
+}
 
-		if err := h.devProxyManager.BuildDevProxy(); err != nil {
-			tracker.CompleteItem(currentIndex, err)
-			tracker.PrintItemComplete(currentIndex)
-			tracker.Stop()
-			return err
+// selectServices returns the services of a context with the given names, or the services of the
+// selected profile if no names are given.
+func selectServices(configContext *domain.ConfigurationContext, services []string, selectedProfile string) []domain.Service {
+	var selected []domain.Service
+	for _, service := range configContext.Services {
+		if len(services) == 0 && !slices.Contains(service.Profiles, selectedProfile) {
+			continue
 		}
 
-		if err := h.devProxyManager.InstallDevProxy(); err != nil {
-			tracker.CompleteItem(currentIndex, err)
-			tracker.PrintItemComplete(currentIndex)
-			tracker.Stop()
-			return err
+		if len(services) > 0 && !slices.ContainsFunc(services, func(s string) bool { return s == service.Name }) {
+			continue
 		}
 
-		tracker.CompleteItem(currentIndex, nil)
-		tracker.PrintItemComplete(currentIndex)
-		currentIndex++
+		selected = append(selected, service)
 	}
+	return selected
+}
 
-	// Install user services
-	for _, service := range servicesToInstall {
-		tracker.StartItem(currentIndex)
+// render renders the manifests of the services and, unless skipped, the dev-proxy the way Handle
+// would install them. Each release is written to <OutputDir>/<name>.yaml and the generated dev-proxy
+// files to <OutputDir>/dev-proxy/. Without an output directory the manifests are printed to stdout as
+// one multi-document stream, so they can be piped into validators.
+func (h *InstallCommandHandler) render(services []string, selectedProfile string, options InstallOptions) error {
+	configContext, err := h.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return err
+	}
+
+	rendered := 0
+	for _, service := range selectServices(configContext, services, selectedProfile) {
+		if err := h.chartDownloader.Download(&service, func(string) {}); err != nil {
+			return err
+		}
 
-		err := h.scm.Download(service.HelmRepoPath, service.HelmBranch, service.HelmPath)
+		manifests, err := h.containerOrchestrator.RenderService(&service)
 		if err != nil {
-			tracker.CompleteItem(currentIndex, err)
-			tracker.PrintItemComplete(currentIndex)
-			tracker.Stop()
+			return fmt.Errorf("failed to render service %s: %v", service.Name, err)
+		}
+		if err := h.writeManifests(service.Name, manifests, options.OutputDir); err != nil {
 			return err
 		}
+		rendered++
+	}
 
-		if err = h.containerOrchestrator.InstallService(&service); err != nil {
-			installErr := fmt.Errorf("failed to install service %s: %v", service.Name, err)
-			tracker.CompleteItem(currentIndex, installErr)
-			tracker.PrintItemComplete(currentIndex)
-			tracker.Stop()
-			return installErr
+	if !options.SkipDevProxy {
+		files, manifests, err := h.devProxyManager.RenderDevProxy()
+		if err != nil {
+			return fmt.Errorf("failed to render dev-proxy: %v", err)
+		}
+		if options.OutputDir != "" {
+			for _, file := range files {
+				path := filepath.Join(options.OutputDir, "dev-proxy", file.RelativePath)
+				if err := h.outputWriter.WriteFile(path, file.Content); err != nil {
+					return err
+				}
+			}
+		}
+		if err := h.writeManifests("dev-proxy", manifests, options.OutputDir); err != nil {
+			return err
 		}
+		rendered++
+	}
 
-		tracker.CompleteItem(currentIndex, nil)
-		tracker.PrintItemComplete(currentIndex)
-		currentIndex++
+	if options.OutputDir != "" {
+		output.PrintSuccess(fmt.Sprintf("Rendered %d %s to %s", rendered, output.Plural(rendered, "release", "releases"), options.OutputDir))
 	}
+	return nil
+}
 
-	tracker.Stop()
-	fmt.Println()
-	output.PrintSuccess(fmt.Sprintf("Installed %d %s", totalItems, output.Plural(totalItems, "service", "services")))
+// writeManifests writes the manifests of a release to <outputDir>/<name>.yaml, or prints them as
+// documents of a stream to stdout if outputDir is empty.
+func (h *InstallCommandHandler) writeManifests(name string, manifests []byte, outputDir string) error {
+	if outputDir != "" {
+		return h.outputWriter.WriteFile(filepath.Join(outputDir, name+".yaml"), manifests)
+	}
 
+	fmt.Printf("---\n# Release: %s\n", name)
+	fmt.Print(strings.TrimPrefix(string(manifests), "---\n"))
+	if len(manifests) > 0 && !bytes.HasSuffix(manifests, []byte("\n")) {
+		fmt.Println()
+	}
 	return nil
 }
+
+// installTask is a service installation that starts once its dependencies are installed.
+type installTask struct {
+	service       domain.Service
+	install       func(onStatus func(status string)) (unchanged bool, err error)
+	dependencies  []int // Indices of the tasks this task depends on
+	hasDependents bool
+}
+
+// runInstallTasks runs up to options.Jobs tasks at the same time, starting each task once its dependencies
+// succeeded. No new tasks are started after a task fails. Returns the number of services whose
+// release was unchanged, or the first error.
+func (h *InstallCommandHandler) runInstallTasks(
+	tracker *progress.ConcurrentTracker,
+	tasks []installTask,
+	options InstallOptions,
+) (int, error) {
+	installed := make([]chan struct{}, len(tasks))
+	for i := range tasks {
+		installed[i] = make(chan struct{})
+	}
+	slots := make(chan struct{}, max(options.Jobs, 1))
+	var unchanged atomic.Int32
+
+	g, ctx := errgroup.WithContext(context.Background())
+	for i, task := range tasks {
+		g.Go(func() error {
+			for _, dependency := range task.dependencies {
+				select {
+				case <-installed[dependency]:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-slots }()
+
+			tracker.StartItem(i)
+			taskUnchanged, err := task.install(func(status string) {
+				tracker.SetItemInfo(i, status)
+			})
+			var dependencyErr *core.ChartDependencyError
+			if errors.As(err, &dependencyErr) {
+				tracker.SetItemInfo(i, "chart dependencies could not be built")
+			}
+			if err == nil && (options.Wait || (options.WaitForDependencies && task.hasDependents)) {
+				err = h.containerOrchestrator.WaitForService(&task.service, options.Timeout, func(status string) {
+					tracker.SetItemInfo(i, status)
+				})
+			}
+			if err == nil && taskUnchanged {
+				unchanged.Add(1)
+				tracker.SetItemInfo(i, "unchanged")
+			}
+			tracker.CompleteItem(i, err)
+			if err != nil {
+				return err
+			}
+
+			close(installed[i])
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+	return int(unchanged.Load()), nil
+}
+
+// printRolloutDiagnostics prints the events and logs of the pod that failed a rollout, if any.
+func printRolloutDiagnostics(err error) {
+	var rolloutErr *ports.RolloutError
+	if !errors.As(err, &rolloutErr) || rolloutErr.Pod == "" {
+		return
+	}
+
+	fmt.Println()
+	output.PrintHeader(fmt.Sprintf("Events of pod %s", rolloutErr.Pod))
+	if len(rolloutErr.Events) == 0 {
+		output.PrintSecondary("No events found")
+	}
+	for _, event := range rolloutErr.Events {
+		output.PrintSecondary(event)
+	}
+
+	fmt.Println()
+	output.PrintHeader(fmt.Sprintf("Last log lines of pod %s", rolloutErr.Pod))
+	if rolloutErr.Logs == "" {
+		output.PrintSecondary("No logs found")
+		return
+	}
+	for _, line := range strings.Split(rolloutErr.Logs, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
@@ -1,16 +1,32 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"dx/internal/core"
 	"dx/internal/core/domain"
+	"dx/internal/ports"
 	"dx/internal/testutil"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// createTestChartDownloader creates a ChartDownloader for charts without dependencies.
+func createTestChartDownloader(scm *testutil.MockScm) *core.ChartDownloader {
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("FileExists", mock.Anything).Return(false, nil)
+	return core.ProvideChartDownloader(scm, new(testutil.MockHelmClient), fileSystem)
+}
+
 func TestInstallCommandHandler_HandleInstallsAllServices(t *testing.T) {
 	configContext := &domain.ConfigurationContext{
 		Name: "Test",
@@ -34,7 +50,7 @@ func TestInstallCommandHandler_HandleInstallsAllServices(t *testing.T) {
 	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
 	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
-	containerOrchestrator.On("InstallService", mock.Anything).Return(nil)
+	containerOrchestrator.On("InstallService", mock.Anything, false).Return(true, nil)
 	containerOrchestrator.On("InstallDevProxy", mock.Anything).Return(nil)
 	fileSystem := new(testutil.MockFileSystem)
 	fileSystem.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
@@ -73,10 +89,11 @@ func TestInstallCommandHandler_HandleInstallsAllServices(t *testing.T) {
 		containerOrchestrator,
 		devProxyManager,
 		environmentEnsurer,
-		scm,
+		createTestChartDownloader(scm),
+		new(testutil.MockOutputWriter),
 	)
 
-	result := sut.Handle([]string{}, "all", false)
+	result := sut.Handle([]string{}, "all", InstallOptions{})
 
 	assert.Nil(t, result)
 	containerImageRepository.AssertExpectations(t)
@@ -112,7 +129,7 @@ func TestInstallCommandHandler_HandleInstallsOnlySelectedService(t *testing.T) {
 	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
 	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
-	containerOrchestrator.On("InstallService", mock.Anything).Return(nil)
+	containerOrchestrator.On("InstallService", mock.Anything, false).Return(true, nil)
 	containerOrchestrator.On("InstallDevProxy", mock.Anything).Return(nil)
 	fileSystem := new(testutil.MockFileSystem)
 	fileSystem.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
@@ -145,10 +162,11 @@ func TestInstallCommandHandler_HandleInstallsOnlySelectedService(t *testing.T) {
 		containerOrchestrator,
 		devProxyManager,
 		environmentEnsurer,
-		scm,
+		createTestChartDownloader(scm),
+		new(testutil.MockOutputWriter),
 	)
 
-	result := sut.Handle([]string{"service-1"}, "all", false)
+	result := sut.Handle([]string{"service-1"}, "all", InstallOptions{})
 
 	assert.Nil(t, result)
 	containerImageRepository.AssertExpectations(t)
@@ -183,14 +201,15 @@ func TestInstallCommandHandler_HandleSkipsDevProxyWhenChecksumUnchanged(t *testi
 	}
 	// Calculate the expected checksum for the LocalServices
 	configGenerator := core.ProvideDevProxyConfigGenerator()
-	expectedChecksum := configGenerator.GenerateChecksum(configContext)
+	expectedChecksum, err := configGenerator.GenerateChecksum(configContext)
+	assert.NoError(t, err)
 
 	configRepository := new(testutil.MockConfigRepository)
 	configRepository.On("LoadEnvKey", mock.Anything).Return("any-key", nil)
 	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
 	containerOrchestrator := new(testutil.MockContainerOrchestrator)
 	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
-	containerOrchestrator.On("InstallService", mock.Anything).Return(nil)
+	containerOrchestrator.On("InstallService", mock.Anything, false).Return(true, nil)
 	// Return matching checksum - dev-proxy should be skipped
 	containerOrchestrator.On("GetDevProxyChecksum").Return(expectedChecksum, nil)
 	fileSystem := new(testutil.MockFileSystem)
@@ -222,10 +241,11 @@ func TestInstallCommandHandler_HandleSkipsDevProxyWhenChecksumUnchanged(t *testi
 		containerOrchestrator,
 		devProxyManager,
 		environmentEnsurer,
-		scm,
+		createTestChartDownloader(scm),
+		new(testutil.MockOutputWriter),
 	)
 
-	result := sut.Handle([]string{}, "default", false)
+	result := sut.Handle([]string{}, "default", InstallOptions{})
 
 	assert.Nil(t, result)
 	// Verify BuildImage was NOT called since dev-proxy checksum matched
@@ -238,3 +258,376 @@ func TestInstallCommandHandler_HandleSkipsDevProxyWhenChecksumUnchanged(t *testi
 	containerOrchestrator.AssertNumberOfCalls(t, "InstallService", 1)
 	scm.AssertNumberOfCalls(t, "Download", 1)
 }
+
+func TestInstallCommandHandler_HandleInstallsDevProxyBeforeServicesWithLocalServices(t *testing.T) {
+	configContext := &domain.ConfigurationContext{
+		Name: "Test",
+		LocalServices: []domain.LocalService{
+			{Name: "service-1", KubernetesPort: 8080, LocalPort: 3000, HealthCheckPath: "/health"},
+		},
+		Services: []domain.Service{
+			{Name: "service-1", HelmRepoPath: "any-repo-1", HelmBranch: "any-branch-1", Profiles: []string{"default"}},
+			{Name: "service-2", HelmRepoPath: "any-repo-2", HelmBranch: "any-branch-2", Profiles: []string{"default"}},
+		},
+	}
+	var installOrder []string
+	var mu sync.Mutex
+	recordInstall := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		installOrder = append(installOrder, name)
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadEnvKey", mock.Anything).Return("any-key", nil)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
+	containerOrchestrator.On("GetDevProxyChecksum").Return("", nil)
+	containerOrchestrator.On("InstallDevProxy", mock.Anything).Run(func(mock.Arguments) {
+		// Give the services a chance to overtake the dev-proxy if they don't wait for it
+		time.Sleep(50 * time.Millisecond)
+		recordInstall("dev-proxy")
+	}).Return(nil)
+	containerOrchestrator.On("InstallService", mock.Anything, false).Run(func(args mock.Arguments) {
+		recordInstall(args.Get(0).(*domain.Service).Name)
+	}).Return(true, nil)
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	fileSystem.On("HomeDir").Return("/home/test", nil)
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuildImage", mock.Anything).Return(nil)
+	devProxyManager := core.ProvideDevProxyManager(
+		configRepository,
+		fileSystem,
+		containerImageRepository,
+		containerOrchestrator,
+		core.ProvideDevProxyConfigGenerator(),
+	)
+	sut := ProvideInstallCommandHandler(
+		configRepository,
+		containerImageRepository,
+		containerOrchestrator,
+		devProxyManager,
+		core.ProvideEnvironmentEnsurer(configRepository, containerOrchestrator),
+		createTestChartDownloader(scm),
+		new(testutil.MockOutputWriter),
+	)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{})
+
+	assert.NoError(t, result)
+	require.Len(t, installOrder, 3)
+	assert.Equal(t, "dev-proxy", installOrder[0])
+	assert.ElementsMatch(t, []string{"service-1", "service-2"}, installOrder[1:])
+}
+
+func createWaitTestInstallCommandHandler(
+	containerOrchestrator *testutil.MockContainerOrchestrator,
+	services ...domain.Service,
+) InstallCommandHandler {
+	if len(services) == 0 {
+		services = []domain.Service{
+			{Name: "service-1", HelmRepoPath: "any-repo-1", HelmBranch: "any-branch-1", Profiles: []string{"default"}},
+			{Name: "service-2", HelmRepoPath: "any-repo-2", HelmBranch: "any-branch-2", Profiles: []string{"default"}},
+		}
+	}
+	configContext := &domain.ConfigurationContext{Name: "Test", Services: services}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadEnvKey", mock.Anything).Return("any-key", nil)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
+	containerOrchestrator.On("InstallService", mock.Anything, false).Return(true, nil).Maybe()
+	scm := new(testutil.MockScm)
+	scm.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	devProxyManager := core.ProvideDevProxyManager(
+		configRepository,
+		new(testutil.MockFileSystem),
+		containerImageRepository,
+		containerOrchestrator,
+		core.ProvideDevProxyConfigGenerator(),
+	)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(configRepository, containerOrchestrator)
+	return ProvideInstallCommandHandler(
+		configRepository,
+		containerImageRepository,
+		containerOrchestrator,
+		devProxyManager,
+		environmentEnsurer,
+		createTestChartDownloader(scm),
+		new(testutil.MockOutputWriter),
+	)
+}
+
+func TestInstallCommandHandler_HandleWaitsForEachService(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("WaitForService", mock.Anything, 2*time.Minute, mock.Anything).Return(nil)
+	sut := createWaitTestInstallCommandHandler(containerOrchestrator)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{SkipDevProxy: true, Wait: true, Timeout: 2 * time.Minute})
+
+	assert.NoError(t, result)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallService", 2)
+	containerOrchestrator.AssertNumberOfCalls(t, "WaitForService", 2)
+}
+
+func TestInstallCommandHandler_HandleDoesNotWaitByDefault(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	sut := createWaitTestInstallCommandHandler(containerOrchestrator)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{SkipDevProxy: true})
+
+	assert.NoError(t, result)
+	containerOrchestrator.AssertNotCalled(t, "WaitForService", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInstallCommandHandler_HandleStopsWhenServiceIsNotReady(t *testing.T) {
+	rolloutErr := &ports.RolloutError{
+		Service:  "service-1",
+		Workload: "deployment/service-1",
+		Reason:   "has container main in CrashLoopBackOff",
+		Pod:      "service-1-abc",
+		Events:   []string{"Warning BackOff: Back-off restarting failed container"},
+		Logs:     "panic: missing config",
+	}
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("WaitForService", mock.Anything, mock.Anything, mock.Anything).Return(rolloutErr)
+	sut := createWaitTestInstallCommandHandler(
+		containerOrchestrator,
+		domain.Service{Name: "service-1", Profiles: []string{"default"}},
+		domain.Service{Name: "service-2", Profiles: []string{"default"}, DependsOn: []string{"service-1"}},
+	)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{SkipDevProxy: true, Wait: true, Timeout: time.Minute})
+
+	assert.ErrorIs(t, result, rolloutErr)
+	// service-2 isn't started since its dependency failed
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallService", 1)
+}
+
+func TestInstallCommandHandler_HandleInstallsDependenciesFirst(t *testing.T) {
+	var installOrder []string
+	var mu sync.Mutex
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("InstallService", mock.Anything, false).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		installOrder = append(installOrder, args.Get(0).(*domain.Service).Name)
+	}).Return(true, nil)
+	sut := createWaitTestInstallCommandHandler(
+		containerOrchestrator,
+		domain.Service{Name: "api", Profiles: []string{"default"}, DependsOn: []string{"db", "cache"}},
+		domain.Service{Name: "cache", Profiles: []string{"default"}, DependsOn: []string{"db"}},
+		domain.Service{Name: "db", Profiles: []string{"default"}},
+	)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{SkipDevProxy: true})
+
+	assert.NoError(t, result)
+	assert.Equal(t, []string{"db", "cache", "api"}, installOrder)
+}
+
+func TestInstallCommandHandler_HandleInstallsUpToJobsServicesAtATime(t *testing.T) {
+	for _, jobs := range []int{1, 2} {
+		var running, maxRunning int
+		var mu sync.Mutex
+		containerOrchestrator := new(testutil.MockContainerOrchestrator)
+		containerOrchestrator.On("InstallService", mock.Anything, false).Run(func(args mock.Arguments) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}).Return(true, nil)
+		sut := createWaitTestInstallCommandHandler(
+			containerOrchestrator,
+			domain.Service{Name: "a", Profiles: []string{"default"}},
+			domain.Service{Name: "b", Profiles: []string{"default"}},
+			domain.Service{Name: "c", Profiles: []string{"default"}},
+			domain.Service{Name: "d", Profiles: []string{"default"}},
+		)
+
+		result := sut.Handle([]string{}, "default", InstallOptions{SkipDevProxy: true, Jobs: jobs})
+
+		assert.NoError(t, result)
+		assert.LessOrEqual(t, maxRunning, jobs)
+		containerOrchestrator.AssertNumberOfCalls(t, "InstallService", 4)
+	}
+}
+
+func TestInstallCommandHandler_HandleIgnoresDependenciesOutsideSelection(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	sut := createWaitTestInstallCommandHandler(
+		containerOrchestrator,
+		domain.Service{Name: "api", Profiles: []string{"default"}, DependsOn: []string{"db"}},
+		domain.Service{Name: "db", Profiles: []string{"default"}},
+	)
+
+	result := sut.Handle([]string{"api"}, "default", InstallOptions{SkipDevProxy: true})
+
+	assert.NoError(t, result)
+	containerOrchestrator.AssertCalled(t, "InstallService", mock.MatchedBy(func(s *domain.Service) bool {
+		return s.Name == "api"
+	}), false)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallService", 1)
+}
+
+func TestInstallCommandHandler_HandleWaitsOnlyForDependencies(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("WaitForService", mock.Anything, time.Minute, mock.Anything).Return(nil)
+	sut := createWaitTestInstallCommandHandler(
+		containerOrchestrator,
+		domain.Service{Name: "api", Profiles: []string{"default"}, DependsOn: []string{"db"}},
+		domain.Service{Name: "db", Profiles: []string{"default"}},
+	)
+
+	result := sut.Handle(
+		[]string{},
+		"default",
+		InstallOptions{SkipDevProxy: true, WaitForDependencies: true, Timeout: time.Minute},
+	)
+
+	assert.NoError(t, result)
+	containerOrchestrator.AssertNumberOfCalls(t, "WaitForService", 1)
+	containerOrchestrator.AssertCalled(t, "WaitForService", mock.MatchedBy(func(s *domain.Service) bool {
+		return s.Name == "db"
+	}), time.Minute, mock.Anything)
+}
+
+func TestInstallCommandHandler_HandlePassesForce(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("InstallService", mock.Anything, true).Return(true, nil)
+	sut := createWaitTestInstallCommandHandler(containerOrchestrator)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{SkipDevProxy: true, Force: true})
+
+	assert.NoError(t, result)
+	containerOrchestrator.AssertNotCalled(t, "InstallService", mock.Anything, false)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallService", 2)
+}
+
+func TestInstallCommandHandler_HandleAcceptsUnchangedReleases(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("InstallService", mock.Anything, false).Return(false, nil)
+	containerOrchestrator.On("WaitForService", mock.Anything, time.Minute, mock.Anything).Return(nil)
+	sut := createWaitTestInstallCommandHandler(containerOrchestrator)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{SkipDevProxy: true, Wait: true, Timeout: time.Minute})
+
+	assert.NoError(t, result)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallService", 2)
+	containerOrchestrator.AssertNumberOfCalls(t, "WaitForService", 2)
+}
+
+func createDryRunTestInstallCommandHandler(
+	containerOrchestrator *testutil.MockContainerOrchestrator,
+	outputWriter *testutil.MockOutputWriter,
+) InstallCommandHandler {
+	configContext := &domain.ConfigurationContext{
+		Name: "Test",
+		Services: []domain.Service{
+			{Name: "api", HelmRepoPath: "any-repo", HelmBranch: "main", HelmPath: "/charts/api", Profiles: []string{"default"}},
+			{Name: "db", HelmRepoPath: "any-repo", HelmBranch: "main", HelmPath: "/charts/db", Profiles: []string{"data"}},
+		},
+	}
+	// No cluster expectations are registered, so contacting the cluster fails the test
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	containerOrchestrator.On("RenderService", mock.MatchedBy(func(s *domain.Service) bool { return s.Name == "api" })).
+		Return([]byte("kind: Service\nmetadata:\n  name: api\n"), nil)
+	containerOrchestrator.On("RenderDevProxy", mock.Anything).Return([]byte("kind: Deployment\n"), nil).Maybe()
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("HomeDir").Return("/home/test", nil).Maybe()
+	fileSystem.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	scm := new(testutil.MockScm)
+	scm.On("Download", "any-repo", "main", "/charts/api").Return(nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	devProxyManager := core.ProvideDevProxyManager(
+		configRepository,
+		fileSystem,
+		containerImageRepository,
+		containerOrchestrator,
+		core.ProvideDevProxyConfigGenerator(),
+	)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(configRepository, containerOrchestrator)
+	return ProvideInstallCommandHandler(
+		configRepository,
+		containerImageRepository,
+		containerOrchestrator,
+		devProxyManager,
+		environmentEnsurer,
+		createTestChartDownloader(scm),
+		outputWriter,
+	)
+}
+
+func TestInstallCommandHandler_HandleDryRunWritesManifestsToOutputDir(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	outputWriter := new(testutil.MockOutputWriter)
+	outputWriter.On("WriteFile", mock.Anything, mock.Anything).Return(nil)
+	sut := createDryRunTestInstallCommandHandler(containerOrchestrator, outputWriter)
+
+	result := sut.Handle([]string{}, "default", InstallOptions{DryRun: true, OutputDir: "rendered"})
+
+	assert.NoError(t, result)
+	outputWriter.AssertCalled(t, "WriteFile", filepath.Join("rendered", "api.yaml"), []byte("kind: Service\nmetadata:\n  name: api\n"))
+	outputWriter.AssertCalled(t, "WriteFile", filepath.Join("rendered", "dev-proxy.yaml"), []byte("kind: Deployment\n"))
+	outputWriter.AssertCalled(t, "WriteFile", filepath.Join("rendered", "dev-proxy", "helm", "Chart.yaml"), mock.Anything)
+	outputWriter.AssertCalled(t, "WriteFile", filepath.Join("rendered", "dev-proxy", "haproxy", "haproxy.cfg"), mock.Anything)
+	containerOrchestrator.AssertNotCalled(t, "InstallService", mock.Anything, mock.Anything)
+	containerOrchestrator.AssertNotCalled(t, "GetDevProxyChecksum")
+}
+
+func TestInstallCommandHandler_HandleDryRunSkipsDevProxy(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	outputWriter := new(testutil.MockOutputWriter)
+	outputWriter.On("WriteFile", filepath.Join("rendered", "api.yaml"), mock.Anything).Return(nil)
+	sut := createDryRunTestInstallCommandHandler(containerOrchestrator, outputWriter)
+
+	result := sut.Handle([]string{"api"}, "default", InstallOptions{DryRun: true, OutputDir: "rendered", SkipDevProxy: true})
+
+	assert.NoError(t, result)
+	outputWriter.AssertNumberOfCalls(t, "WriteFile", 1)
+	containerOrchestrator.AssertNotCalled(t, "RenderDevProxy", mock.Anything)
+}
+
+func TestInstallCommandHandler_HandleDryRunPrintsManifestsToStdout(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	outputWriter := new(testutil.MockOutputWriter)
+	sut := createDryRunTestInstallCommandHandler(containerOrchestrator, outputWriter)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	result := sut.Handle([]string{}, "default", InstallOptions{DryRun: true})
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	_, copyErr := io.Copy(&buf, r)
+	require.NoError(t, copyErr)
+
+	assert.NoError(t, result)
+	assert.Equal(t,
+		"---\n# Release: api\nkind: Service\nmetadata:\n  name: api\n---\n# Release: dev-proxy\nkind: Deployment\n",
+		buf.String(),
+	)
+	outputWriter.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+}
+
+func TestInstallCommandHandler_HandleDryRunReturnsRenderError(t *testing.T) {
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("RenderService", mock.Anything).Return(nil, errors.New("helm template failed"))
+	sut := createDryRunTestInstallCommandHandler(containerOrchestrator, new(testutil.MockOutputWriter))
+
+	result := sut.Handle([]string{}, "default", InstallOptions{DryRun: true, OutputDir: "rendered"})
+
+	assert.ErrorContains(t, result, "failed to render service api: helm template failed")
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+
+	"dx/internal/cli/output"
+	"dx/internal/cli/progress"
+	"dx/internal/core"
+)
+
+type ProxyCommandHandler struct {
+	devProxyManager    *core.DevProxyManager
+	environmentEnsurer core.EnvironmentEnsurer
+}
+
+func ProvideProxyCommandHandler(
+	devProxyManager *core.DevProxyManager,
+	environmentEnsurer core.EnvironmentEnsurer,
+) ProxyCommandHandler {
+	return ProxyCommandHandler{
+		devProxyManager:    devProxyManager,
+		environmentEnsurer: environmentEnsurer,
+	}
+}
+
+// HandleDiff prints the differences between the deployed dev-proxy and a freshly generated one.
+func (h *ProxyCommandHandler) HandleDiff() error {
+	err := h.environmentEnsurer.EnsureExpectedClusterIsSelected()
+	if err != nil {
+		return err
+	}
+
+	diff, err := h.devProxyManager.Diff()
+	if err != nil {
+		return err
+	}
+
+	deployedChecksum := diff.DeployedChecksum
+	if deployedChecksum == "" {
+		deployedChecksum = "not deployed"
+	}
+	fmt.Printf("Deployed checksum:  %s\n", output.Dim(deployedChecksum))
+	fmt.Printf("Generated checksum: %s\n", output.Dim(diff.GeneratedChecksum))
+	fmt.Println()
+
+	for _, file := range diff.Files {
+		fromName := file.Path
+		if file.Saved == nil {
+			fromName = "/dev/null"
+		}
+		output.PrintDiff(output.UnifiedDiff(fromName, file.Path, file.Saved, file.Generated))
+		fmt.Println()
+	}
+
+	if diff.DeployedChecksum == diff.GeneratedChecksum {
+		output.PrintSuccess("dev-proxy is up to date")
+		return nil
+	}
+
+	output.PrintInfo("dev-proxy is out of date, run 'dx proxy rebuild' to update it")
+	return nil
+}
+
+// HandleRebuild rebuilds and reinstalls the dev-proxy when its checksum has changed,
+// or unconditionally when force is set.
+func (h *ProxyCommandHandler) HandleRebuild(force bool) error {
+	err := h.environmentEnsurer.EnsureExpectedClusterIsSelected()
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		shouldRebuild, err := h.devProxyManager.ShouldRebuildDevProxy()
+		if err != nil {
+			return err
+		}
+		if !shouldRebuild {
+			output.PrintSuccess("dev-proxy is up to date (use --force to rebuild anyway)")
+			return nil
+		}
+	}
+
+	output.PrintHeader("Rebuilding dev-proxy")
+	fmt.Println()
+
+	tracker := progress.NewTrackerWithInfoAndVerb([]string{"dev-proxy"}, []string{"dx"}, "Rebuilding")
+	tracker.Start()
+	tracker.StartItem(0)
+	err = h.devProxyManager.Rebuild()
+	tracker.CompleteItem(0, err)
+	tracker.PrintItemComplete(0)
+	tracker.Stop()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	output.PrintSuccess("dev-proxy rebuilt")
+	return nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"testing"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func createProxyCommandHandler(
+	configContext *domain.ConfigurationContext,
+	fileSystem *testutil.MockFileSystem,
+	containerImageRepository *testutil.MockContainerImageRepository,
+	containerOrchestrator *testutil.MockContainerOrchestrator,
+) ProxyCommandHandler {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadEnvKey", mock.Anything).Return("any-key", nil)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
+	devProxyManager := core.ProvideDevProxyManager(
+		configRepository,
+		fileSystem,
+		containerImageRepository,
+		containerOrchestrator,
+		core.ProvideDevProxyConfigGenerator(),
+	)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(configRepository, containerOrchestrator)
+
+	return ProvideProxyCommandHandler(devProxyManager, environmentEnsurer)
+}
+
+func TestProxyCommandHandler_HandleRebuildSkipsWhenChecksumUnchanged(t *testing.T) {
+	configContext := &domain.ConfigurationContext{Name: "Test"}
+	checksum, err := core.ProvideDevProxyConfigGenerator().GenerateChecksum(configContext)
+	assert.NoError(t, err)
+	fileSystem := new(testutil.MockFileSystem)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("GetDevProxyChecksum").Return(checksum, nil)
+	sut := createProxyCommandHandler(configContext, fileSystem, containerImageRepository, containerOrchestrator)
+
+	result := sut.HandleRebuild(false)
+
+	assert.Nil(t, result)
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 0)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallDevProxy", 0)
+	fileSystem.AssertNumberOfCalls(t, "WriteFile", 0)
+}
+
+func TestProxyCommandHandler_HandleRebuildForceRebuildsUnchangedProxy(t *testing.T) {
+	configContext := &domain.ConfigurationContext{Name: "Test"}
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	fileSystem.On("HomeDir").Return("/home/test", nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerImageRepository.On("BuildImage", mock.Anything).Return(nil)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("InstallDevProxy", mock.Anything).Return(nil)
+	sut := createProxyCommandHandler(configContext, fileSystem, containerImageRepository, containerOrchestrator)
+
+	result := sut.HandleRebuild(true)
+
+	assert.Nil(t, result)
+	containerOrchestrator.AssertNotCalled(t, "GetDevProxyChecksum")
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 2)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallDevProxy", 1)
+	fileSystem.AssertNumberOfCalls(t, "WriteFile", 5)
+}
+
+func TestProxyCommandHandler_HandleDiffDoesNotModifyAnything(t *testing.T) {
+	configContext := &domain.ConfigurationContext{Name: "Test"}
+	fileSystem := new(testutil.MockFileSystem)
+	fileSystem.On("FileExists", mock.Anything).Return(false, nil)
+	containerImageRepository := new(testutil.MockContainerImageRepository)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("GetDevProxyChecksum").Return("", nil)
+	sut := createProxyCommandHandler(configContext, fileSystem, containerImageRepository, containerOrchestrator)
+
+	result := sut.HandleDiff()
+
+	assert.Nil(t, result)
+	fileSystem.AssertNumberOfCalls(t, "FileExists", 5)
+	fileSystem.AssertNumberOfCalls(t, "WriteFile", 0)
+	containerImageRepository.AssertNumberOfCalls(t, "BuildImage", 0)
+	containerOrchestrator.AssertNumberOfCalls(t, "InstallDevProxy", 0)
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"dx/internal/cli/output"
+	"dx/internal/cli/progress"
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+)
+
+type PruneCommandHandler struct {
+	configRepository      core.ConfigRepository
+	containerOrchestrator ports.ContainerOrchestrator
+	deploymentHistory     *core.DeploymentHistory
+	environmentEnsurer    core.EnvironmentEnsurer
+	terminalInput         ports.TerminalInput
+}
+
+func ProvidePruneCommandHandler(
+	configRepository core.ConfigRepository,
+	containerOrchestrator ports.ContainerOrchestrator,
+	deploymentHistory *core.DeploymentHistory,
+	environmentEnsurer core.EnvironmentEnsurer,
+	terminalInput ports.TerminalInput,
+) PruneCommandHandler {
+	return PruneCommandHandler{
+		configRepository:      configRepository,
+		containerOrchestrator: containerOrchestrator,
+		deploymentHistory:     deploymentHistory,
+		environmentEnsurer:    environmentEnsurer,
+		terminalInput:         terminalInput,
+	}
+}
+
+// Handle uninstalls the releases of the current context whose services are no longer configured,
+// after confirmation. The local state and deployment history of those services are removed as well,
+// also for services whose releases are already gone. With dryRun, they are only listed.
+func (h *PruneCommandHandler) Handle(dryRun bool, skipConfirmation bool) error {
+	err := h.environmentEnsurer.EnsureExpectedClusterIsSelected()
+	if err != nil {
+		return err
+	}
+
+	configContext, err := h.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return err
+	}
+	isOrphan := func(serviceName string) bool {
+		configured := slices.ContainsFunc(configContext.Services, func(s domain.Service) bool { return s.Name == serviceName })
+		return !configured && serviceName != core.DevProxyServiceName
+	}
+
+	deployedServices, err := h.containerOrchestrator.DeployedServices()
+	if err != nil {
+		return err
+	}
+	var orphans []string
+	for _, serviceName := range deployedServices {
+		if isOrphan(serviceName) {
+			orphans = append(orphans, serviceName)
+		}
+	}
+	slices.Sort(orphans)
+
+	// Services without a release that left wrapper charts, kustomize directories, helm values or
+	// deployment history behind
+	localServices, err := h.containerOrchestrator.ServicesWithLocalState()
+	if err != nil {
+		return err
+	}
+	historyServices, err := h.deploymentHistory.Services()
+	if err != nil {
+		return err
+	}
+	var leftovers []string
+	for _, serviceName := range slices.Concat(localServices, historyServices) {
+		if isOrphan(serviceName) && !slices.Contains(orphans, serviceName) && !slices.Contains(leftovers, serviceName) {
+			leftovers = append(leftovers, serviceName)
+		}
+	}
+	slices.Sort(leftovers)
+
+	if len(orphans) == 0 && len(leftovers) == 0 {
+		output.PrintInfo("No orphaned releases or local files found")
+		return nil
+	}
+
+	if len(orphans) > 0 {
+		output.PrintHeader(fmt.Sprintf(
+			"Found %d orphaned %s of services no longer in the configuration",
+			len(orphans),
+			output.Plural(len(orphans), "release", "releases"),
+		))
+		fmt.Println()
+		for _, serviceName := range orphans {
+			fmt.Printf("  %s %s %s\n", output.SymbolBullet, core.ReleaseName(configContext.Name, serviceName), output.Dim(fmt.Sprintf("(service %s)", serviceName)))
+		}
+		fmt.Println()
+	}
+	if len(leftovers) > 0 {
+		output.PrintHeader(fmt.Sprintf(
+			"Found local files of %d %s no longer in the configuration without a release",
+			len(leftovers),
+			output.Plural(len(leftovers), "service", "services"),
+		))
+		fmt.Println()
+		for _, serviceName := range leftovers {
+			fmt.Printf("  %s %s\n", output.SymbolBullet, serviceName)
+		}
+		fmt.Println()
+	}
+
+	if dryRun {
+		output.PrintInfo("Dry run, nothing was removed")
+		return nil
+	}
+
+	if !skipConfirmation {
+		if !h.terminalInput.IsTerminal() {
+			return fmt.Errorf("pruning releases requires confirmation. Use --yes to skip in non-interactive mode")
+		}
+
+		prompt := "Uninstall them? [y/N] "
+		if len(orphans) == 0 {
+			prompt = "Remove them? [y/N] "
+		}
+		response, err := h.terminalInput.ReadLine(prompt)
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			output.PrintInfo("Prune cancelled")
+			return nil
+		}
+		fmt.Println()
+	}
+
+	failed := 0
+	if len(orphans) > 0 {
+		tracker := progress.NewTrackerWithVerb(orphans, "Uninstalling")
+		tracker.Start()
+
+		for i, serviceName := range orphans {
+			tracker.StartItem(i)
+			err := h.containerOrchestrator.UninstallService(&domain.Service{Name: serviceName})
+			if err == nil {
+				err = h.deploymentHistory.Remove(serviceName)
+			}
+			if err != nil {
+				failed++
+			}
+			tracker.CompleteItem(i, err)
+			tracker.PrintItemComplete(i)
+		}
+
+		tracker.Stop()
+		fmt.Println()
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to uninstall %d orphaned %s", failed, output.Plural(failed, "release", "releases"))
+	}
+
+	for _, serviceName := range leftovers {
+		err := h.containerOrchestrator.RemoveLocalState(serviceName)
+		if err == nil {
+			err = h.deploymentHistory.Remove(serviceName)
+		}
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to remove the local files of service %s: %v", serviceName, err))
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove the local files of %d %s", failed, output.Plural(failed, "service", "services"))
+	}
+
+	var pruned []string
+	if len(orphans) > 0 {
+		pruned = append(pruned, fmt.Sprintf("%d %s", len(orphans), output.Plural(len(orphans), "release", "releases")))
+	}
+	if len(leftovers) > 0 {
+		pruned = append(pruned, fmt.Sprintf("the local files of %d %s", len(leftovers), output.Plural(len(leftovers), "service", "services")))
+	}
+	output.PrintSuccess("Pruned " + strings.Join(pruned, " and "))
+	return nil
+}
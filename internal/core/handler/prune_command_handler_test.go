@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestPruneCommandHandler(
+	t *testing.T,
+	deployedServices []string,
+	localServices []string,
+) (PruneCommandHandler, *testutil.MockContainerOrchestrator, *core.DeploymentHistory, *testutil.MockTerminalInput) {
+	configContext := &domain.ConfigurationContext{
+		Name:     "Test",
+		Services: []domain.Service{{Name: "api"}, {Name: "worker"}},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadEnvKey", mock.Anything).Return("any-key", nil)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	configRepository.On("LoadCurrentContextName").Return(configContext.Name, nil)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
+	containerOrchestrator.On("DeployedServices").Return(deployedServices, nil)
+	containerOrchestrator.On("ServicesWithLocalState").Return(localServices, nil)
+	deploymentHistory := core.ProvideDeploymentHistory(configRepository, testutil.NewTestFileSystem(t), new(testutil.MockScm))
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(configRepository, containerOrchestrator)
+	terminalInput := new(testutil.MockTerminalInput)
+	sut := ProvidePruneCommandHandler(configRepository, containerOrchestrator, deploymentHistory, environmentEnsurer, terminalInput)
+	return sut, containerOrchestrator, deploymentHistory, terminalInput
+}
+
+func isService(name string) interface{} {
+	return mock.MatchedBy(func(s *domain.Service) bool { return s.Name == name })
+}
+
+func TestPruneCommandHandler_HandleUninstallsOrphanedReleases(t *testing.T) {
+	sut, containerOrchestrator, _, terminalInput := createTestPruneCommandHandler(t, []string{"dev-proxy", "api", "old-worker", "legacy"}, nil)
+	terminalInput.On("IsTerminal").Return(true)
+	terminalInput.On("ReadLine", "Uninstall them? [y/N] ").Return("y", nil)
+	containerOrchestrator.On("UninstallService", isService("legacy")).Return(nil)
+	containerOrchestrator.On("UninstallService", isService("old-worker")).Return(nil)
+
+	err := sut.Handle(false, false)
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertExpectations(t)
+	containerOrchestrator.AssertNumberOfCalls(t, "UninstallService", 2)
+}
+
+func TestPruneCommandHandler_HandleDryRunUninstallsNothing(t *testing.T) {
+	sut, containerOrchestrator, _, terminalInput := createTestPruneCommandHandler(t, []string{"api", "old-worker"}, nil)
+
+	err := sut.Handle(true, false)
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertNotCalled(t, "UninstallService", mock.Anything)
+	terminalInput.AssertNotCalled(t, "ReadLine", mock.Anything)
+}
+
+func TestPruneCommandHandler_HandleNoOrphans(t *testing.T) {
+	sut, containerOrchestrator, _, terminalInput := createTestPruneCommandHandler(t, []string{"dev-proxy", "api", "worker"}, nil)
+
+	err := sut.Handle(false, false)
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertNotCalled(t, "UninstallService", mock.Anything)
+	terminalInput.AssertNotCalled(t, "ReadLine", mock.Anything)
+}
+
+func TestPruneCommandHandler_HandleCancelled(t *testing.T) {
+	sut, containerOrchestrator, _, terminalInput := createTestPruneCommandHandler(t, []string{"old-worker"}, nil)
+	terminalInput.On("IsTerminal").Return(true)
+	terminalInput.On("ReadLine", "Uninstall them? [y/N] ").Return("n", nil)
+
+	err := sut.Handle(false, false)
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertNotCalled(t, "UninstallService", mock.Anything)
+}
+
+func TestPruneCommandHandler_HandleRequiresConfirmationWhenNotInteractive(t *testing.T) {
+	sut, containerOrchestrator, _, terminalInput := createTestPruneCommandHandler(t, []string{"old-worker"}, nil)
+	terminalInput.On("IsTerminal").Return(false)
+
+	err := sut.Handle(false, false)
+
+	assert.ErrorContains(t, err, "Use --yes to skip")
+	containerOrchestrator.AssertNotCalled(t, "UninstallService", mock.Anything)
+}
+
+func TestPruneCommandHandler_HandleSkipConfirmationReportsFailures(t *testing.T) {
+	sut, containerOrchestrator, _, terminalInput := createTestPruneCommandHandler(t, []string{"legacy", "old-worker"}, nil)
+	containerOrchestrator.On("UninstallService", isService("legacy")).Return(errors.New("helm uninstall failed"))
+	containerOrchestrator.On("UninstallService", isService("old-worker")).Return(nil)
+
+	err := sut.Handle(false, true)
+
+	assert.EqualError(t, err, "failed to uninstall 1 orphaned release")
+	containerOrchestrator.AssertNumberOfCalls(t, "UninstallService", 2)
+	terminalInput.AssertNotCalled(t, "IsTerminal")
+}
+
+func TestPruneCommandHandler_HandleRemovesHistoryOfUninstalledReleases(t *testing.T) {
+	sut, containerOrchestrator, deploymentHistory, _ := createTestPruneCommandHandler(t, []string{"api", "old-worker"}, nil)
+	for _, serviceName := range []string{"api", "old-worker"} {
+		_, err := deploymentHistory.Record(serviceName, domain.Deployment{})
+		require.NoError(t, err)
+	}
+	containerOrchestrator.On("UninstallService", isService("old-worker")).Return(nil)
+
+	err := sut.Handle(false, true)
+
+	assert.NoError(t, err)
+	services, err := deploymentHistory.Services()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api"}, services)
+}
+
+func TestPruneCommandHandler_HandleRemovesLocalFilesWithoutRelease(t *testing.T) {
+	sut, containerOrchestrator, deploymentHistory, terminalInput := createTestPruneCommandHandler(
+		t, []string{"api"}, []string{"api", "dev-proxy", "old-worker", "worker"},
+	)
+	for _, serviceName := range []string{"api", "removed"} {
+		_, err := deploymentHistory.Record(serviceName, domain.Deployment{})
+		require.NoError(t, err)
+	}
+	terminalInput.On("IsTerminal").Return(true)
+	terminalInput.On("ReadLine", "Remove them? [y/N] ").Return("y", nil)
+	containerOrchestrator.On("RemoveLocalState", "old-worker").Return(nil)
+	containerOrchestrator.On("RemoveLocalState", "removed").Return(nil)
+
+	err := sut.Handle(false, false)
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertExpectations(t)
+	containerOrchestrator.AssertNumberOfCalls(t, "RemoveLocalState", 2)
+	containerOrchestrator.AssertNotCalled(t, "UninstallService", mock.Anything)
+	services, err := deploymentHistory.Services()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api"}, services)
+}
+
+func TestPruneCommandHandler_HandleDryRunRemovesNoLocalFiles(t *testing.T) {
+	sut, containerOrchestrator, _, terminalInput := createTestPruneCommandHandler(t, nil, []string{"old-worker"})
+
+	err := sut.Handle(true, false)
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertNotCalled(t, "RemoveLocalState", mock.Anything)
+	terminalInput.AssertNotCalled(t, "ReadLine", mock.Anything)
+}
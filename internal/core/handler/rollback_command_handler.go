@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"dx/internal/cli/output"
+	"dx/internal/core"
+	"dx/internal/ports"
+)
+
+// RollbackOptions controls how a service is rolled back.
+type RollbackOptions struct {
+	// Wait blocks after the rollback until the Deployments, StatefulSets and Jobs of the service are ready
+	Wait    bool
+	Timeout time.Duration
+}
+
+type RollbackCommandHandler struct {
+	configRepository      core.ConfigRepository
+	containerOrchestrator ports.ContainerOrchestrator
+	environmentEnsurer    core.EnvironmentEnsurer
+}
+
+func ProvideRollbackCommandHandler(
+	configRepository core.ConfigRepository,
+	containerOrchestrator ports.ContainerOrchestrator,
+	environmentEnsurer core.EnvironmentEnsurer,
+) RollbackCommandHandler {
+	return RollbackCommandHandler{
+		configRepository:      configRepository,
+		containerOrchestrator: containerOrchestrator,
+		environmentEnsurer:    environmentEnsurer,
+	}
+}
+
+// Handle reinstalls a previous deployment of a service, by its revision in the deployment history or,
+// if revision is 0, the deployment before the latest one. With options.Wait, it waits for the rollout
+// like InstallCommandHandler.Handle.
+func (h *RollbackCommandHandler) Handle(serviceName string, revision int, options RollbackOptions) error {
+	err := h.environmentEnsurer.EnsureExpectedClusterIsSelected()
+	if err != nil {
+		return err
+	}
+
+	configContext, err := h.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return err
+	}
+	service, err := findService(serviceName, configContext.Services)
+	if err != nil {
+		return err
+	}
+
+	deployment, err := h.containerOrchestrator.RollbackService(&service, revision)
+	if err != nil {
+		return fmt.Errorf("failed to roll back service %s: %w", serviceName, err)
+	}
+
+	if options.Wait {
+		output.PrintStep(fmt.Sprintf("Waiting for %s to become ready", serviceName))
+		lastStatus := ""
+		err := h.containerOrchestrator.WaitForService(&service, options.Timeout, func(status string) {
+			if status != lastStatus {
+				lastStatus = status
+				output.PrintSecondary(status)
+			}
+		})
+		if err != nil {
+			printRolloutDiagnostics(err)
+			return fmt.Errorf("rolled back service %s to revision %d, but it is not ready: %w", serviceName, deployment.RollbackOf, err)
+		}
+	}
+
+	output.PrintSuccess(fmt.Sprintf(
+		"Rolled back %s to revision %d (recorded as revision %d)",
+		serviceName, deployment.RollbackOf, deployment.Revision,
+	))
+	return nil
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"dx/internal/core"
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func createTestRollbackCommandHandler() (RollbackCommandHandler, *testutil.MockContainerOrchestrator) {
+	configContext := &domain.ConfigurationContext{
+		Name:     "Test",
+		Services: []domain.Service{{Name: "api"}, {Name: "worker"}},
+	}
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadEnvKey", mock.Anything).Return("any-key", nil)
+	configRepository.On("LoadCurrentConfigurationContext").Return(configContext, nil)
+	containerOrchestrator := new(testutil.MockContainerOrchestrator)
+	containerOrchestrator.On("CreateClusterEnvironmentKey").Return("any-key", nil)
+	environmentEnsurer := core.ProvideEnvironmentEnsurer(configRepository, containerOrchestrator)
+	return ProvideRollbackCommandHandler(configRepository, containerOrchestrator, environmentEnsurer), containerOrchestrator
+}
+
+func TestRollbackCommandHandler_HandleRollsBackService(t *testing.T) {
+	sut, containerOrchestrator := createTestRollbackCommandHandler()
+	containerOrchestrator.On("RollbackService", mock.MatchedBy(func(s *domain.Service) bool { return s.Name == "worker" }), 2).
+		Return(domain.Deployment{Revision: 4, RollbackOf: 2}, nil)
+
+	err := sut.Handle("worker", 2, RollbackOptions{})
+
+	assert.NoError(t, err)
+	containerOrchestrator.AssertExpectations(t)
+}
+
+func TestRollbackCommandHandler_HandleWaitsForRollout(t *testing.T) {
+	sut, containerOrchestrator := createTestRollbackCommandHandler()
+	containerOrchestrator.On("RollbackService", mock.Anything, 0).Return(domain.Deployment{Revision: 4, RollbackOf: 2}, nil)
+	containerOrchestrator.On("WaitForService", mock.MatchedBy(func(s *domain.Service) bool { return s.Name == "api" }), time.Minute, mock.Anything).
+		Return(&ports.RolloutError{Service: "api", Workload: "deployment/api", Reason: "timed out"})
+
+	err := sut.Handle("api", 0, RollbackOptions{Wait: true, Timeout: time.Minute})
+
+	assert.EqualError(t, err, "rolled back service api to revision 2, but it is not ready: service api is not ready: deployment/api timed out")
+	containerOrchestrator.AssertExpectations(t)
+}
+
+func TestRollbackCommandHandler_HandleRollbackFails(t *testing.T) {
+	sut, containerOrchestrator := createTestRollbackCommandHandler()
+	containerOrchestrator.On("RollbackService", mock.Anything, 0).
+		Return(domain.Deployment{}, errors.New("service api has no previous deployment to roll back to"))
+
+	err := sut.Handle("api", 0, RollbackOptions{})
+
+	assert.EqualError(t, err, "failed to roll back service api: service api has no previous deployment to roll back to")
+}
+
+func TestRollbackCommandHandler_HandleUnknownService(t *testing.T) {
+	sut, containerOrchestrator := createTestRollbackCommandHandler()
+
+	err := sut.Handle("unknown", 0, RollbackOptions{})
+
+	assert.EqualError(t, err, "service 'unknown' not found")
+	containerOrchestrator.AssertNotCalled(t, "RollbackService", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"dx/internal/cli/output"
+	"dx/internal/cli/progress"
+	"dx/internal/core"
+	"dx/internal/ports"
+)
+
+// WatchOptions controls how services are rebuilt and reinstalled on changes.
+type WatchOptions struct {
+	// Debounce is how long to wait after the last change before updating the changed services
+	Debounce time.Duration
+	Build    BuildOptions
+	Install  InstallOptions
+}
+
+// serviceBuilder builds the images of services, see BuildCommandHandler.
+type serviceBuilder interface {
+	Handle(services []string, selectedProfile string, options BuildOptions) error
+}
+
+// serviceInstaller installs services, see InstallCommandHandler.
+type serviceInstaller interface {
+	Handle(services []string, selectedProfile string, options InstallOptions) error
+}
+
+type WatchCommandHandler struct {
+	configRepository core.ConfigRepository
+	scm              ports.Scm
+	fileWatcher      ports.FileWatcher
+	builder          serviceBuilder
+	installer        serviceInstaller
+	statusLine       *progress.StatusLine
+}
+
+func ProvideWatchCommandHandler(
+	configRepository core.ConfigRepository,
+	scm ports.Scm,
+	fileWatcher ports.FileWatcher,
+	buildHandler BuildCommandHandler,
+	installHandler InstallCommandHandler,
+) WatchCommandHandler {
+	return WatchCommandHandler{
+		configRepository: configRepository,
+		scm:              scm,
+		fileWatcher:      fileWatcher,
+		builder:          &buildHandler,
+		installer:        &installHandler,
+		statusLine:       progress.NewStatusLine(),
+	}
+}
+
+// watchedSources holds the sources of the watched services.
+type watchedSources struct {
+	// services maps watched directories to the services built from them
+	services map[string][]string
+	// worktrees maps each service to the checkouts its images are built from
+	worktrees map[string]map[string]string
+}
+
+// Handle watches the build contexts of the images of the selected services until ctx is done. When
+// files change, the affected services are rebuilt from their sources as they are and reinstalled.
+// Only local paths and sources that have already been checked out are watched. Failed updates are
+// reported and watching continues.
+func (h *WatchCommandHandler) Handle(
+	ctx context.Context,
+	services []string,
+	selectedProfile string,
+	options WatchOptions,
+) error {
+	sources, err := h.findSources(services, selectedProfile)
+	if err != nil {
+		return err
+	}
+
+	directories := slices.Sorted(maps.Keys(sources.services))
+	changes, err := h.fileWatcher.Watch(ctx, directories)
+	if err != nil {
+		return err
+	}
+
+	output.PrintHeader("Watching for changes")
+	for _, directory := range directories {
+		fmt.Printf("  %s %s  %s\n", output.Dim(output.SymbolBullet), strings.Join(sources.services[directory], ", "), output.Dim(directory))
+	}
+	fmt.Println()
+
+	watching := fmt.Sprintf(
+		"Watching %d %s for changes (Ctrl+C to stop)",
+		len(sources.worktrees),
+		output.Plural(len(sources.worktrees), "service", "services"),
+	)
+	h.statusLine.Set(watching)
+	defer h.statusLine.Clear()
+
+	pending := make(map[string]bool)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case directory, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			for _, service := range sources.services[directory] {
+				pending[service] = true
+			}
+			h.statusLine.Set(fmt.Sprintf("Changes in %s, updating shortly", strings.Join(slices.Sorted(maps.Keys(pending)), ", ")))
+			debounce = time.After(options.Debounce)
+		case <-debounce:
+			debounce = nil
+			h.statusLine.Clear()
+			var results []string
+			for _, service := range slices.Sorted(maps.Keys(pending)) {
+				results = append(results, h.update(service, selectedProfile, sources.worktrees[service], options))
+			}
+			clear(pending)
+			fmt.Println()
+			h.statusLine.Set(fmt.Sprintf("%s | %s", watching, strings.Join(results, ", ")))
+		}
+	}
+}
+
+// findSources returns the build contexts of the images of the selected services that can be
+// watched. Images whose source hasn't been checked out yet are skipped with a warning.
+func (h *WatchCommandHandler) findSources(services []string, selectedProfile string) (watchedSources, error) {
+	configContext, err := h.configRepository.LoadCurrentConfigurationContext()
+	if err != nil {
+		return watchedSources{}, err
+	}
+
+	sources := watchedSources{
+		services:  make(map[string][]string),
+		worktrees: make(map[string]map[string]string),
+	}
+	for _, service := range configContext.Services {
+		if len(services) == 0 && !slices.Contains(service.Profiles, selectedProfile) {
+			continue
+		}
+
+		if len(services) > 0 && !slices.Contains(services, service.Name) {
+			continue
+		}
+
+		for _, image := range service.DockerImages {
+			if image.LocalPath == "" {
+				if _, err := h.scm.Revision(image.Path); err != nil {
+					output.PrintWarning(fmt.Sprintf(
+						"Not watching %s: its source hasn't been checked out yet, run 'dx build %s' first",
+						image.Name,
+						service.Name,
+					))
+					continue
+				}
+			}
+
+			directory := filepath.Join(image.Path, image.BuildContextRelativePath)
+			if !slices.Contains(sources.services[directory], service.Name) {
+				sources.services[directory] = append(sources.services[directory], service.Name)
+			}
+			if sources.worktrees[service.Name] == nil {
+				sources.worktrees[service.Name] = make(map[string]string)
+			}
+			sources.worktrees[service.Name][image.Name] = image.Path
+		}
+	}
+
+	if len(sources.services) == 0 {
+		return watchedSources{}, fmt.Errorf("no sources to watch for the selected services")
+	}
+	return sources, nil
+}
+
+// update builds the images of the service from the watched checkouts and reinstalls it. Returns a
+// short description of the outcome.
+func (h *WatchCommandHandler) update(
+	service string,
+	selectedProfile string,
+	worktrees map[string]string,
+	options WatchOptions,
+) string {
+	startTime := time.Now()
+	buildOptions := options.Build
+	buildOptions.Worktrees = worktrees
+
+	err := h.builder.Handle([]string{service}, selectedProfile, buildOptions)
+	if err == nil {
+		err = h.installer.Handle([]string{service}, selectedProfile, options.Install)
+	}
+
+	finishedAt := time.Now().Format("15:04:05")
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to update %s: %v", service, err))
+		return fmt.Sprintf("%s failed at %s", service, finishedAt)
+	}
+	return fmt.Sprintf("%s updated at %s in %s", service, finishedAt, progress.FormatDuration(time.Since(startTime)))
+}
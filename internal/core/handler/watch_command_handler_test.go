@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"dx/internal/cli/progress"
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServiceUpdater records the services it is asked to build or install.
+type fakeServiceUpdater struct {
+	mu       sync.Mutex
+	services []string
+	options  []BuildOptions
+	err      error
+	updated  chan struct{}
+}
+
+func newFakeServiceUpdater() *fakeServiceUpdater {
+	return &fakeServiceUpdater{updated: make(chan struct{}, 10)}
+}
+
+func (f *fakeServiceUpdater) record(services []string) error {
+	f.mu.Lock()
+	f.services = append(f.services, services...)
+	f.mu.Unlock()
+	f.updated <- struct{}{}
+	return f.err
+}
+
+func (f *fakeServiceUpdater) recorded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.services...)
+}
+
+type fakeBuilder struct{ *fakeServiceUpdater }
+
+func (f fakeBuilder) Handle(services []string, _ string, options BuildOptions) error {
+	f.mu.Lock()
+	f.options = append(f.options, options)
+	f.mu.Unlock()
+	return f.record(services)
+}
+
+type fakeInstaller struct{ *fakeServiceUpdater }
+
+func (f fakeInstaller) Handle(services []string, _ string, _ InstallOptions) error {
+	return f.record(services)
+}
+
+func waitForUpdate(t *testing.T, updater *fakeServiceUpdater) {
+	t.Helper()
+	select {
+	case <-updater.updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("service not updated")
+	}
+}
+
+func createTestWatchContext() *domain.ConfigurationContext {
+	return &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name:     "api",
+				Profiles: []string{"default"},
+				DockerImages: []domain.DockerImage{
+					{Name: "api", LocalPath: "/src/api", Path: "/src/api", BuildContextRelativePath: "."},
+					{Name: "api-migrations", Path: "/checkouts/api", BuildContextRelativePath: "migrations"},
+				},
+			},
+			{
+				Name:     "worker",
+				Profiles: []string{"default"},
+				DockerImages: []domain.DockerImage{
+					{Name: "worker", Path: "/checkouts/worker", BuildContextRelativePath: "."},
+				},
+			},
+			{
+				Name:     "frontend",
+				Profiles: []string{"default"},
+				DockerImages: []domain.DockerImage{
+					{Name: "frontend", Path: "/checkouts/frontend", BuildContextRelativePath: "."},
+				},
+			},
+		},
+	}
+}
+
+func TestWatchCommandHandler_HandleUpdatesOnlyChangedServices(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(createTestWatchContext(), nil)
+	scm := new(testutil.MockScm)
+	scm.On("Revision", "/checkouts/api").Return("any-revision", nil)
+	scm.On("Revision", "/checkouts/worker").Return("any-revision", nil)
+	scm.On("Revision", "/checkouts/frontend").Return("", errors.New("not a git repository"))
+	changes := make(chan string)
+	fileWatcher := new(testutil.MockFileWatcher)
+	fileWatcher.On("Watch", mock.Anything, []string{"/checkouts/api/migrations", "/checkouts/worker", "/src/api"}).
+		Return(changes, nil)
+	builder := fakeBuilder{newFakeServiceUpdater()}
+	installer := fakeInstaller{newFakeServiceUpdater()}
+	sut := WatchCommandHandler{
+		configRepository: configRepository,
+		scm:              scm,
+		fileWatcher:      fileWatcher,
+		builder:          builder,
+		installer:        installer,
+		statusLine:       progress.NewStatusLine(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sut.Handle(ctx, []string{}, "default", WatchOptions{Debounce: 10 * time.Millisecond, Build: BuildOptions{Jobs: 2}})
+	}()
+
+	// Changes to both sources of the api are debounced into a single update
+	changes <- "/src/api"
+	changes <- "/checkouts/api/migrations"
+	waitForUpdate(t, builder.fakeServiceUpdater)
+	waitForUpdate(t, installer.fakeServiceUpdater)
+	changes <- "/checkouts/worker"
+	waitForUpdate(t, builder.fakeServiceUpdater)
+	waitForUpdate(t, installer.fakeServiceUpdater)
+	cancel()
+
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"api", "worker"}, builder.recorded())
+	assert.Equal(t, []string{"api", "worker"}, installer.recorded())
+	assert.Equal(
+		t,
+		BuildOptions{Jobs: 2, Worktrees: map[string]string{"api": "/src/api", "api-migrations": "/checkouts/api"}},
+		builder.options[0],
+	)
+}
+
+func TestWatchCommandHandler_HandleContinuesAfterFailedUpdate(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(createTestWatchContext(), nil)
+	scm := new(testutil.MockScm)
+	scm.On("Revision", mock.Anything).Return("any-revision", nil)
+	changes := make(chan string)
+	fileWatcher := new(testutil.MockFileWatcher)
+	fileWatcher.On("Watch", mock.Anything, mock.Anything).Return(changes, nil)
+	builder := fakeBuilder{newFakeServiceUpdater()}
+	builder.err = errors.New("build failed")
+	installer := fakeInstaller{newFakeServiceUpdater()}
+	sut := WatchCommandHandler{
+		configRepository: configRepository,
+		scm:              scm,
+		fileWatcher:      fileWatcher,
+		builder:          builder,
+		installer:        installer,
+		statusLine:       progress.NewStatusLine(),
+	}
+	done := make(chan error)
+	go func() {
+		done <- sut.Handle(context.Background(), []string{"worker"}, "default", WatchOptions{Debounce: time.Millisecond})
+	}()
+
+	changes <- "/checkouts/worker"
+	waitForUpdate(t, builder.fakeServiceUpdater)
+	changes <- "/checkouts/worker"
+	waitForUpdate(t, builder.fakeServiceUpdater)
+	close(changes)
+
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"worker", "worker"}, builder.recorded())
+	assert.Empty(t, installer.recorded())
+}
+
+func TestWatchCommandHandler_HandleFailsWithoutSources(t *testing.T) {
+	configRepository := new(testutil.MockConfigRepository)
+	configRepository.On("LoadCurrentConfigurationContext").Return(createTestWatchContext(), nil)
+	scm := new(testutil.MockScm)
+	scm.On("Revision", "/checkouts/frontend").Return("", errors.New("not a git repository"))
+	sut := WatchCommandHandler{configRepository: configRepository, scm: scm}
+
+	err := sut.Handle(context.Background(), []string{"frontend"}, "default", WatchOptions{})
+
+	assert.EqualError(t, err, "no sources to watch for the selected services")
+}
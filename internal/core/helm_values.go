@@ -0,0 +1,69 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenderHelmValues renders the values files and inline values of a service in the order they are passed
+// to helm: the values files first, then the inline values, which take precedence.
+// Relative values file paths are resolved against the chart repository.
+func RenderHelmValues(
+	service *domain.Service,
+	templater ports.Templater,
+	templateValues map[string]interface{},
+) ([][]byte, error) {
+	var values [][]byte
+	for i, valuesFile := range service.HelmValuesFiles {
+		content, err := readHelmValuesFile(service, valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read helm values file %s: %w", valuesFile, err)
+		}
+		rendered, err := templater.Render(string(content), fmt.Sprintf("helm-values-files.%d", i), templateValues)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, []byte(rendered))
+	}
+
+	if len(service.HelmValues) > 0 {
+		rendered, err := renderTemplatedValue(service.HelmValues, "helm-values", templater, templateValues)
+		if err != nil {
+			return nil, err
+		}
+		content, err := yaml.Marshal(rendered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal helm values: %w", err)
+		}
+		values = append(values, content)
+	}
+	return values, nil
+}
+
+// readHelmValuesFile reads a values file of a service, resolving relative paths against the chart repository.
+func readHelmValuesFile(service *domain.Service, valuesFile string) ([]byte, error) {
+	path := valuesFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(service.HelmPath, path)
+	}
+	// We use os.ReadFile directly since the restricted FileSystem is only for ~/.dx/ paths.
+	return os.ReadFile(path)
+}
+
+// helmValuesTemplates returns the values of a service that may contain template references. Values files
+// that can't be read yet, e.g. because the chart repository hasn't been cloned, are skipped.
+func helmValuesTemplates(service *domain.Service) []string {
+	var templates []string
+	for _, valuesFile := range service.HelmValuesFiles {
+		if content, err := readHelmValuesFile(service, valuesFile); err == nil {
+			templates = append(templates, string(content))
+		}
+	}
+	return append(templates, valueStrings(service.HelmValues)...)
+}
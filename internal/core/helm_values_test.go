@@ -0,0 +1,59 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHelmValues_RendersFilesThenInlineValues(t *testing.T) {
+	chartRepo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(chartRepo, "values"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(chartRepo, "values", "dev.yaml"), []byte("replicas: 1\n"), 0644))
+	absolutePath := filepath.Join(t.TempDir(), "secrets.yaml")
+	require.NoError(t, os.WriteFile(absolutePath, []byte("password: {{ .Secrets.DB_PASSWORD }}\n"), 0644))
+	service := &domain.Service{
+		Name:            "api",
+		HelmPath:        chartRepo,
+		HelmValuesFiles: []string{"values/dev.yaml", absolutePath},
+		HelmValues: map[string]interface{}{
+			"image": map[string]interface{}{"tag": "{{ .Secrets.TAG }}"},
+			"debug": true,
+		},
+	}
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", "replicas: 1\n", "helm-values-files.0", mock.Anything).Return("replicas: 1\n", nil)
+	templater.On("Render", "password: {{ .Secrets.DB_PASSWORD }}\n", "helm-values-files.1", mock.Anything).
+		Return("password: secret\n", nil)
+	templater.On("Render", "{{ .Secrets.TAG }}", "helm-values.image.tag", mock.Anything).Return("v2", nil)
+
+	values, err := RenderHelmValues(service, templater, nil)
+
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	assert.Equal(t, "replicas: 1\n", string(values[0]))
+	assert.Equal(t, "password: secret\n", string(values[1]))
+	assert.Equal(t, "debug: true\nimage:\n    tag: v2\n", string(values[2]))
+}
+
+func TestRenderHelmValues_NoValues(t *testing.T) {
+	values, err := RenderHelmValues(&domain.Service{Name: "api"}, new(testutil.MockTemplater), nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestRenderHelmValues_MissingFile(t *testing.T) {
+	service := &domain.Service{Name: "api", HelmPath: t.TempDir(), HelmValuesFiles: []string{"values/missing.yaml"}}
+
+	_, err := RenderHelmValues(service, new(testutil.MockTemplater), nil)
+
+	assert.ErrorContains(t, err, "failed to read helm values file values/missing.yaml")
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+)
+
+// shortShaLength is the number of characters of the commit SHA in .Git.ShortSha
+const shortShaLength = 7
+
+// invalidTagCharacters matches the characters that aren't allowed in image tags
+var invalidTagCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// IsTemplatedImageName reports whether the name of the image depends on the revision of its source.
+func IsTemplatedImageName(image domain.DockerImage) bool {
+	return strings.Contains(image.Name, "{{")
+}
+
+// ResolveImageName renders the name of the image with the git revision of its source, which must
+// already be downloaded. Names can use .Git.Sha, .Git.ShortSha, .Git.Branch and .Git.Dirty. The
+// branch is the configured gitRef, or the branch checked out in localPath, with characters that
+// aren't allowed in tags replaced by '-'. Dirty is only ever true for images built from localPath.
+// Names without templates are returned as they are.
+func ResolveImageName(image domain.DockerImage, scm ports.Scm, templater ports.Templater) (string, error) {
+	if !IsTemplatedImageName(image) {
+		return image.Name, nil
+	}
+
+	sha, err := scm.Revision(image.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get revision of %s: %w", image.Name, err)
+	}
+
+	branch := image.GitRef
+	dirty := false
+	if image.LocalPath != "" {
+		if branch, err = scm.Branch(image.Path); err != nil {
+			return "", err
+		}
+		if dirty, err = scm.HasUncommittedChanges(image.Path); err != nil {
+			return "", fmt.Errorf("failed to get status of %s: %w", image.Name, err)
+		}
+	}
+
+	values := map[string]interface{}{
+		"Git": map[string]interface{}{
+			"Sha":      sha,
+			"ShortSha": sha[:min(shortShaLength, len(sha))],
+			"Branch":   invalidTagCharacters.ReplaceAllString(branch, "-"),
+			"Dirty":    dirty,
+		},
+	}
+	return templater.Render(image.Name, "image-name", values)
+}
+
+// ImageNameWithoutTag returns the image reference without its tag or digest.
+func ImageNameWithoutTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
+// imagesTemplater fails to render templates that refer to images missing from .Images, which would
+// otherwise be rendered as "<no value>" with a warning.
+type imagesTemplater struct {
+	ports.Templater
+	images map[string]interface{}
+}
+
+// NewImagesTemplater returns a templater for templates rendered with the image references as .Images,
+// see BuildCache.ImageReferences. Rendering fails with an error naming the image when a template
+// refers to an image as .Images.<name> or (index .Images "<name>") that isn't configured or hasn't
+// been built yet.
+func NewImagesTemplater(templater ports.Templater, images map[string]interface{}) ports.Templater {
+	return &imagesTemplater{Templater: templater, images: images}
+}
+
+func (t *imagesTemplater) Render(templateText string, templateName string, values map[string]interface{}) (string, error) {
+	// Templates that don't parse are left to the templater to report
+	if tmpl, err := template.New(templateName).Parse(templateText); err == nil && tmpl.Tree != nil {
+		for _, name := range referencedImages(tmpl.Tree.Root, true) {
+			if _, ok := t.images[name]; !ok {
+				return "", fmt.Errorf(
+					"%s refers to image %s, which isn't configured or hasn't been built yet", templateName, name,
+				)
+			}
+		}
+	}
+	return t.Templater.Render(templateText, templateName, values)
+}
+
+// referencedImages returns the names of the images the template refers to in .Images. The dot is
+// only the template values outside of range and with blocks, elsewhere only $.Images is considered.
+func referencedImages(node parse.Node, rootDot bool) []string {
+	var names []string
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, child := range node.Nodes {
+			names = append(names, referencedImages(child, rootDot)...)
+		}
+	case *parse.ActionNode:
+		names = referencedImages(node.Pipe, rootDot)
+	case *parse.TemplateNode:
+		names = referencedImages(node.Pipe, rootDot)
+	case *parse.IfNode:
+		names = referencedBranchImages(&node.BranchNode, rootDot, rootDot)
+	case *parse.RangeNode:
+		names = referencedBranchImages(&node.BranchNode, false, rootDot)
+	case *parse.WithNode:
+		names = referencedBranchImages(&node.BranchNode, false, rootDot)
+	case *parse.PipeNode:
+		if node == nil {
+			return nil
+		}
+		for _, command := range node.Cmds {
+			names = append(names, referencedImages(command, rootDot)...)
+		}
+	case *parse.CommandNode:
+		if name, ok := indexedImage(node, rootDot); ok {
+			names = append(names, name)
+		}
+		for _, arg := range node.Args {
+			names = append(names, referencedImages(arg, rootDot)...)
+		}
+	case *parse.FieldNode:
+		if rootDot && len(node.Ident) > 1 && node.Ident[0] == "Images" {
+			names = append(names, node.Ident[1])
+		}
+	case *parse.VariableNode:
+		if len(node.Ident) > 2 && node.Ident[0] == "$" && node.Ident[1] == "Images" {
+			names = append(names, node.Ident[2])
+		}
+	case *parse.ChainNode:
+		names = referencedImages(node.Node, rootDot)
+	}
+	return names
+}
+
+func referencedBranchImages(node *parse.BranchNode, listRootDot bool, rootDot bool) []string {
+	names := referencedImages(node.Pipe, rootDot)
+	names = append(names, referencedImages(node.List, listRootDot)...)
+	return append(names, referencedImages(node.ElseList, rootDot)...)
+}
+
+// indexedImage returns the name of the image of an (index .Images "<name>") command.
+func indexedImage(command *parse.CommandNode, rootDot bool) (string, bool) {
+	if len(command.Args) < 3 {
+		return "", false
+	}
+	if identifier, ok := command.Args[0].(*parse.IdentifierNode); !ok || identifier.Ident != "index" {
+		return "", false
+	}
+
+	images := false
+	switch arg := command.Args[1].(type) {
+	case *parse.FieldNode:
+		images = rootDot && len(arg.Ident) == 1 && arg.Ident[0] == "Images"
+	case *parse.VariableNode:
+		images = len(arg.Ident) == 2 && arg.Ident[0] == "$" && arg.Ident[1] == "Images"
+	}
+	name, ok := command.Args[2].(*parse.StringNode)
+	if !images || !ok {
+		return "", false
+	}
+	return name.Text, true
+}
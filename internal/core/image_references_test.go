@@ -0,0 +1,123 @@
+package core
+
+import (
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveImageName_UntemplatedNameIsUnchanged(t *testing.T) {
+	scm := new(testutil.MockScm)
+
+	name, err := ResolveImageName(domain.DockerImage{Name: "api:latest"}, scm, new(testutil.MockTemplater))
+
+	require.NoError(t, err)
+	assert.Equal(t, "api:latest", name)
+	scm.AssertNotCalled(t, "Revision")
+}
+
+func TestResolveImageName_UsesRevisionOfGitSource(t *testing.T) {
+	scm := new(testutil.MockScm)
+	scm.On("Revision", "/source").Return("0123456789abcdef", nil)
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", "api:{{.Git.ShortSha}}", "image-name", map[string]interface{}{
+		"Git": map[string]interface{}{
+			"Sha":      "0123456789abcdef",
+			"ShortSha": "0123456",
+			"Branch":   "release-1.2",
+			"Dirty":    false,
+		},
+	}).Return("api:0123456", nil)
+	image := domain.DockerImage{Name: "api:{{.Git.ShortSha}}", GitRef: "release/1.2", Path: "/source"}
+
+	name, err := ResolveImageName(image, scm, templater)
+
+	require.NoError(t, err)
+	assert.Equal(t, "api:0123456", name)
+}
+
+func TestResolveImageName_UsesBranchAndStatusOfLocalPath(t *testing.T) {
+	scm := new(testutil.MockScm)
+	scm.On("Revision", "/src/api").Return("abc", nil)
+	scm.On("Branch", "/src/api").Return("feature/login", nil)
+	scm.On("HasUncommittedChanges", "/src/api").Return(true, nil)
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", "api:{{.Git.Branch}}", "image-name", map[string]interface{}{
+		"Git": map[string]interface{}{
+			"Sha":      "abc",
+			"ShortSha": "abc",
+			"Branch":   "feature-login",
+			"Dirty":    true,
+		},
+	}).Return("api:feature-login", nil)
+	image := domain.DockerImage{Name: "api:{{.Git.Branch}}", LocalPath: "/src/api", Path: "/src/api"}
+
+	name, err := ResolveImageName(image, scm, templater)
+
+	require.NoError(t, err)
+	assert.Equal(t, "api:feature-login", name)
+}
+
+func TestImageNameWithoutTag(t *testing.T) {
+	tests := map[string]string{
+		"api":                             "api",
+		"api:latest":                      "api",
+		"api:{{.Git.ShortSha}}":           "api",
+		"localhost:5001/api":              "localhost:5001/api",
+		"localhost:5001/api:1.0":          "localhost:5001/api",
+		"ghcr.io/acme/api@sha256:abc":     "ghcr.io/acme/api",
+		"ghcr.io/acme/api:1.0@sha256:abc": "ghcr.io/acme/api",
+	}
+
+	for image, expected := range tests {
+		assert.Equal(t, expected, ImageNameWithoutTag(image), image)
+	}
+}
+
+func TestImagesTemplater_RendersTemplatesReferringToKnownImages(t *testing.T) {
+	images := map[string]interface{}{
+		"api":      map[string]interface{}{"ref": "api:0123456"},
+		"acme/web": map[string]interface{}{"ref": "acme/web:1.0"},
+	}
+	templates := []string{
+		"--set=image={{.Images.api.ref}}",
+		`--set=image={{index .Images "acme/web" "ref"}}`,
+		"{{range .Items}}{{.Images.other}}{{end}}",
+		"{{with .Images}}{{.api.ref}}{{end}}",
+		"{{.Values.Images.other}}",
+	}
+
+	for _, tmpl := range templates {
+		templater := new(testutil.MockTemplater)
+		templater.On("Render", tmpl, "helm-args.0", map[string]interface{}{"Images": images}).Return("rendered", nil)
+
+		rendered, err := NewImagesTemplater(templater, images).Render(tmpl, "helm-args.0", map[string]interface{}{"Images": images})
+
+		require.NoError(t, err, tmpl)
+		assert.Equal(t, "rendered", rendered, tmpl)
+	}
+}
+
+func TestImagesTemplater_FailsOnMissingImages(t *testing.T) {
+	images := map[string]interface{}{"api": map[string]interface{}{"ref": "api:0123456"}}
+	templates := []string{
+		"--set=image={{.Images.worker.ref}}",
+		"{{if .Enabled}}{{.Images.worker.ref}}{{end}}",
+		"{{range .Items}}{{$.Images.worker.ref}}{{end}}",
+		`{{index .Images "worker" "ref"}}`,
+		`{{index $.Images "worker" | printf "%v"}}`,
+	}
+
+	for _, tmpl := range templates {
+		templater := new(testutil.MockTemplater)
+
+		_, err := NewImagesTemplater(templater, images).Render(tmpl, "helm-args.0", map[string]interface{}{"Images": images})
+
+		assert.EqualError(t, err, "helm-args.0 refers to image worker, which isn't configured or hasn't been built yet", tmpl)
+		templater.AssertNotCalled(t, "Render")
+	}
+}
@@ -0,0 +1,32 @@
+package core
+
+// ContextLabel is the label that marks Kubernetes resources and Helm releases with the dx context
+// that owns them, so the releases of a context can be told apart from those of other tools, or of
+// other contexts whose namespace defaults to the same namespace of the kube context.
+const ContextLabel = "dx-context"
+
+// ReleaseName returns the Helm release name of a service in a context.
+// Release names are prefixed with the context name, so releases of different contexts don't collide.
+// The chart is still rendered with the service name, see domain.Config.Validate.
+func ReleaseName(contextName, serviceName string) string {
+	return contextName + "-" + serviceName
+}
+
+// DevProxyServiceName is the name of the service the dev-proxy of each context is installed as.
+const DevProxyServiceName = "dev-proxy"
+
+// DevProxyName returns the name of the dev-proxy Deployment and Service, and the value of
+// their app label, for a context. This must match the names used in the dev-proxy Helm template.
+func DevProxyName(contextName string) string {
+	return "dev-proxy-" + contextName
+}
+
+// DevProxyHAProxyImage returns the name of the HAProxy image of the dev-proxy of a context.
+func DevProxyHAProxyImage(contextName string) string {
+	return "henriq/haproxy-" + contextName
+}
+
+// DevProxyMitmproxyImage returns the name of the mitmproxy image of the dev-proxy of a context.
+func DevProxyMitmproxyImage(contextName string) string {
+	return "henriq/mitmproxy-" + contextName
+}
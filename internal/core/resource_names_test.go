@@ -0,0 +1,25 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseName(t *testing.T) {
+	assert.Equal(t, "my-context-api", ReleaseName("my-context", "api"))
+}
+
+func TestDevProxyName(t *testing.T) {
+	assert.Equal(t, "dev-proxy-my-context", DevProxyName("my-context"))
+}
+
+func TestDevProxyName_MatchesTemplate(t *testing.T) {
+	configs, err := ProvideDevProxyConfigGenerator().Generate(createTestConfigContext())
+
+	assert.NoError(t, err)
+	deployment := string(configs.HelmDeploymentYaml)
+	assert.Contains(t, deployment, "name: "+DevProxyName("test-context")+"\n")
+	assert.Contains(t, deployment, "app: "+DevProxyName("test-context")+"\n")
+	assert.Contains(t, deployment, ContextLabel+": test-context\n")
+}
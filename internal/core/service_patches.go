@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+)
+
+// RenderServicePatches renders the templated values of the patches of a service into kustomize patches.
+// Templated operation values are rendered as strings.
+func RenderServicePatches(
+	service *domain.Service,
+	templater ports.Templater,
+	templateValues map[string]interface{},
+) ([]ports.Patch, error) {
+	var patches []ports.Patch
+	for i, servicePatch := range service.Patches {
+		patch := ports.Patch{
+			Target: ports.PatchTarget{Kind: servicePatch.Target.Kind, Name: servicePatch.Target.Name},
+		}
+
+		for j, op := range servicePatch.Operations {
+			value, err := renderTemplatedValue(op.Value, fmt.Sprintf("patches.%d.operations.%d.value", i, j), templater, templateValues)
+			if err != nil {
+				return nil, err
+			}
+			patch.JSONPatch = append(patch.JSONPatch, ports.PatchOperation{Op: op.Op, Path: op.Path, From: op.From, Value: value})
+		}
+
+		if servicePatch.Patch != "" {
+			rendered, err := templater.Render(servicePatch.Patch, fmt.Sprintf("patches.%d.patch", i), templateValues)
+			if err != nil {
+				return nil, err
+			}
+			patch.StrategicMerge = rendered
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// renderTemplatedValue renders the strings of a YAML value, including those nested in maps and lists.
+func renderTemplatedValue(
+	value interface{},
+	name string,
+	templater ports.Templater,
+	templateValues map[string]interface{},
+) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return templater.Render(v, name, templateValues)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renderedItem, err := renderTemplatedValue(item, name+"."+key, templater, templateValues)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = renderedItem
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			renderedItem, err := renderTemplatedValue(item, fmt.Sprintf("%s.%d", name, i), templater, templateValues)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = renderedItem
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+// patchTemplates returns the strings of a patch that may contain template references.
+func patchTemplates(patch domain.ServicePatch) []string {
+	templates := []string{patch.Patch}
+	for _, op := range patch.Operations {
+		templates = append(templates, valueStrings(op.Value)...)
+	}
+	return templates
+}
+
+// valueStrings returns the strings of a YAML value, including those nested in maps and lists.
+func valueStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case map[string]interface{}:
+		var strings []string
+		for _, item := range v {
+			strings = append(strings, valueStrings(item)...)
+		}
+		return strings
+	case []interface{}:
+		var strings []string
+		for _, item := range v {
+			strings = append(strings, valueStrings(item)...)
+		}
+		return strings
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"dx/internal/core/domain"
+	"dx/internal/ports"
+	"dx/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderServicePatches_RendersTemplates(t *testing.T) {
+	service := &domain.Service{
+		Name: "api",
+		Patches: []domain.ServicePatch{
+			{
+				Target: domain.ServicePatchTarget{Kind: "Deployment", Name: "api"},
+				Operations: []domain.ServicePatchOperation{
+					{Op: "replace", Path: "/spec/replicas", Value: 2},
+					{Op: "add", Path: "/spec/template/spec/containers/0/env/-", Value: map[string]interface{}{
+						"name":  "API_KEY",
+						"value": "{{ .Secrets.API_KEY }}",
+					}},
+					{Op: "move", Path: "/metadata/labels/tier", From: "/metadata/labels/layer"},
+				},
+			},
+			{
+				Target: domain.ServicePatchTarget{Kind: "Service"},
+				Patch:  "metadata:\n  annotations:\n    owner: {{ .Secrets.OWNER }}\n",
+			},
+		},
+	}
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", "API_KEY", "patches.0.operations.1.value.name", mock.Anything).Return("API_KEY", nil)
+	templater.On("Render", "{{ .Secrets.API_KEY }}", "patches.0.operations.1.value.value", mock.Anything).Return("secret", nil)
+	templater.On("Render", service.Patches[1].Patch, "patches.1.patch", mock.Anything).
+		Return("metadata:\n  annotations:\n    owner: payments\n", nil)
+
+	patches, err := RenderServicePatches(service, templater, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ports.Patch{
+		{
+			Target: ports.PatchTarget{Kind: "Deployment", Name: "api"},
+			JSONPatch: []ports.PatchOperation{
+				{Op: "replace", Path: "/spec/replicas", Value: 2},
+				{Op: "add", Path: "/spec/template/spec/containers/0/env/-", Value: map[string]interface{}{"name": "API_KEY", "value": "secret"}},
+				{Op: "move", Path: "/metadata/labels/tier", From: "/metadata/labels/layer"},
+			},
+		},
+		{
+			Target:         ports.PatchTarget{Kind: "Service"},
+			StrategicMerge: "metadata:\n  annotations:\n    owner: payments\n",
+		},
+	}, patches)
+}
+
+func TestRenderServicePatches_ReturnsTemplateError(t *testing.T) {
+	service := &domain.Service{
+		Patches: []domain.ServicePatch{{Target: domain.ServicePatchTarget{Kind: "Service"}, Patch: "{{ .Secrets.MISSING }}"}},
+	}
+	templater := new(testutil.MockTemplater)
+	templater.On("Render", mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("missing secret"))
+
+	_, err := RenderServicePatches(service, templater, nil)
+
+	assert.ErrorContains(t, err, "missing secret")
+}
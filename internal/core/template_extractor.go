@@ -79,7 +79,8 @@ func ExtractTemplateVariables(template string) map[string][]string {
 }
 
 // ExtractSecretKeys returns all unique secret keys referenced in a ConfigurationContext.
-// It scans Scripts, Service.HelmArgs, and DockerImage.BuildArgs for template references.
+// It scans Scripts, Service.HelmArgs, helm values and Patches, and DockerImage.BuildArgs and build options for
+// template references, and includes the secrets DockerImage.BuildSecrets mounts into builds.
 func ExtractSecretKeys(ctx *domain.ConfigurationContext) []string {
 	if ctx == nil {
 		return nil
@@ -107,8 +108,21 @@ func ExtractSecretKeys(ctx *domain.ConfigurationContext) []string {
 	// Services
 	for _, svc := range ctx.Services {
 		collect(svc.HelmArgs...)
+		collect(helmValuesTemplates(&svc)...)
+		for _, patch := range svc.Patches {
+			collect(patchTemplates(patch)...)
+		}
 		for _, img := range svc.DockerImages {
 			collect(img.BuildArgs...)
+			for _, option := range buildOptions(img) {
+				collect(option.value)
+			}
+			for _, key := range img.BuildSecrets {
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
 		}
 	}
 
@@ -1,11 +1,14 @@
 package core
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"dx/internal/core/domain"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractTemplateVariables_SecretsOnly(t *testing.T) {
@@ -104,6 +107,96 @@ func TestExtractSecretKeys_FromHelmArgs(t *testing.T) {
 	assert.ElementsMatch(t, []string{"API_KEY", "DB_PASSWORD"}, keys)
 }
 
+func TestExtractSecretKeys_FromHelmValues(t *testing.T) {
+	chartRepo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(chartRepo, "values.yaml"), []byte("password: {{.Secrets.DB_PASSWORD}}\n"), 0644))
+	ctx := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name:            "api",
+				HelmPath:        chartRepo,
+				HelmValuesFiles: []string{"values.yaml", "not-cloned-yet.yaml"},
+				HelmValues:      map[string]interface{}{"api": map[string]interface{}{"key": "{{.Secrets.API_KEY}}"}},
+			},
+		},
+	}
+
+	keys := ExtractSecretKeys(ctx)
+
+	assert.Equal(t, []string{"API_KEY", "DB_PASSWORD"}, keys)
+}
+
+func TestExtractSecretKeys_FromPatches(t *testing.T) {
+	ctx := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name: "api",
+				Patches: []domain.ServicePatch{
+					{
+						Target: domain.ServicePatchTarget{Kind: "Deployment"},
+						Operations: []domain.ServicePatchOperation{
+							{Op: "add", Path: "/spec/template/spec/containers/0/env/-", Value: map[string]interface{}{
+								"name":  "API_KEY",
+								"value": "{{ .Secrets.API_KEY }}",
+							}},
+						},
+					},
+					{
+						Target: domain.ServicePatchTarget{Kind: "Service"},
+						Patch:  "metadata:\n  annotations:\n    owner: {{ .Secrets.OWNER }}\n",
+					},
+				},
+			},
+		},
+	}
+
+	keys := ExtractSecretKeys(ctx)
+
+	assert.Equal(t, []string{"API_KEY", "OWNER"}, keys)
+}
+
+func TestExtractSecretKeys_FromBuildOptions(t *testing.T) {
+	ctx := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name: "api",
+				DockerImages: []domain.DockerImage{
+					{
+						Name:      "api-image",
+						CacheFrom: []string{"type=registry,ref={{.Secrets.CACHE_REGISTRY}}/api"},
+						Labels:    map[string]string{"owner": "{{.Secrets.OWNER}}"},
+					},
+				},
+			},
+		},
+	}
+
+	keys := ExtractSecretKeys(ctx)
+
+	assert.ElementsMatch(t, []string{"CACHE_REGISTRY", "OWNER"}, keys)
+}
+
+func TestExtractSecretKeys_FromBuildSecrets(t *testing.T) {
+	ctx := &domain.ConfigurationContext{
+		Services: []domain.Service{
+			{
+				Name: "api",
+				DockerImages: []domain.DockerImage{
+					{
+						Name:         "api-image",
+						BuildArgs:    []string{"--build-arg=TOKEN={{.Secrets.NPM_TOKEN}}"},
+						BuildSecrets: map[string]string{"npm": "NPM_TOKEN", "github": "GITHUB_TOKEN"},
+					},
+				},
+			},
+		},
+	}
+
+	keys := ExtractSecretKeys(ctx)
+
+	assert.Equal(t, []string{"GITHUB_TOKEN", "NPM_TOKEN"}, keys)
+}
+
 func TestExtractSecretKeys_FromBuildArgs(t *testing.T) {
 	ctx := &domain.ConfigurationContext{
 		Services: []domain.Service{
@@ -55,5 +55,5 @@ backend be-{{ .Name }}
     {{- if gt .LocalPort 0 }}
     server local host.docker.internal:{{ .LocalPort }} check
     {{- end }}
-    server k8s {{ .Name }}-srv:{{ .KubernetesPort }} check backup
+    server k8s {{ .Name }}-{{ $.Name }}-srv:{{ .KubernetesPort }} check backup
 {{ end }}
\ No newline at end of file
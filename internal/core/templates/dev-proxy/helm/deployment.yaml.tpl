@@ -1,18 +1,22 @@
 apiVersion: apps/v1
 kind: Deployment
 metadata:
-  name: dev-proxy
+  name: dev-proxy-{{ .Name }}
   labels:
-    app: dev-proxy
+    app: dev-proxy-{{ .Name }}
+    managed-by: dx
+    dx-context: {{ .Name }}
 spec:
   replicas: 1
   selector:
     matchLabels:
-      app: dev-proxy
+      app: dev-proxy-{{ .Name }}
   template:
     metadata:
       labels:
-        app: dev-proxy
+        app: dev-proxy-{{ .Name }}
+        managed-by: dx
+        dx-context: {{ .Name }}
       annotations:
         checksum: {{ .Checksum }}
     spec:
@@ -22,7 +26,7 @@ spec:
       containers:
       - name: haproxy
         image: henriq/haproxy-{{ .Name }}
-        imagePullPolicy: Never
+        imagePullPolicy: {{ .ImagePullPolicy }}
         ports:
         - containerPort: 8080
         - containerPort: 8888
@@ -38,7 +42,7 @@ spec:
           mountPath: /var/lib/haproxy
       - name: mitmproxy
         image: henriq/mitmproxy-{{ .Name }}
-        imagePullPolicy: Never
+        imagePullPolicy: {{ .ImagePullPolicy }}
         tty: true
         stdin: true
         securityContext:
@@ -91,10 +95,13 @@ spec:
 apiVersion: v1
 kind: Service
 metadata:
-  name: dev-proxy
+  name: dev-proxy-{{ .Name }}
+  labels:
+    managed-by: dx
+    dx-context: {{ .Name }}
 spec:
   selector:
-    app: dev-proxy
+    app: dev-proxy-{{ .Name }}
   ports:
   - protocol: TCP
     port: 8888
@@ -110,7 +117,10 @@ spec:
 apiVersion: networking.k8s.io/v1
 kind: Ingress
 metadata:
-  name: dev-proxy-haproxy
+  name: dev-proxy-{{ .Name }}-haproxy
+  labels:
+    managed-by: dx
+    dx-context: {{ .Name }}
 spec:
   rules:
   - host: stats.dev-proxy.{{ .Name }}.localhost
@@ -120,7 +130,7 @@ spec:
         pathType: Prefix
         backend:
           service:
-            name: dev-proxy
+            name: dev-proxy-{{ .Name }}
             port:
               number: 8888
 
@@ -129,7 +139,10 @@ spec:
 apiVersion: networking.k8s.io/v1
 kind: Ingress
 metadata:
-  name: dev-proxy-mitmweb
+  name: dev-proxy-{{ .Name }}-mitmweb
+  labels:
+    managed-by: dx
+    dx-context: {{ .Name }}
 spec:
   rules:
   - host: dev-proxy.{{ .Name }}.localhost
@@ -139,7 +152,7 @@ spec:
         pathType: Prefix
         backend:
           service:
-            name: dev-proxy
+            name: dev-proxy-{{ .Name }}
             port:
               number: 8000
 
@@ -150,7 +163,10 @@ spec:
 apiVersion: v1
 kind: Service
 metadata:
-  name: {{ .Name }}-srv
+  name: {{ .Name }}-{{ $.Name }}-srv
+  labels:
+    managed-by: dx
+    dx-context: {{ $.Name }}
 spec:
   selector:
 {{ .Selector | toYaml | indent 4}}
@@ -165,7 +181,10 @@ spec:
 apiVersion: networking.k8s.io/v1
 kind: Ingress
 metadata:
-  name: {{ .Name }}-dx
+  name: {{ .Name }}-{{ $.Name }}-dx
+  labels:
+    managed-by: dx
+    dx-context: {{ $.Name }}
 spec:
   rules:
   - host: {{ .Name }}.{{ $.Name }}.localhost
@@ -9,6 +9,7 @@ type CommandRunner interface {
 	RunInDir(dir, name string, args ...string) ([]byte, error)
 	RunWithEnvInDir(dir string, env []string, name string, args ...string) ([]byte, error)
 	RunWithStdin(stdin io.Reader, name string, args ...string) ([]byte, error)
+	RunWithEnvAndStdin(stdin io.Reader, env []string, name string, args ...string) ([]byte, error)
 	// RunInteractive executes a command with stdin, stdout, and stderr connected
 	// to the terminal for interactive use. Returns error if command fails.
 	RunInteractive(name string, args ...string) error
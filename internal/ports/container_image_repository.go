@@ -7,4 +7,11 @@ import (
 type ContainerImageRepository interface {
 	BuildImage(image domain.DockerImage) error
 	PullImage(image string) error
+	// LoadImage makes a local image available to the cluster of the current context with its image
+	// loader. BuildImage and PullImage load the images they build and pull already.
+	LoadImage(image string) error
+	// ImageID returns the ID of the local image, or an empty string if it doesn't exist locally.
+	ImageID(image string) (string, error)
+	// BuilderVersion returns the version of the CLI images are built with.
+	BuilderVersion() (string, error)
 }
@@ -1,16 +1,73 @@
 package ports
 
 import (
+	"fmt"
+	"time"
+
 	"dx/internal/core/domain"
 )
 
 type ContainerOrchestrator interface {
 	CreateClusterEnvironmentKey() (string, error)
-	InstallService(service *domain.Service) error
+	// EnsureNamespace creates the namespace of the current context if it doesn't exist.
+	// Returns the namespace and whether it was created.
+	EnsureNamespace() (string, bool, error)
+	// InstallService installs or upgrades the release of a service. The upgrade is skipped when the
+	// deployed release has the same manifests, unless force is set. Returns whether it was upgraded.
+	InstallService(service *domain.Service, force bool) (bool, error)
 	InstallDevProxy(service *domain.Service) error
+	// RenderService renders a service like InstallService without contacting the cluster.
+	// Returns the manifests with the dx patches applied.
+	RenderService(service *domain.Service) ([]byte, error)
+	// RenderDevProxy renders the dev-proxy chart like InstallDevProxy without contacting the cluster.
+	RenderDevProxy(service *domain.Service) ([]byte, error)
+	// DiffService renders a service like InstallService and compares the result with the live objects
+	// in the cluster. Returns the objects that would be created, changed or deleted.
+	DiffService(service *domain.Service) ([]ObjectDiff, error)
+	// WaitForService blocks until the Deployments, StatefulSets and Jobs of the service's release are ready.
+	// onProgress is called with a short status whenever the rollout progresses.
+	// Returns a *RolloutError if a workload fails or isn't ready within the timeout.
+	WaitForService(service *domain.Service, timeout time.Duration, onProgress func(status string)) error
+	// RollbackService reinstalls the wrapper chart of a previous deployment of a service, by its revision
+	// in the deployment history or, if revision is 0, the deployment before the latest one.
+	// Returns the deployment recording the rollback.
+	RollbackService(service *domain.Service, revision int) (domain.Deployment, error)
+	// UninstallService removes the release of a service and its local state, see RemoveLocalState.
 	UninstallService(service *domain.Service) error
+	// ServicesWithLocalState returns the names of the services, including the dev-proxy, that have
+	// wrapper charts, a kustomize directory or helm values files in ~/.dx/<context>, sorted.
+	ServicesWithLocalState() ([]string, error)
+	// RemoveLocalState removes the wrapper charts, kustomize directory and helm values files of a
+	// service in ~/.dx/<context>, without touching its release.
+	RemoveLocalState(serviceName string) error
 	HasDeployedServices() (bool, error)
+	// DeployedServices returns the names of the services, including the dev-proxy, that have a release
+	// labelled managed-by=dx for the current context.
+	DeployedServices() ([]string, error)
 	// GetDevProxyChecksum returns the checksum annotation from the existing dev-proxy deployment.
 	// Returns an empty string if the deployment doesn't exist.
 	GetDevProxyChecksum() (string, error)
 }
+
+// ObjectDiff compares a rendered object with the live object in the cluster, both as YAML.
+type ObjectDiff struct {
+	Kind      string
+	Namespace string // Empty for cluster-scoped objects
+	Name      string
+	Live      string // Empty if the object doesn't exist
+	Desired   string // Empty if the object would be deleted
+}
+
+// RolloutError describes a workload that failed to become ready, with diagnostics of the failing pod.
+type RolloutError struct {
+	Service  string
+	Workload string // e.g. "deployment/api"
+	Reason   string
+	Pod      string   // Empty if no failing pod was found
+	Events   []string // Recent events of the pod, oldest first
+	Logs     string   // Last log lines of the failing container
+}
+
+func (e *RolloutError) Error() string {
+	return fmt.Sprintf("service %s is not ready: %s %s", e.Service, e.Workload, e.Reason)
+}
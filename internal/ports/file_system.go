@@ -15,6 +15,10 @@ type FileSystem interface {
 	FileExists(path string) (bool, error)
 	MkdirAll(path string, accessMode AccessMode) error
 	RemoveAll(path string) error
+	// Rename moves a file or directory. newPath must not be an existing directory.
+	Rename(oldPath, newPath string) error
+	// ReadDir returns the names of the entries of a directory, sorted. A missing directory has no entries.
+	ReadDir(path string) ([]string, error)
 	// HomeDir returns the user's home directory path.
 	// Used when paths need to be expanded for external tools like Helm.
 	HomeDir() (string, error)
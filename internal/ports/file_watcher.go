@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// FileWatcher reports changes to the files in directory trees.
+type FileWatcher interface {
+	// Watch sends a directory on the returned channel whenever a file below it is created, changed
+	// or removed, until ctx is done. Changes in .git directories are ignored.
+	Watch(ctx context.Context, directories []string) (<-chan string, error)
+}
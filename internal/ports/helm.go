@@ -5,9 +5,31 @@ type HelmClient interface {
 	// Template renders a helm chart and returns the manifests as YAML.
 	Template(name, chartPath, namespace string, args []string) ([]byte, error)
 	// UpgradeFromManifests installs/upgrades using pre-rendered manifests in a wrapper chart.
-	UpgradeFromManifests(name, namespace, wrapperChartPath string) error
+	// The release is labelled with managed-by=dx and the given labels.
+	// An empty kubeContext uses the current kubeconfig context.
+	UpgradeFromManifests(name, namespace, kubeContext, wrapperChartPath string, labels map[string]string) error
 	// Uninstall removes a helm release.
-	Uninstall(name, namespace string) error
+	Uninstall(name, namespace, kubeContext string) error
 	// List returns release names matching the label selector.
-	List(labelSelector, namespace string) ([]string, error)
+	List(labelSelector, namespace, kubeContext string) ([]string, error)
+	// ReleaseMetadata returns the metadata of the deployed revision of a release.
+	// Returns nil if the release doesn't exist.
+	ReleaseMetadata(name, namespace, kubeContext string) (*ReleaseMetadata, error)
+	// Manifest returns the manifests of the deployed revision of a release.
+	// Returns nil if the release doesn't exist.
+	Manifest(name, namespace, kubeContext string) ([]byte, error)
+	// Pull downloads a chart from a helm repository, or an oci:// reference if repoURL is empty, and
+	// unpacks it into a directory named after the chart in destination. An empty version pulls the
+	// latest version.
+	Pull(chart, repoURL, version, destination string) error
+	// DependencyBuild downloads the dependencies of a chart into its charts/ directory, as pinned by
+	// its Chart.lock.
+	DependencyBuild(chartPath string) error
+}
+
+// ReleaseMetadata describes the deployed revision of a helm release.
+type ReleaseMetadata struct {
+	Status      string
+	Revision    int
+	Annotations map[string]string // Annotations of the release's Chart.yaml
 }
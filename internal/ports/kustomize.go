@@ -2,18 +2,41 @@ package ports
 
 // KustomizeClient applies kustomize patches to Kubernetes manifests.
 type KustomizeClient interface {
-	// Apply takes raw YAML manifests and applies patches, returning patched YAML.
+	// Apply takes raw YAML manifests and applies the kustomization, returning patched YAML.
 	// workDir is the directory where kustomize files will be written for inspection.
 	// If a patch target is not found in the manifests, it logs a warning and continues.
-	Apply(manifests []byte, patches []Patch, workDir string) ([]byte, error)
+	Apply(manifests []byte, kustomization Kustomization, workDir string) ([]byte, error)
+}
+
+// Kustomization describes the changes applied to a set of manifests.
+type Kustomization struct {
+	// Labels are added to the metadata of every resource, in addition to managed-by: dx.
+	// Selectors are left untouched.
+	Labels map[string]string
+	// Patches are applied to the resources matching their target
+	Patches []Patch
+	// Images rename the images of all containers, keeping their tags
+	Images []ImageOverride
+}
+
+// ImageOverride replaces the name of an image.
+type ImageOverride struct {
+	Name    string // Image name without tag or digest
+	NewName string
 }
 
 // Patch represents a kustomize patch configuration.
 type Patch struct {
 	// Target selects which resources to patch
 	Target PatchTarget
-	// Operations are JSON patch operations to apply
+	// Operations are JSON patch operations to apply. Add operations are turned into strategic merge
+	// patches, so they create missing parent fields.
 	Operations []PatchOperation
+	// JSONPatch are JSON patch operations applied as they are
+	JSONPatch []PatchOperation
+	// StrategicMerge is a strategic merge patch as YAML. apiVersion, kind and metadata.name are
+	// filled in when missing.
+	StrategicMerge string
 }
 
 // PatchTarget identifies which Kubernetes resources to patch.
@@ -24,7 +47,8 @@ type PatchTarget struct {
 
 // PatchOperation is a single JSON patch operation.
 type PatchOperation struct {
-	Op    string      // "add", "replace", "remove"
+	Op    string      // "add", "replace", "remove", "move", "copy", "test"
 	Path  string      // JSON pointer path
-	Value interface{} // Value for add/replace
+	From  string      // JSON pointer path of the source of move/copy
+	Value interface{} // Value for add/replace/test
 }
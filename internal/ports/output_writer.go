@@ -0,0 +1,8 @@
+package ports
+
+// OutputWriter writes files to paths chosen by the user, such as the output directory of
+// 'dx install --dry-run'. Unlike FileSystem, it isn't restricted to ~/.dx.
+type OutputWriter interface {
+	// WriteFile writes content to path, creating missing parent directories.
+	WriteFile(path string, content []byte) error
+}
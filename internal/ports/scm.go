@@ -2,4 +2,14 @@ package ports
 
 type Scm interface {
 	Download(repositoryUrl string, branch string, repositoryPath string) error
+	// Revision returns the commit SHA checked out in the downloaded repository.
+	Revision(repositoryPath string) (string, error)
+	// HasUncommittedChanges reports whether any file under path differs from the commit checked
+	// out in its working tree.
+	HasUncommittedChanges(path string) (bool, error)
+	// HasIgnoredFiles reports whether path contains files that are ignored by git, and so aren't
+	// described by the checked out commit.
+	HasIgnoredFiles(path string) (bool, error)
+	// Branch returns the branch checked out in the repository, or an empty string if HEAD is detached.
+	Branch(repositoryPath string) (string, error)
 }
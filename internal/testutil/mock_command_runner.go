@@ -51,6 +51,14 @@ func (m *MockCommandRunner) RunWithStdin(stdin io.Reader, name string, args ...s
 	return callArgs.Get(0).([]byte), callArgs.Error(1)
 }
 
+func (m *MockCommandRunner) RunWithEnvAndStdin(stdin io.Reader, env []string, name string, args ...string) ([]byte, error) {
+	callArgs := m.Called(stdin, env, name, args)
+	if callArgs.Get(0) == nil {
+		return nil, callArgs.Error(1)
+	}
+	return callArgs.Get(0).([]byte), callArgs.Error(1)
+}
+
 func (m *MockCommandRunner) RunInteractive(name string, args ...string) error {
 	callArgs := m.Called(name, args)
 	return callArgs.Error(0)
@@ -23,3 +23,18 @@ func (m *MockContainerImageRepository) PullImage(image string) error {
 	args := m.Called(image)
 	return args.Error(0)
 }
+
+func (m *MockContainerImageRepository) LoadImage(image string) error {
+	args := m.Called(image)
+	return args.Error(0)
+}
+
+func (m *MockContainerImageRepository) ImageID(image string) (string, error) {
+	args := m.Called(image)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockContainerImageRepository) BuilderVersion() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
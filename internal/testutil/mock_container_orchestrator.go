@@ -1,7 +1,10 @@
 package testutil
 
 import (
+	"time"
+
 	"dx/internal/core/domain"
+	"dx/internal/ports"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -15,9 +18,38 @@ func (m *MockContainerOrchestrator) CreateClusterEnvironmentKey() (string, error
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockContainerOrchestrator) InstallService(service *domain.Service) error {
+func (m *MockContainerOrchestrator) EnsureNamespace() (string, bool, error) {
+	args := m.Called()
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockContainerOrchestrator) InstallService(service *domain.Service, force bool) (bool, error) {
+	args := m.Called(service, force)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockContainerOrchestrator) RenderService(service *domain.Service) ([]byte, error) {
 	args := m.Called(service)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockContainerOrchestrator) RenderDevProxy(service *domain.Service) ([]byte, error) {
+	args := m.Called(service)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockContainerOrchestrator) DiffService(service *domain.Service) ([]ports.ObjectDiff, error) {
+	args := m.Called(service)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ports.ObjectDiff), args.Error(1)
 }
 
 func (m *MockContainerOrchestrator) InstallDevProxy(service *domain.Service) error {
@@ -25,6 +57,20 @@ func (m *MockContainerOrchestrator) InstallDevProxy(service *domain.Service) err
 	return args.Error(0)
 }
 
+func (m *MockContainerOrchestrator) WaitForService(
+	service *domain.Service,
+	timeout time.Duration,
+	onProgress func(status string),
+) error {
+	args := m.Called(service, timeout, onProgress)
+	return args.Error(0)
+}
+
+func (m *MockContainerOrchestrator) RollbackService(service *domain.Service, revision int) (domain.Deployment, error) {
+	args := m.Called(service, revision)
+	return args.Get(0).(domain.Deployment), args.Error(1)
+}
+
 func (m *MockContainerOrchestrator) UninstallService(service *domain.Service) error {
 	args := m.Called(service)
 	return args.Error(0)
@@ -35,6 +81,27 @@ func (m *MockContainerOrchestrator) HasDeployedServices() (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockContainerOrchestrator) DeployedServices() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockContainerOrchestrator) ServicesWithLocalState() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockContainerOrchestrator) RemoveLocalState(serviceName string) error {
+	args := m.Called(serviceName)
+	return args.Error(0)
+}
+
 func (m *MockContainerOrchestrator) GetDevProxyChecksum() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
@@ -0,0 +1,17 @@
+package testutil
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFileWatcher struct {
+	mock.Mock
+}
+
+func (m *MockFileWatcher) Watch(ctx context.Context, directories []string) (<-chan string, error) {
+	args := m.Called(ctx, directories)
+	changes, _ := args.Get(0).(chan string)
+	return changes, args.Error(1)
+}
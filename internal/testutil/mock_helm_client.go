@@ -0,0 +1,63 @@
+package testutil
+
+import (
+	"dx/internal/ports"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHelmClient struct {
+	mock.Mock
+}
+
+func (m *MockHelmClient) Template(name, chartPath, namespace string, args []string) ([]byte, error) {
+	callArgs := m.Called(name, chartPath, namespace, args)
+	if callArgs.Get(0) == nil {
+		return nil, callArgs.Error(1)
+	}
+	return callArgs.Get(0).([]byte), callArgs.Error(1)
+}
+
+func (m *MockHelmClient) UpgradeFromManifests(name, namespace, kubeContext, wrapperChartPath string, labels map[string]string) error {
+	args := m.Called(name, namespace, kubeContext, wrapperChartPath, labels)
+	return args.Error(0)
+}
+
+func (m *MockHelmClient) Uninstall(name, namespace, kubeContext string) error {
+	args := m.Called(name, namespace, kubeContext)
+	return args.Error(0)
+}
+
+func (m *MockHelmClient) List(labelSelector, namespace, kubeContext string) ([]string, error) {
+	args := m.Called(labelSelector, namespace, kubeContext)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockHelmClient) ReleaseMetadata(name, namespace, kubeContext string) (*ports.ReleaseMetadata, error) {
+	args := m.Called(name, namespace, kubeContext)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ports.ReleaseMetadata), args.Error(1)
+}
+
+func (m *MockHelmClient) Manifest(name, namespace, kubeContext string) ([]byte, error) {
+	args := m.Called(name, namespace, kubeContext)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockHelmClient) Pull(chart, repoURL, version, destination string) error {
+	args := m.Called(chart, repoURL, version, destination)
+	return args.Error(0)
+}
+
+func (m *MockHelmClient) DependencyBuild(chartPath string) error {
+	args := m.Called(chartPath)
+	return args.Error(0)
+}
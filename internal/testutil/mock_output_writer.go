@@ -0,0 +1,14 @@
+package testutil
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutputWriter struct {
+	mock.Mock
+}
+
+func (m *MockOutputWriter) WriteFile(path string, content []byte) error {
+	args := m.Called(path, content)
+	return args.Error(0)
+}
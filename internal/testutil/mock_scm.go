@@ -12,3 +12,23 @@ func (m *MockScm) Download(repositoryUrl string, branch string, repositoryPath s
 	args := m.Called(repositoryUrl, branch, repositoryPath)
 	return args.Error(0)
 }
+
+func (m *MockScm) Revision(repositoryPath string) (string, error) {
+	args := m.Called(repositoryPath)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockScm) Branch(repositoryPath string) (string, error) {
+	args := m.Called(repositoryPath)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockScm) HasUncommittedChanges(path string) (bool, error) {
+	args := m.Called(path)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockScm) HasIgnoredFiles(path string) (bool, error) {
+	args := m.Called(path)
+	return args.Bool(0), args.Error(1)
+}
@@ -79,6 +79,25 @@ func (f *TestFileSystem) RemoveAll(path string) error {
 	return os.RemoveAll(f.resolvePath(path))
 }
 
+func (f *TestFileSystem) Rename(oldPath, newPath string) error {
+	return os.Rename(f.resolvePath(oldPath), f.resolvePath(newPath))
+}
+
+func (f *TestFileSystem) ReadDir(path string) ([]string, error) {
+	entries, err := os.ReadDir(f.resolvePath(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names, nil
+}
+
 func (f *TestFileSystem) HomeDir() (string, error) {
 	// Return the sandbox base directory as a mock "home"
 	return f.baseDir, nil
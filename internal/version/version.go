@@ -0,0 +1,5 @@
+package version
+
+// Version is the dx release version. It is overridden at build time with
+// -ldflags "-X dx/internal/version.Version=<version>".
+var Version = "dev"